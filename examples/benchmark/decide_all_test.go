@@ -0,0 +1,113 @@
+// to run the DecideAll benchmarks: go test -bench DecideAll -benchmem
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/optimizely/go-sdk/v2/pkg/client"
+	"github.com/optimizely/go-sdk/v2/pkg/decide"
+	"github.com/optimizely/go-sdk/v2/pkg/decision/evaluator"
+	"github.com/optimizely/go-sdk/v2/pkg/logging"
+)
+
+var countries = []string{"us", "ca", "fr", "de", "jp"}
+
+// largeDatafile generates a datafile with the given number of flags, each with an experiment and three rollout rules
+// targeting audiences that combine exact, numeric, semver and list conditions and reference each other
+func largeDatafile(flagCount, audienceCount int) []byte {
+	audiences := make([]map[string]interface{}, 0, audienceCount)
+	for i := 0; i < audienceCount; i++ {
+		conditions, _ := json.Marshal([]interface{}{"and",
+			[]interface{}{"or", map[string]interface{}{"name": "country", "type": "custom_attribute", "match": "exact", "value": countries[i%len(countries)]}},
+			[]interface{}{"or", map[string]interface{}{"name": "age", "type": "custom_attribute", "match": "gt", "value": i % 50}},
+			[]interface{}{"or",
+				map[string]interface{}{"name": "app_version", "type": "custom_attribute", "match": "semver_ge", "value": fmt.Sprintf("2.%d.0", i%10)},
+				map[string]interface{}{"name": "plan", "type": "custom_attribute", "match": "in", "value": []string{"pro", "team"}},
+			},
+		})
+		audiences = append(audiences, map[string]interface{}{"id": fmt.Sprintf("a%d", i), "name": fmt.Sprintf("audience_%d", i), "conditions": string(conditions)})
+	}
+	audienceID := func(index int) string {
+		return fmt.Sprintf("a%d", index%audienceCount)
+	}
+
+	var experiments, rollouts, featureFlags []map[string]interface{}
+	rule := func(id, key string, audienceConditions interface{}, endOfRange int) map[string]interface{} {
+		return map[string]interface{}{
+			"id": id, "key": key, "layerId": "l_" + id, "status": "Running", "audienceIds": []string{}, "audienceConditions": audienceConditions,
+			"variations": []map[string]interface{}{
+				{"id": id + "_on", "key": "on", "featureEnabled": true},
+				{"id": id + "_off", "key": "off", "featureEnabled": false},
+			},
+			"trafficAllocation": []map[string]interface{}{
+				{"entityId": id + "_on", "endOfRange": endOfRange / 2},
+				{"entityId": id + "_off", "endOfRange": endOfRange},
+			},
+		}
+	}
+	for i := 0; i < flagCount; i++ {
+		experimentID := fmt.Sprintf("e%d", i)
+		experiments = append(experiments, rule(experimentID, fmt.Sprintf("experiment_%d", i),
+			[]interface{}{"or", audienceID(i), []interface{}{"and", audienceID(i + 1), audienceID(i + 2)}}, 5000))
+
+		rolloutID := fmt.Sprintf("r%d", i)
+		rollouts = append(rollouts, map[string]interface{}{"id": rolloutID, "experiments": []map[string]interface{}{
+			rule(rolloutID+"_1", fmt.Sprintf("targeted_%d", i), []interface{}{"and", audienceID(i + 3), []interface{}{"not", audienceID(i + 4)}}, 10000),
+			rule(rolloutID+"_2", fmt.Sprintf("fallback_%d", i), []interface{}{"or", audienceID(i + 5), audienceID(i + 6)}, 10000),
+			rule(rolloutID+"_3", fmt.Sprintf("everyone_else_%d", i), []interface{}{}, 10000),
+		}})
+
+		featureFlags = append(featureFlags, map[string]interface{}{
+			"id": fmt.Sprintf("f%d", i), "key": fmt.Sprintf("flag_%d", i), "rolloutId": rolloutID,
+			"experimentIds": []string{experimentID}, "variables": []interface{}{},
+		})
+	}
+
+	datafile, _ := json.Marshal(map[string]interface{}{
+		"version": "4", "revision": "1", "accountId": "account", "projectId": "project",
+		"audiences": audiences, "experiments": experiments, "rollouts": rollouts, "featureFlags": featureFlags,
+		"attributes": []interface{}{}, "events": []interface{}{}, "groups": []interface{}{},
+	})
+	return datafile
+}
+
+func BenchmarkDecideAll(b *testing.B) {
+	logging.SetLogLevel(logging.LogLevelError)
+	attributes := map[string]interface{}{"country": "fr", "age": 31, "app_version": "2.4.1", "plan": "team"}
+	options := []decide.OptimizelyDecideOptions{decide.DisableDecisionEvent}
+
+	for _, flagCount := range []int{100, 1000} {
+		datafile := largeDatafile(flagCount, flagCount/2)
+		for _, interpreted := range []bool{true, false} {
+			name := "compiled"
+			if interpreted {
+				name = "interpreted"
+			}
+			b.Run(fmt.Sprintf("%d_flags/%s", flagCount, name), func(b *testing.B) {
+				clientOptions := []client.OptionFunc{client.WithOdpDisabled(true)}
+				if interpreted {
+					// the clients default to an evaluator.CompiledTreeEvaluator
+					audienceEvaluator := evaluator.NewMixedTreeEvaluator(logging.GetLogger("", "AudienceEvaluator"))
+					clientOptions = append(clientOptions, client.WithAudienceEvaluator(audienceEvaluator))
+				}
+				optlyClient, err := (&client.OptimizelyFactory{Datafile: datafile}).Client(clientOptions...)
+				if err != nil {
+					b.Fatal(err)
+				}
+				defer optlyClient.Close()
+
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					user := optlyClient.CreateUserContext(fmt.Sprintf("user_%d", i), attributes)
+					if decisions := user.DecideAll(options); len(decisions) != flagCount {
+						b.Fatalf("expected %d decisions, got %d", flagCount, len(decisions))
+					}
+				}
+			})
+		}
+	}
+}
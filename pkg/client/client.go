@@ -53,6 +53,8 @@ const (
 	SpanNameDecideForKeys = "decideForKeys"
 	// SpanNameDecideAll is the name of the span used by the Optimizely SDK for tracing decideAll call
 	SpanNameDecideAll = "decideAll"
+	// SpanNameDecideForUsers is the name of the span used by the Optimizely SDK for tracing DecideForUsers call
+	SpanNameDecideForUsers = "DecideForUsers"
 	// SpanNameActivate is the name of the span used by the Optimizely SDK for tracing Activate call
 	SpanNameActivate = "Activate"
 	// SpanNameFetchQualifiedSegments is the name of the span used by the Optimizely SDK for tracing fetchQualifiedSegments call
@@ -131,7 +133,8 @@ func (o *OptimizelyClient) WithTraceContext(ctx context.Context) *OptimizelyClie
 	return o
 }
 
-func (o *OptimizelyClient) decide(userContext *OptimizelyUserContext, key string, options *decide.Options) OptimizelyDecision {
+// decide returns the decision of the flag, sendNotification is false when the decision is only explained
+func (o *OptimizelyClient) decide(userContext *OptimizelyUserContext, key string, options *decide.Options, sendNotification bool) OptimizelyDecision {
 	var err error
 	defer func() {
 		if r := recover(); r != nil {
@@ -234,7 +237,7 @@ func (o *OptimizelyClient) decide(userContext *OptimizelyUserContext, key string
 	reasonsToReport := decisionReasons.ToReport()
 	ruleKey := featureDecision.Experiment.Key
 
-	if o.notificationCenter != nil {
+	if sendNotification && o.notificationCenter != nil {
 		decisionNotification := decision.FlagNotification(key, variationKey, ruleKey, experimentID, variationID, flagEnabled, eventSent, usrContext, variableMap, reasonsToReport)
 		o.logger.Debug(fmt.Sprintf(`Feature %q is enabled for user %q? %v`, key, usrContext.ID, flagEnabled))
 		if e := o.notificationCenter.Send(notification.Decision, *decisionNotification); e != nil {
@@ -275,33 +278,175 @@ func (o *OptimizelyClient) decideForKeys(userContext OptimizelyUserContext, keys
 	if len(keys) == 0 {
 		return decisionMap
 	}
+	return o.decideForUsers([]OptimizelyUserContext{userContext}, keys, options)[0]
+}
+
+// DecideForUsers returns the decisions of the flags with the given keys for every user context, in the order of the
+// user contexts. The profiles of all the users are looked up before the first decision and the changed ones saved
+// after the last, in a single round-trip when the UserProfileService is a decision.BatchUserProfileService.
+func (o *OptimizelyClient) DecideForUsers(userContexts []OptimizelyUserContext, keys []string, options []decide.OptimizelyDecideOptions) (decisionMaps []map[string]OptimizelyDecision) {
+	defer func() {
+		if r := recover(); r != nil {
+			var err error
+			switch t := r.(type) {
+			case error:
+				err = t
+			case string:
+				err = errors.New(t)
+			default:
+				err = errors.New("unexpected error")
+			}
+			errorMessage := "DecideForUsers call, optimizely SDK is panicking with the error:"
+			o.logger.Error(errorMessage, err)
+			o.logger.Debug(string(debug.Stack()))
+		}
+	}()
+
+	_, span := o.tracer.StartSpan(o.ctx, DefaultTracerName, SpanNameDecideForUsers)
+	defer span.End()
+
+	decisionMaps = make([]map[string]OptimizelyDecision, len(userContexts))
+	for i := range decisionMaps {
+		decisionMaps[i] = map[string]OptimizelyDecision{}
+	}
+	if _, err := o.getProjectConfig(); err != nil {
+		o.logger.Error("Optimizely instance is not valid, failing DecideForUsers call.", err)
+		return decisionMaps
+	}
+	if len(keys) == 0 || len(userContexts) == 0 {
+		return decisionMaps
+	}
+
+	// use copies of the user contexts so that any changes to the originals are not reflected inside the decisions
+	userContextCopies := make([]OptimizelyUserContext, len(userContexts))
+	for i, userContext := range userContexts {
+		userContextCopies[i] = newOptimizelyUserContext(o, userContext.GetUserID(), userContext.GetUserAttributes(),
+			userContext.getForcedDecisionService(), userContext.GetQualifiedSegments())
+	}
+	decideOptions := convertDecideOptions(options)
+	enabledFlagsOnly := o.getAllOptions(decideOptions).EnabledFlagsOnly
+	for i, decisionMap := range o.decideForUsers(userContextCopies, keys, decideOptions) {
+		decisionMaps[i] = filteredDecision(decisionMap, enabledFlagsOnly)
+	}
+	return decisionMaps
+}
+
+// DecideAllForUsers returns the decisions of all the flags for every user context like DecideForUsers
+func (o *OptimizelyClient) DecideAllForUsers(userContexts []OptimizelyUserContext, options []decide.OptimizelyDecideOptions) []map[string]OptimizelyDecision {
+	var allFlagKeys []string
+	if projectConfig, err := o.getProjectConfig(); err == nil {
+		for _, flag := range projectConfig.GetFeatureList() {
+			allFlagKeys = append(allFlagKeys, flag.Key)
+		}
+	}
+	return o.DecideForUsers(userContexts, allFlagKeys, options)
+}
+
+// decideForUsers decides the flags for every user. The user profiles are looked up before the first decision, the
+// decisions of a user accumulate in its profile which is saved after the last decision if it changed.
+func (o *OptimizelyClient) decideForUsers(userContexts []OptimizelyUserContext, keys []string, options *decide.Options) []map[string]OptimizelyDecision {
 	allOptions := o.getAllOptions(options)
 
-	var userProfile *decision.UserProfile
+	// storage failures of the user profile service are reported in the reasons of every decision of the user
+	userProfileErrors := map[string][]string{}
+	var lookupErrors map[string]error
 	ignoreUserProfileSvc := o.UserProfileService == nil || allOptions.IgnoreUserProfileService
 	if !ignoreUserProfileSvc {
-		up := o.UserProfileService.Lookup(userContext.GetUserID())
-		if up.ID == "" {
-			up = decision.UserProfile{
-				ID:                  userContext.GetUserID(),
-				ExperimentBucketMap: map[decision.UserDecisionKey]string{},
+		userIDs := make([]string, 0, len(userContexts))
+		for _, userContext := range userContexts {
+			userIDs = append(userIDs, userContext.GetUserID())
+		}
+		var userProfiles map[string]decision.UserProfile
+		userProfiles, lookupErrors = decision.LookupUserProfiles(o.ctx, o.UserProfileService, userIDs)
+		for userID, lookupErr := range lookupErrors {
+			o.logger.Error("Unable to look up the user profile.", lookupErr)
+			userProfileErrors[userID] = append(userProfileErrors[userID], lookupErr.Error())
+		}
+
+		// user contexts of the same user share the profile
+		sharedProfiles := make(map[string]*decision.UserProfile, len(userProfiles))
+		for i := range userContexts {
+			userID := userContexts[i].GetUserID()
+			userProfile, ok := sharedProfiles[userID]
+			if !ok {
+				up := userProfiles[userID]
+				if up.ID == "" {
+					up = decision.UserProfile{
+						ID:                  userID,
+						ExperimentBucketMap: map[decision.UserDecisionKey]string{},
+					}
+				}
+				userProfile = &up
+				sharedProfiles[userID] = userProfile
 			}
+			userContexts[i].userProfile = userProfile
 		}
-		userProfile = &up
-		userContext.userProfile = userProfile
 	}
 
-	for _, key := range keys {
-		optimizelyDecision := o.decide(&userContext, key, options)
-		decisionMap[key] = optimizelyDecision
+	decisionMaps := make([]map[string]OptimizelyDecision, len(userContexts))
+	for i := range userContexts {
+		decisionMap := map[string]OptimizelyDecision{}
+		for _, key := range keys {
+			decisionMap[key] = o.decide(&userContexts[i], key, options, true)
+		}
+		decisionMaps[i] = decisionMap
 	}
 
-	if !ignoreUserProfileSvc && userProfile != nil && userProfile.HasUnsavedChange {
-		o.UserProfileService.Save(*userProfile)
-		userProfile.HasUnsavedChange = false
+	if !ignoreUserProfileSvc {
+		var changedProfiles []decision.UserProfile
+		for _, userContext := range userContexts {
+			userProfile := userContext.userProfile
+			// a profile that could not be looked up is not saved, that would overwrite the decisions saved before
+			if _, failed := lookupErrors[userContext.GetUserID()]; failed || !userProfile.HasUnsavedChange {
+				continue
+			}
+			changedProfiles = append(changedProfiles, *userProfile)
+			userProfile.HasUnsavedChange = false
+		}
+		for userID, saveErr := range decision.SaveUserProfiles(o.ctx, o.UserProfileService, changedProfiles) {
+			o.logger.Error("Unable to save the user profile.", saveErr)
+			userProfileErrors[userID] = append(userProfileErrors[userID], saveErr.Error())
+		}
+	}
+
+	for i, userContext := range userContexts {
+		errs := userProfileErrors[userContext.GetUserID()]
+		if len(errs) == 0 {
+			continue
+		}
+		for key, optimizelyDecision := range decisionMaps[i] {
+			optimizelyDecision.Reasons = append(optimizelyDecision.Reasons, errs...)
+			decisionMaps[i][key] = optimizelyDecision
+		}
+	}
+	return decisionMaps
+}
+
+func (o *OptimizelyClient) explain(userContext OptimizelyUserContext, key string) *decide.DecisionTrace {
+	trace := &decide.DecisionTrace{FlagKey: key, UserID: userContext.GetUserID()}
+	options := &decide.Options{DisableDecisionEvent: true, Trace: trace}
+
+	if o.UserProfileService != nil && !o.getAllOptions(options).IgnoreUserProfileService {
+		// the profile is looked up so that saved variations are traced, but not saved back
+		userProfile, lookupErr := decision.LookupUserProfile(o.ctx, o.UserProfileService, userContext.GetUserID())
+		if lookupErr != nil {
+			trace.Reasons = append(trace.Reasons, lookupErr.Error())
+		}
+		if userProfile.ID == "" {
+			userProfile = decision.UserProfile{
+				ID:                  userContext.GetUserID(),
+				ExperimentBucketMap: map[decision.UserDecisionKey]string{},
+			}
+		}
+		userContext.userProfile = &userProfile
 	}
 
-	return decisionMap
+	optimizelyDecision := o.decide(&userContext, key, options, false)
+	trace.RuleKey = optimizelyDecision.RuleKey
+	trace.VariationKey = optimizelyDecision.VariationKey
+	trace.Enabled = optimizelyDecision.Enabled
+	trace.Reasons = append(trace.Reasons, optimizelyDecision.Reasons...)
+	return trace
 }
 
 func (o *OptimizelyClient) decideAll(userContext OptimizelyUserContext, options *decide.Options) map[string]OptimizelyDecision {
@@ -1203,6 +1348,7 @@ func (o *OptimizelyClient) getAllOptions(options *decide.Options) decide.Options
 		IgnoreCMABCache:          o.defaultDecideOptions.IgnoreCMABCache || options.IgnoreCMABCache,
 		ResetCMABCache:           o.defaultDecideOptions.ResetCMABCache || options.ResetCMABCache,
 		InvalidateUserCMABCache:  o.defaultDecideOptions.InvalidateUserCMABCache || options.InvalidateUserCMABCache,
+		Trace:                    options.Trace,
 	}
 }
 
@@ -27,6 +27,9 @@ import (
 	"github.com/optimizely/go-sdk/v2/pkg/config"
 	"github.com/optimizely/go-sdk/v2/pkg/decide"
 	"github.com/optimizely/go-sdk/v2/pkg/decision"
+	"github.com/optimizely/go-sdk/v2/pkg/decision/bucketer"
+	"github.com/optimizely/go-sdk/v2/pkg/decision/evaluator"
+	"github.com/optimizely/go-sdk/v2/pkg/decision/evaluator/matchers"
 	"github.com/optimizely/go-sdk/v2/pkg/event"
 	"github.com/optimizely/go-sdk/v2/pkg/logging"
 	"github.com/optimizely/go-sdk/v2/pkg/metrics"
@@ -74,12 +77,18 @@ type OptimizelyFactory struct {
 	defaultDecideOptions *decide.Options
 	eventDispatcher      event.Dispatcher
 	eventProcessor       event.Processor
+	eventCompression     *int
+	eventRetryPolicy     *event.RetryPolicy
+	eventDeadLetterSink  event.DeadLetterSink
 	metricsRegistry      metrics.Registry
 	tracer               tracing.Tracer
 	overrideStore        decision.ExperimentOverrideStore
 	userProfileService   decision.UserProfileService
 	notificationCenter   notification.Center
 	cmabConfig           *CmabConfig
+	bucketer             bucketer.Bucketer
+	matcherRegistry      *matchers.MatcherRegistry
+	audienceEvaluator    evaluator.TreeEvaluator
 
 	// ODP
 	segmentsCacheSize    int
@@ -156,6 +165,7 @@ func (f *OptimizelyFactory) Client(clientOptions ...OptionFunc) (*OptimizelyClie
 			f.SDKKey,
 			config.WithInitialDatafile(f.Datafile),
 			config.WithDatafileAccessToken(f.DatafileAccessToken),
+			config.WithMetricsRegistry(metricsRegistry),
 		)
 	}
 
@@ -168,6 +178,15 @@ func (f *OptimizelyFactory) Client(clientOptions ...OptionFunc) (*OptimizelyClie
 		if f.eventDispatcher != nil {
 			eventProcessorOptions = append(eventProcessorOptions, event.WithEventDispatcher(f.eventDispatcher))
 		}
+		if f.eventCompression != nil {
+			eventProcessorOptions = append(eventProcessorOptions, event.WithHTTPDispatcherOptions(event.WithGzipCompression(*f.eventCompression)))
+		}
+		if f.eventRetryPolicy != nil {
+			eventProcessorOptions = append(eventProcessorOptions, event.WithRetryPolicy(*f.eventRetryPolicy))
+		}
+		if f.eventDeadLetterSink != nil {
+			eventProcessorOptions = append(eventProcessorOptions, event.WithDeadLetterSink(f.eventDeadLetterSink))
+		}
 		eventProcessorOptions = append(eventProcessorOptions, event.WithEventDispatcherMetrics(metricsRegistry))
 		appClient.EventProcessor = event.NewBatchEventProcessor(eventProcessorOptions...)
 	}
@@ -190,14 +209,34 @@ func (f *OptimizelyFactory) Client(clientOptions ...OptionFunc) (*OptimizelyClie
 		if f.cmabConfig != nil {
 			experimentServiceOptions = append(experimentServiceOptions, decision.WithCmabConfig(f.cmabConfig.toCmabConfig()))
 		}
+		compositeServiceOptions := []decision.CSOptionFunc{}
+		if f.bucketer != nil {
+			experimentServiceOptions = append(experimentServiceOptions, decision.WithBucketer(f.bucketer))
+			compositeServiceOptions = append(compositeServiceOptions, decision.WithCompositeBucketer(f.bucketer))
+		}
+		if f.matcherRegistry != nil {
+			experimentServiceOptions = append(experimentServiceOptions, decision.WithMatcherRegistry(f.matcherRegistry))
+			compositeServiceOptions = append(compositeServiceOptions, decision.WithCompositeMatcherRegistry(f.matcherRegistry))
+		}
+		// experiments, rollouts and holdouts share the audience evaluator so that audiences are compiled once per config
+		audienceEvaluator := f.audienceEvaluator
+		if audienceEvaluator == nil {
+			audienceEvaluator = evaluator.NewCompiledTreeEvaluator(logging.GetLogger(f.SDKKey, "AudienceEvaluator"), f.matcherRegistry)
+		}
+		experimentServiceOptions = append(experimentServiceOptions, decision.WithAudienceEvaluator(audienceEvaluator))
+		compositeServiceOptions = append(compositeServiceOptions, decision.WithCompositeAudienceEvaluator(audienceEvaluator))
 		compositeExperimentService := decision.NewCompositeExperimentService(f.SDKKey, experimentServiceOptions...)
-		compositeService := decision.NewCompositeService(f.SDKKey, decision.WithCompositeExperimentService(compositeExperimentService))
+		compositeServiceOptions = append(compositeServiceOptions, decision.WithCompositeExperimentService(compositeExperimentService))
+		compositeService := decision.NewCompositeService(f.SDKKey, compositeServiceOptions...)
 		appClient.DecisionService = compositeService
 	}
 
 	// Initialize the default services with the execution context
-	if pollingConfigManager, ok := appClient.ConfigManager.(*config.PollingProjectConfigManager); ok {
-		eg.Go(pollingConfigManager.Start)
+	switch configManager := appClient.ConfigManager.(type) {
+	case *config.PollingProjectConfigManager:
+		eg.Go(configManager.Start)
+	case *config.StreamingProjectConfigManager:
+		eg.Go(configManager.Start)
 	}
 
 	if batchProcessor, ok := appClient.EventProcessor.(*event.BatchEventProcessor); ok {
@@ -323,6 +362,31 @@ func WithEventDispatcher(eventDispatcher event.Dispatcher) OptionFunc {
 	}
 }
 
+// WithEventCompression compresses with gzip the event batches whose payload has at least threshold bytes, e.g.
+// event.DefaultCompressionThreshold. It applies to the default event dispatcher only, not to the one given with
+// WithEventDispatcher nor to the event processor given with WithEventProcessor or WithBatchEventProcessor.
+func WithEventCompression(threshold int) OptionFunc {
+	return func(f *OptimizelyFactory) {
+		f.eventCompression = &threshold
+	}
+}
+
+// WithEventRetryPolicy sets how the default event dispatcher retries the event batches which fail to be sent, defaults
+// to event.DefaultRetryPolicy
+func WithEventRetryPolicy(policy event.RetryPolicy) OptionFunc {
+	return func(f *OptimizelyFactory) {
+		f.eventRetryPolicy = &policy
+	}
+}
+
+// WithEventDeadLetterSink sets the sink receiving the event batches the default event dispatcher failed to send
+// permanently, e.g. an event.FileDeadLetterSink. They are logged and dropped when no sink is set.
+func WithEventDeadLetterSink(sink event.DeadLetterSink) OptionFunc {
+	return func(f *OptimizelyFactory) {
+		f.eventDeadLetterSink = sink
+	}
+}
+
 // WithContext allows user to pass in their own context to override the default one in the client.
 func WithContext(ctx context.Context) OptionFunc {
 	return func(f *OptimizelyFactory) {
@@ -358,6 +422,29 @@ func WithCmabConfig(cmabConfig *CmabConfig) OptionFunc {
 	}
 }
 
+// WithBucketer sets the bucketer used to bucket users into experiments, rollouts and holdouts, defaults to MurmurHash3
+func WithBucketer(customBucketer bucketer.Bucketer) OptionFunc {
+	return func(f *OptimizelyFactory) {
+		f.bucketer = customBucketer
+	}
+}
+
+// WithMatcherRegistry sets the registry of the matchers used to evaluate audience conditions, defaults to the global
+// registry of the matchers package. Clients given different registries can use different custom matchers.
+func WithMatcherRegistry(registry *matchers.MatcherRegistry) OptionFunc {
+	return func(f *OptimizelyFactory) {
+		f.matcherRegistry = registry
+	}
+}
+
+// WithAudienceEvaluator sets the evaluator of the audiences of experiments, rollouts and holdouts, defaults to an
+// evaluator.CompiledTreeEvaluator using the matcher registry. It takes precedence over WithMatcherRegistry.
+func WithAudienceEvaluator(audienceEvaluator evaluator.TreeEvaluator) OptionFunc {
+	return func(f *OptimizelyFactory) {
+		f.audienceEvaluator = audienceEvaluator
+	}
+}
+
 // StaticClient returns a client initialized with a static project config.
 func (f *OptimizelyFactory) StaticClient() (optlyClient *OptimizelyClient, err error) {
 
@@ -426,8 +513,13 @@ func (f *OptimizelyFactory) startOdpManager(eg *utils.ExecGroup, appClient *Opti
 			})
 		}
 	}
-	// Add callback for config update
-	_, _ = appClient.ConfigManager.OnProjectConfigUpdate(callback)
+	// Add callback for config update, removed once the client is closed as the notification center outlives it
+	if id, err := appClient.ConfigManager.OnProjectConfigUpdate(callback); err == nil {
+		eg.Go(func(ctx context.Context) {
+			<-ctx.Done()
+			_ = appClient.ConfigManager.RemoveOnProjectConfigUpdate(id)
+		})
+	}
 }
 
 func convertDecideOptions(options []decide.OptimizelyDecideOptions) *decide.Options {
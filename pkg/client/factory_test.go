@@ -20,6 +20,8 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"net/http/httptest"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -31,7 +33,10 @@ import (
 	"github.com/optimizely/go-sdk/v2/pkg/config"
 	"github.com/optimizely/go-sdk/v2/pkg/decide"
 	"github.com/optimizely/go-sdk/v2/pkg/decision"
+	"github.com/optimizely/go-sdk/v2/pkg/decision/evaluator/matchers"
+	"github.com/optimizely/go-sdk/v2/pkg/entities"
 	"github.com/optimizely/go-sdk/v2/pkg/event"
+	"github.com/optimizely/go-sdk/v2/pkg/logging"
 	"github.com/optimizely/go-sdk/v2/pkg/metrics"
 	"github.com/optimizely/go-sdk/v2/pkg/notification"
 	"github.com/optimizely/go-sdk/v2/pkg/odp"
@@ -302,6 +307,91 @@ func TestStaticClient(t *testing.T) {
 	assert.Nil(t, optlyClient)
 }
 
+func TestClientsWithMatcherRegistries(t *testing.T) {
+	datafile := []byte(`{
+		"version": "4", "revision": "1",
+		"audiences": [{"id": "a1", "name": "gold", "conditions": "[\"or\", {\"name\": \"tier\", \"type\": \"custom_attribute\", \"match\": \"tier_at_least\", \"value\": \"gold\"}]"}],
+		"experiments": [],
+		"rollouts": [{"id": "r1", "experiments": [{
+			"id": "rule1", "key": "gold_rule", "layerId": "l1", "status": "Running", "audienceIds": ["a1"],
+			"variations": [{"id": "v1", "key": "on", "featureEnabled": true}],
+			"trafficAllocation": [{"entityId": "v1", "endOfRange": 10000}]
+		}]}],
+		"featureFlags": [{"id": "f1", "key": "flag", "rolloutId": "r1", "experimentIds": [], "variables": []}]
+	}`)
+	tiers := map[string]int{"silver": 1, "gold": 2, "platinum": 3}
+	atLeast := func(condition entities.Condition, user entities.UserContext, logger logging.OptimizelyLogProducer) (bool, error) {
+		tier, err := user.GetStringAttribute(condition.Name)
+		if err != nil {
+			return false, err
+		}
+		return tiers[tier] >= tiers[condition.Value.(string)], nil
+	}
+	exactly := func(condition entities.Condition, user entities.UserContext, logger logging.OptimizelyLogProducer) (bool, error) {
+		return matchers.ExactMatcher(condition, user, logger)
+	}
+
+	isEnabled := func(registry *matchers.MatcherRegistry) bool {
+		configManager := config.NewStaticProjectConfigManagerWithOptions("", config.WithInitialDatafile(datafile))
+		factory := OptimizelyFactory{}
+		optimizelyClient, err := factory.Client(WithConfigManager(configManager), WithOdpDisabled(true), WithMatcherRegistry(registry))
+		assert.NoError(t, err)
+		defer optimizelyClient.Close()
+		userContext := optimizelyClient.CreateUserContext("tester", map[string]interface{}{"tier": "platinum"})
+		return userContext.Decide("flag", []decide.OptimizelyDecideOptions{decide.DisableDecisionEvent}).Enabled
+	}
+
+	atLeastRegistry := matchers.NewMatcherRegistry()
+	atLeastRegistry.Register("tier_at_least", atLeast)
+	exactlyRegistry := matchers.NewMatcherRegistry()
+	exactlyRegistry.Register("tier_at_least", exactly)
+
+	assert.True(t, isEnabled(atLeastRegistry))
+	assert.False(t, isEnabled(exactlyRegistry))
+	// the matcher is not registered globally
+	assert.False(t, isEnabled(nil))
+	_, ok := matchers.Get("tier_at_least")
+	assert.False(t, ok)
+}
+
+// alwaysInAudience is an audience evaluator counting its evaluations
+type alwaysInAudience struct {
+	evaluations int
+}
+
+func (a *alwaysInAudience) Evaluate(*entities.TreeNode, *entities.TreeParameters, *decide.Options) (bool, bool, decide.DecisionReasons) {
+	a.evaluations++
+	return true, true, decide.NewDecisionReasons(nil)
+}
+
+func TestClientWithAudienceEvaluator(t *testing.T) {
+	datafile := []byte(`{
+		"version": "4", "revision": "1",
+		"audiences": [{"id": "a1", "name": "nobody", "conditions": "[\"or\", {\"name\": \"missing\", \"type\": \"custom_attribute\", \"match\": \"exists\"}]"}],
+		"experiments": [],
+		"rollouts": [{"id": "r1", "experiments": [{
+			"id": "rule1", "key": "targeted", "layerId": "l1", "status": "Running", "audienceIds": ["a1"],
+			"variations": [{"id": "v1", "key": "on", "featureEnabled": true}],
+			"trafficAllocation": [{"entityId": "v1", "endOfRange": 10000}]
+		}]}],
+		"featureFlags": [{"id": "f1", "key": "flag", "rolloutId": "r1", "experimentIds": [], "variables": []}]
+	}`)
+	isEnabled := func(options ...OptionFunc) bool {
+		configManager := config.NewStaticProjectConfigManagerWithOptions("", config.WithInitialDatafile(datafile))
+		factory := OptimizelyFactory{}
+		optimizelyClient, err := factory.Client(append(options, WithConfigManager(configManager), WithOdpDisabled(true))...)
+		assert.NoError(t, err)
+		defer optimizelyClient.Close()
+		userContext := optimizelyClient.CreateUserContext("tester", nil)
+		return userContext.Decide("flag", []decide.OptimizelyDecideOptions{decide.DisableDecisionEvent}).Enabled
+	}
+
+	assert.False(t, isEnabled())
+	audienceEvaluator := &alwaysInAudience{}
+	assert.True(t, isEnabled(WithAudienceEvaluator(audienceEvaluator)))
+	assert.NotZero(t, audienceEvaluator.evaluations)
+}
+
 func TestClientWithCustomDecisionServiceOptions(t *testing.T) {
 	factory := OptimizelyFactory{SDKKey: "1212"}
 
@@ -326,6 +416,45 @@ func TestClientWithEventDispatcher(t *testing.T) {
 	assert.Equal(t, dispatcher, mockEventDispatcher)
 }
 
+func TestClientWithEventCompression(t *testing.T) {
+	contentEncodings := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentEncodings <- r.Header.Get("Content-Encoding")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	factory := OptimizelyFactory{SDKKey: "1212"}
+	optimizelyClient, err := factory.Client(WithEventCompression(0))
+	assert.NoError(t, err)
+	defer optimizelyClient.Close()
+
+	dispatcher, ok := optimizelyClient.EventProcessor.(*event.BatchEventProcessor).EventDispatcher.(*event.QueueEventDispatcher)
+	assert.True(t, ok)
+	success, err := dispatcher.Dispatcher.DispatchEvent(event.LogEvent{EndPoint: server.URL, Event: event.Batch{AccountID: "1"}})
+	assert.NoError(t, err)
+	assert.True(t, success)
+	assert.Equal(t, event.ContentEncodingGzip, <-contentEncodings)
+}
+
+func TestClientWithEventRetryPolicyAndDeadLetterSink(t *testing.T) {
+	factory := OptimizelyFactory{SDKKey: "1212"}
+	policy := event.DefaultRetryPolicy()
+	policy.MaxAttempts = 10
+	sink, err := event.OpenFileDeadLetterSink(filepath.Join(t.TempDir(), "dead-letters.jsonl"))
+	assert.NoError(t, err)
+	defer sink.Close()
+
+	optimizelyClient, err := factory.Client(WithEventRetryPolicy(policy), WithEventDeadLetterSink(sink))
+	assert.NoError(t, err)
+	defer optimizelyClient.Close()
+
+	dispatcher, ok := optimizelyClient.EventProcessor.(*event.BatchEventProcessor).EventDispatcher.(*event.QueueEventDispatcher)
+	assert.True(t, ok)
+	assert.Equal(t, &policy, dispatcher.RetryPolicy)
+	assert.Equal(t, sink, dispatcher.DeadLetterSink)
+}
+
 func TestClientMetrics(t *testing.T) {
 	factory := OptimizelyFactory{SDKKey: "1212"}
 
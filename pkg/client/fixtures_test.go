@@ -18,6 +18,7 @@
 package client
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/optimizely/go-sdk/v2/pkg/config"
@@ -194,6 +195,34 @@ func (m *MockUserProfileService) Save(userProfile decision.UserProfile) {
 	m.Called(userProfile)
 }
 
+type MockContextUserProfileService struct {
+	MockUserProfileService
+}
+
+func (m *MockContextUserProfileService) LookupWithContext(ctx context.Context, userID string) (decision.UserProfile, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(decision.UserProfile), args.Error(1)
+}
+
+func (m *MockContextUserProfileService) SaveWithContext(ctx context.Context, userProfile decision.UserProfile) error {
+	args := m.Called(ctx, userProfile)
+	return args.Error(0)
+}
+
+type MockBatchUserProfileService struct {
+	MockUserProfileService
+}
+
+func (m *MockBatchUserProfileService) LookupBatch(ctx context.Context, userIDs []string) (map[string]decision.UserProfile, error) {
+	args := m.Called(ctx, userIDs)
+	return args.Get(0).(map[string]decision.UserProfile), args.Error(1)
+}
+
+func (m *MockBatchUserProfileService) SaveBatch(ctx context.Context, userProfiles []decision.UserProfile) error {
+	args := m.Called(ctx, userProfiles)
+	return args.Error(0)
+}
+
 // Helper methods for creating test entities
 func makeTestExperiment(experimentKey string) entities.Experiment {
 	return entities.Experiment{
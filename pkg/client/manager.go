@@ -0,0 +1,404 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package client has client facing factories
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/optimizely/go-sdk/v2/pkg/event"
+	"github.com/optimizely/go-sdk/v2/pkg/logging"
+	"github.com/optimizely/go-sdk/v2/pkg/metrics"
+	"github.com/optimizely/go-sdk/v2/pkg/odp"
+	odpEvent "github.com/optimizely/go-sdk/v2/pkg/odp/event"
+	"github.com/optimizely/go-sdk/v2/pkg/odp/segment"
+	pkgUtils "github.com/optimizely/go-sdk/v2/pkg/odp/utils"
+	"github.com/optimizely/go-sdk/v2/pkg/utils"
+)
+
+// DefaultMaxIdleTime is how long a managed client may go unused before it is closed
+const DefaultMaxIdleTime = 1 * time.Hour
+
+// ErrManagerClosed is returned when a client is requested from a closed Manager
+var ErrManagerClosed = errors.New("client manager is closed")
+
+// Manager lazily creates and caches one OptimizelyClient per SDK key. Clients share the event dispatcher and the
+// ODP http requesters, and each one uses the notification center registered for its SDK key in the registry,
+// so handlers added there survive a client being evicted and created again.
+type Manager struct {
+	clientOptions       []OptionFunc
+	sdkKeyOptions       func(sdkKey string) []OptionFunc
+	maxIdleTime         time.Duration
+	maxClients          int
+	metricsRegistry     metrics.Registry
+	eventDispatcher     event.Dispatcher
+	eventCompression    *int
+	eventRetryPolicy    *event.RetryPolicy
+	eventDeadLetterSink event.DeadLetterSink
+	eventQueue          event.Queue
+	odpSegmentRequester utils.Requester
+	odpEventRequester   utils.Requester
+	ctx                 context.Context
+	execGroup           *utils.ExecGroup
+	logger              logging.OptimizelyLogProducer
+
+	lock    sync.Mutex
+	closed  bool
+	clients map[string]*managedClient
+}
+
+type managedClient struct {
+	ready    chan struct{} // closed once client and err are set
+	client   *OptimizelyClient
+	err      error
+	lastUsed time.Time
+}
+
+// ManagerOptionFunc is used to provide custom configuration to the Manager.
+type ManagerOptionFunc func(*Manager)
+
+// WithClientOptions sets options applied to every client the Manager creates
+func WithClientOptions(clientOptions ...OptionFunc) ManagerOptionFunc {
+	return func(m *Manager) {
+		m.clientOptions = append(m.clientOptions, clientOptions...)
+	}
+}
+
+// WithSDKKeyClientOptions sets a function returning options for the client of a given SDK key, applied after WithClientOptions
+func WithSDKKeyClientOptions(sdkKeyOptions func(sdkKey string) []OptionFunc) ManagerOptionFunc {
+	return func(m *Manager) {
+		m.sdkKeyOptions = sdkKeyOptions
+	}
+}
+
+// WithMaxIdleTime sets how long a client may go unused before it is closed, zero disables idle eviction
+func WithMaxIdleTime(maxIdleTime time.Duration) ManagerOptionFunc {
+	return func(m *Manager) {
+		m.maxIdleTime = maxIdleTime
+	}
+}
+
+// WithMaxClients bounds the number of cached clients, the least recently used one is closed when it is exceeded
+func WithMaxClients(maxClients int) ManagerOptionFunc {
+	return func(m *Manager) {
+		m.maxClients = maxClients
+	}
+}
+
+// WithManagerMetricsRegistry sets the metrics registry shared by the Manager and every client it creates
+func WithManagerMetricsRegistry(metricsRegistry metrics.Registry) ManagerOptionFunc {
+	return func(m *Manager) {
+		m.metricsRegistry = metricsRegistry
+	}
+}
+
+// WithSharedEventDispatcher sets the event dispatcher shared by every client the Manager creates
+func WithSharedEventDispatcher(eventDispatcher event.Dispatcher) ManagerOptionFunc {
+	return func(m *Manager) {
+		m.eventDispatcher = eventDispatcher
+	}
+}
+
+// WithSharedEventCompression compresses with gzip the event batches whose payload has at least threshold bytes, e.g.
+// event.DefaultCompressionThreshold. It applies to the default shared event dispatcher only, not to the one given with
+// WithSharedEventDispatcher.
+func WithSharedEventCompression(threshold int) ManagerOptionFunc {
+	return func(m *Manager) {
+		m.eventCompression = &threshold
+	}
+}
+
+// WithSharedEventRetryPolicy sets how the default shared event dispatcher retries the event batches which fail to be
+// sent, defaults to event.DefaultRetryPolicy
+func WithSharedEventRetryPolicy(policy event.RetryPolicy) ManagerOptionFunc {
+	return func(m *Manager) {
+		m.eventRetryPolicy = &policy
+	}
+}
+
+// WithSharedEventDeadLetterSink sets the sink receiving the event batches the default shared event dispatcher failed
+// to send permanently, e.g. an event.FileDeadLetterSink
+func WithSharedEventDeadLetterSink(sink event.DeadLetterSink) ManagerOptionFunc {
+	return func(m *Manager) {
+		m.eventDeadLetterSink = sink
+	}
+}
+
+// WithSharedEventQueue sets the queue holding the event batches of the default shared event dispatcher until they
+// are sent, e.g. a durable one
+func WithSharedEventQueue(queue event.Queue) ManagerOptionFunc {
+	return func(m *Manager) {
+		m.eventQueue = queue
+	}
+}
+
+// NewManager returns a Manager with the given configuration. The ctx bounds the lifetime of every managed client.
+// Managed clients send their events through the shared event dispatcher, so the client options configuring the
+// default event dispatcher, WithEventCompression, WithEventRetryPolicy and WithEventDeadLetterSink, are ignored and
+// logged, use WithSharedEventCompression, WithSharedEventRetryPolicy, WithSharedEventDeadLetterSink and
+// WithSharedEventQueue instead.
+func NewManager(ctx context.Context, managerOptions ...ManagerOptionFunc) *Manager {
+	logger := logging.GetLogger("", "ClientManager")
+	manager := &Manager{
+		maxIdleTime: DefaultMaxIdleTime,
+		ctx:         ctx,
+		execGroup:   utils.NewExecGroup(ctx, logger),
+		logger:      logger,
+		clients:     map[string]*managedClient{},
+	}
+	for _, opt := range managerOptions {
+		opt(manager)
+	}
+
+	if manager.metricsRegistry == nil {
+		manager.metricsRegistry = metrics.NewNoopRegistry()
+	}
+	if manager.eventDispatcher == nil {
+		manager.eventDispatcher = manager.newSharedEventDispatcher()
+	}
+	manager.odpSegmentRequester = utils.NewHTTPRequester(logging.GetLogger("", "SegmentAPIManager"), utils.Timeout(pkgUtils.DefaultSegmentFetchTimeout))
+	manager.odpEventRequester = utils.NewHTTPRequester(logging.GetLogger("", "EventAPIManager"), utils.Timeout(pkgUtils.DefaultOdpEventTimeout))
+
+	if manager.maxIdleTime > 0 {
+		manager.execGroup.Go(manager.evictIdleClients)
+	}
+	return manager
+}
+
+// Get returns the client of the given SDK key, creating it on first use
+func (m *Manager) Get(sdkKey string) (*OptimizelyClient, error) {
+	m.lock.Lock()
+	if m.closed {
+		m.lock.Unlock()
+		return nil, ErrManagerClosed
+	}
+	managed, ok := m.clients[sdkKey]
+	if !ok {
+		managed = &managedClient{ready: make(chan struct{})}
+		m.clients[sdkKey] = managed
+	}
+	managed.lastUsed = time.Now()
+	var evicted []*OptimizelyClient
+	if !ok {
+		evicted = m.evictOverLimit(sdkKey)
+	}
+	m.lock.Unlock()
+	closeClients(evicted)
+
+	if ok {
+		<-managed.ready
+		return managed.client, managed.err
+	}
+
+	// the lock is not held while creating, a synchronous datafile fetch must not block the other SDK keys
+	func() {
+		defer close(managed.ready)
+		managed.client, managed.err = m.newClient(sdkKey)
+	}()
+	if managed.err != nil {
+		m.lock.Lock()
+		if m.clients[sdkKey] == managed {
+			delete(m.clients, sdkKey)
+		}
+		m.lock.Unlock()
+	} else {
+		m.logger.Debug(fmt.Sprintf("Created client for SDK key %s", sdkKey))
+	}
+	return managed.client, managed.err
+}
+
+// Remove closes and forgets the client of the given SDK key, if any
+func (m *Manager) Remove(sdkKey string) {
+	m.lock.Lock()
+	managed, ok := m.clients[sdkKey]
+	if ok {
+		delete(m.clients, sdkKey)
+	}
+	m.lock.Unlock()
+	if ok {
+		closeClients([]*OptimizelyClient{readyClient(managed, true)})
+	}
+}
+
+// SDKKeys returns the SDK keys of the cached clients
+func (m *Manager) SDKKeys() []string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	sdkKeys := make([]string, 0, len(m.clients))
+	for sdkKey := range m.clients {
+		sdkKeys = append(sdkKeys, sdkKey)
+	}
+	return sdkKeys
+}
+
+// Close closes every cached client and stops the Manager, Get fails afterwards
+func (m *Manager) Close() {
+	m.lock.Lock()
+	if m.closed {
+		m.lock.Unlock()
+		return
+	}
+	m.closed = true
+	managedClients := m.clients
+	m.clients = map[string]*managedClient{}
+	m.lock.Unlock()
+
+	m.execGroup.TerminateAndWait()
+	clients := make([]*OptimizelyClient, 0, len(managedClients))
+	for _, managed := range managedClients {
+		clients = append(clients, readyClient(managed, true))
+	}
+	closeClients(clients)
+}
+
+func (m *Manager) newClient(sdkKey string) (*OptimizelyClient, error) {
+	// shared resources come first so that client options can override them
+	clientOptions := []OptionFunc{WithEventDispatcher(m.eventDispatcher), WithMetricsRegistry(m.metricsRegistry)}
+	clientOptions = append(clientOptions, m.clientOptions...)
+	if m.sdkKeyOptions != nil {
+		clientOptions = append(clientOptions, m.sdkKeyOptions(sdkKey)...)
+	}
+	// the manager ctx comes last so that every client stops with the manager
+	clientOptions = append(clientOptions, m.withSharedOdpRequesters(sdkKey), m.warnIgnoredEventOptions(sdkKey), WithContext(m.ctx))
+
+	factory := OptimizelyFactory{SDKKey: sdkKey}
+	return factory.Client(clientOptions...)
+}
+
+// newSharedEventDispatcher returns the event dispatcher shared by the clients when none was provided
+func (m *Manager) newSharedEventDispatcher() event.Dispatcher {
+	dispatcher := event.NewQueueEventDispatcherWithQueue("", m.metricsRegistry, m.eventQueue)
+	dispatcher.RetryPolicy = m.eventRetryPolicy
+	dispatcher.DeadLetterSink = m.eventDeadLetterSink
+	if m.eventCompression != nil {
+		dispatcher.Dispatcher = event.NewHTTPEventDispatcher("", nil, nil, event.WithGzipCompression(*m.eventCompression))
+	}
+	return dispatcher
+}
+
+// warnIgnoredEventOptions logs the client options configuring the default event dispatcher, which managed clients do not use
+func (m *Manager) warnIgnoredEventOptions(sdkKey string) OptionFunc {
+	return func(f *OptimizelyFactory) {
+		if f.eventProcessor != nil {
+			return
+		}
+		if f.eventCompression != nil || f.eventRetryPolicy != nil || f.eventDeadLetterSink != nil {
+			m.logger.Warning(fmt.Sprintf("Event compression, retry policy and dead letter sink of the client for SDK key %s are ignored, "+
+				"managed clients use the shared event dispatcher", sdkKey))
+		}
+	}
+}
+
+// withSharedOdpRequesters builds the ODP manager on the shared requesters, unless ODP is disabled or a manager was provided
+func (m *Manager) withSharedOdpRequesters(sdkKey string) OptionFunc {
+	return func(f *OptimizelyFactory) {
+		if f.odpManager != nil || f.odpDisabled {
+			return
+		}
+		segmentManager := segment.NewSegmentManager(sdkKey,
+			segment.WithAPIManager(segment.NewSegmentAPIManager(sdkKey, m.odpSegmentRequester)),
+			segment.WithSegmentsCacheSize(f.segmentsCacheSize),
+			segment.WithSegmentsCacheTimeout(f.segmentsCacheTimeout))
+		eventManager := odpEvent.NewBatchEventManager(odpEvent.WithSDKKey(sdkKey),
+			odpEvent.WithAPIManager(odpEvent.NewEventAPIManager(sdkKey, m.odpEventRequester)))
+		f.odpManager = odp.NewOdpManager(sdkKey, false, odp.WithSegmentManager(segmentManager), odp.WithEventManager(eventManager))
+	}
+}
+
+func (m *Manager) evictIdleClients(ctx context.Context) {
+	interval := m.maxIdleTime / 2
+	if interval < time.Second {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			closeClients(m.evictIdle(now))
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// evictIdle forgets the clients unused since maxIdleTime before now and returns them to be closed
+func (m *Manager) evictIdle(now time.Time) (evicted []*OptimizelyClient) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for sdkKey, managed := range m.clients {
+		if now.Sub(managed.lastUsed) < m.maxIdleTime {
+			continue
+		}
+		if client := readyClient(managed, false); client != nil {
+			delete(m.clients, sdkKey)
+			evicted = append(evicted, client)
+			m.logger.Debug(fmt.Sprintf("Evicted idle client for SDK key %s", sdkKey))
+		}
+	}
+	return evicted
+}
+
+// evictOverLimit forgets the least recently used clients beyond maxClients, must be called with the lock held
+func (m *Manager) evictOverLimit(keep string) (evicted []*OptimizelyClient) {
+	for m.maxClients > 0 && len(m.clients) > m.maxClients {
+		var oldestKey string
+		var oldest *managedClient
+		for sdkKey, managed := range m.clients {
+			if sdkKey == keep || readyClient(managed, false) == nil {
+				continue
+			}
+			if oldest == nil || managed.lastUsed.Before(oldest.lastUsed) {
+				oldestKey, oldest = sdkKey, managed
+			}
+		}
+		if oldest == nil {
+			// every other client is still being created
+			return evicted
+		}
+		delete(m.clients, oldestKey)
+		evicted = append(evicted, oldest.client)
+		m.logger.Debug(fmt.Sprintf("Evicted least recently used client for SDK key %s", oldestKey))
+	}
+	return evicted
+}
+
+// readyClient returns the created client, waiting for its creation when wait is set, nil if it is not available
+func readyClient(managed *managedClient, wait bool) *OptimizelyClient {
+	if wait {
+		<-managed.ready
+		return managed.client
+	}
+	select {
+	case <-managed.ready:
+		return managed.client
+	default:
+		return nil
+	}
+}
+
+func closeClients(clients []*OptimizelyClient) {
+	for _, client := range clients {
+		if client != nil {
+			client.Close()
+		}
+	}
+}
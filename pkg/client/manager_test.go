@@ -0,0 +1,213 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/optimizely/go-sdk/v2/pkg/config"
+	"github.com/optimizely/go-sdk/v2/pkg/event"
+	"github.com/optimizely/go-sdk/v2/pkg/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestManager(managerOptions ...ManagerOptionFunc) *Manager {
+	return newTestManagerWithContext(context.Background(), managerOptions...)
+}
+
+func newTestManagerWithContext(ctx context.Context, managerOptions ...ManagerOptionFunc) *Manager {
+	managerOptions = append([]ManagerOptionFunc{
+		WithSDKKeyClientOptions(func(sdkKey string) []OptionFunc {
+			datafile := []byte(fmt.Sprintf(`{"version": "4", "revision": "%s"}`, sdkKey))
+			configManager, _ := config.NewStaticProjectConfigManagerFromPayload(datafile, logging.GetLogger(sdkKey, "StaticProjectConfigManager"))
+			return []OptionFunc{WithConfigManager(configManager)}
+		}),
+		WithClientOptions(WithOdpDisabled(true)),
+	}, managerOptions...)
+	return NewManager(ctx, managerOptions...)
+}
+
+func TestManagerGetCreatesAndCachesClients(t *testing.T) {
+	manager := newTestManager()
+	defer manager.Close()
+
+	client1, err := manager.Get("sdk_key_1")
+	require.NoError(t, err)
+	client2, err := manager.Get("sdk_key_2")
+	require.NoError(t, err)
+	assert.NotSame(t, client1, client2)
+
+	cached, err := manager.Get("sdk_key_1")
+	require.NoError(t, err)
+	assert.Same(t, client1, cached)
+
+	projectConfig, err := client2.ConfigManager.GetConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "sdk_key_2", projectConfig.GetRevision())
+
+	sdkKeys := manager.SDKKeys()
+	sort.Strings(sdkKeys)
+	assert.Equal(t, []string{"sdk_key_1", "sdk_key_2"}, sdkKeys)
+}
+
+func TestManagerSharesEventDispatcher(t *testing.T) {
+	dispatcher := &MockDispatcher{}
+	manager := newTestManager(WithSharedEventDispatcher(dispatcher))
+	defer manager.Close()
+
+	client1, _ := manager.Get("sdk_key_1")
+	client2, _ := manager.Get("sdk_key_2")
+	assert.Same(t, dispatcher, client1.EventProcessor.(*event.BatchEventProcessor).EventDispatcher)
+	assert.Same(t, dispatcher, client2.EventProcessor.(*event.BatchEventProcessor).EventDispatcher)
+}
+
+func TestManagerSharedEventDispatcherOptions(t *testing.T) {
+	policy := event.RetryPolicy{MaxAttempts: 1}
+	sink, err := event.OpenFileDeadLetterSink(filepath.Join(t.TempDir(), "dead_letters.jsonl"))
+	require.NoError(t, err)
+	defer sink.Close()
+	manager := newTestManager(WithSharedEventRetryPolicy(policy), WithSharedEventDeadLetterSink(sink),
+		WithSharedEventCompression(event.DefaultCompressionThreshold), WithSharedEventQueue(event.NewInMemoryQueue(10)))
+	defer manager.Close()
+
+	client1, _ := manager.Get("sdk_key_1")
+	client2, _ := manager.Get("sdk_key_2")
+	dispatcher, ok := client1.EventProcessor.(*event.BatchEventProcessor).EventDispatcher.(*event.QueueEventDispatcher)
+	require.True(t, ok)
+	assert.Same(t, dispatcher, client2.EventProcessor.(*event.BatchEventProcessor).EventDispatcher)
+	assert.Equal(t, &policy, dispatcher.RetryPolicy)
+	assert.Equal(t, sink, dispatcher.DeadLetterSink)
+}
+
+func TestManagerWarnsAboutIgnoredClientEventOptions(t *testing.T) {
+	logger := &warningLogger{}
+	manager := newTestManager(WithClientOptions(WithEventRetryPolicy(event.RetryPolicy{MaxAttempts: 1})))
+	manager.logger = logger
+	defer manager.Close()
+
+	_, err := manager.Get("sdk_key_1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Event compression, retry policy and dead letter sink of the client for SDK key sdk_key_1 are ignored, " +
+		"managed clients use the shared event dispatcher"}, logger.warnings)
+}
+
+// warningLogger records the warnings logged
+type warningLogger struct {
+	warnings []string
+}
+
+func (l *warningLogger) Debug(message string) {}
+
+func (l *warningLogger) Info(message string) {}
+
+func (l *warningLogger) Warning(message string) {
+	l.warnings = append(l.warnings, message)
+}
+
+func (l *warningLogger) Error(message string, err interface{}) {}
+
+func TestManagerEvictsLeastRecentlyUsed(t *testing.T) {
+	manager := newTestManager(WithMaxClients(2))
+	defer manager.Close()
+
+	_, _ = manager.Get("sdk_key_1")
+	_, _ = manager.Get("sdk_key_2")
+	_, _ = manager.Get("sdk_key_1")
+	_, _ = manager.Get("sdk_key_3")
+
+	sdkKeys := manager.SDKKeys()
+	sort.Strings(sdkKeys)
+	assert.Equal(t, []string{"sdk_key_1", "sdk_key_3"}, sdkKeys)
+}
+
+func TestManagerEvictsIdleClients(t *testing.T) {
+	manager := newTestManager(WithMaxIdleTime(time.Minute))
+	defer manager.Close()
+
+	_, _ = manager.Get("sdk_key_1")
+	assert.Empty(t, manager.evictIdle(time.Now()))
+	evicted := manager.evictIdle(time.Now().Add(time.Minute))
+	assert.Len(t, evicted, 1)
+	assert.Empty(t, manager.SDKKeys())
+}
+
+func TestManagerRemoveAndClose(t *testing.T) {
+	manager := newTestManager()
+
+	client1, _ := manager.Get("sdk_key_1")
+	manager.Remove("sdk_key_1")
+	assert.Empty(t, manager.SDKKeys())
+	recreated, _ := manager.Get("sdk_key_1")
+	assert.NotSame(t, client1, recreated)
+
+	manager.Close()
+	_, err := manager.Get("sdk_key_1")
+	assert.True(t, errors.Is(err, ErrManagerClosed))
+}
+
+func TestManagerDoesNotCacheFailedClients(t *testing.T) {
+	manager := NewManager(context.Background(), WithSDKKeyClientOptions(func(sdkKey string) []OptionFunc {
+		return nil
+	}))
+	defer manager.Close()
+
+	_, err := manager.Get("")
+	assert.Error(t, err)
+	assert.Empty(t, manager.SDKKeys())
+}
+
+type flushSignalDispatcher struct {
+	dispatched chan event.LogEvent
+}
+
+func (d *flushSignalDispatcher) DispatchEvent(logEvent event.LogEvent) (bool, error) {
+	d.dispatched <- logEvent
+	return true, nil
+}
+
+func TestManagerContextStopsClients(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	dispatcher := &flushSignalDispatcher{dispatched: make(chan event.LogEvent, 1)}
+	manager := newTestManagerWithContext(ctx, WithSharedEventDispatcher(dispatcher))
+	defer manager.Close()
+
+	client, err := manager.Get("sdk_key_1")
+	require.NoError(t, err)
+	assert.True(t, client.EventProcessor.ProcessEvent(event.UserEvent{VisitorID: "user_1", Conversion: &event.ConversionEvent{Key: "event_key"}}))
+
+	// the queued event is only flushed once the event processor of the client stops
+	select {
+	case <-dispatcher.dispatched:
+		t.Fatal("event dispatched before the manager ctx was cancelled")
+	case <-time.After(50 * time.Millisecond):
+	}
+	cancel()
+	select {
+	case logEvent := <-dispatcher.dispatched:
+		assert.Equal(t, "user_1", logEvent.Event.Visitors[0].VisitorID)
+	case <-time.After(time.Second):
+		t.Fatal("client was not stopped by the manager ctx")
+	}
+}
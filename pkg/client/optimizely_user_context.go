@@ -138,6 +138,14 @@ func (o *OptimizelyUserContext) Decide(key string, options []decide.OptimizelyDe
 	return decision
 }
 
+// Explain decides the flag like Decide and returns a structured trace of every step of the decision.
+// No decision event is dispatched, no Decision notification is sent and the user profile is not saved.
+func (o *OptimizelyUserContext) Explain(flagKey string) *decide.DecisionTrace {
+	// use a copy of the user context so that any changes to the original context are not reflected inside the decision
+	userContextCopy := newOptimizelyUserContext(o.GetOptimizely(), o.GetUserID(), o.GetUserAttributes(), o.getForcedDecisionService(), o.GetQualifiedSegments())
+	return o.optimizely.explain(userContextCopy, flagKey)
+}
+
 // DecideAll returns a key-map of decision results for all active flag keys with options.
 func (o *OptimizelyUserContext) DecideAll(options []decide.OptimizelyDecideOptions) map[string]OptimizelyDecision {
 	// use a copy of the user context so that any changes to the original context are not reflected inside the decision
@@ -17,6 +17,7 @@
 package client
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"sync"
@@ -1080,6 +1081,117 @@ func (s *OptimizelyUserContextTestSuite) TestGetAllOptionsUsesOrOperator() {
 	}, options2)
 }
 
+func (s *OptimizelyUserContextTestSuite) TestExplainRollout() {
+	user := s.OptimizelyClient.CreateUserContext(s.userID, nil)
+	trace := user.Explain("feature_1")
+
+	s.Equal("feature_1", trace.FlagKey)
+	s.Equal(s.userID, trace.UserID)
+	s.Equal("18322080788", trace.RuleKey)
+	s.Equal("18257766532", trace.VariationKey)
+	s.True(trace.Enabled)
+
+	type stepSummary struct {
+		kind    decide.TraceStepKind
+		ruleKey string
+		matched bool
+	}
+	var steps []stepSummary
+	for _, step := range trace.Steps {
+		steps = append(steps, stepSummary{step.Kind, step.RuleKey, step.Matched})
+	}
+	s.Equal([]stepSummary{
+		{decide.TraceAudience, "exp_with_audience", false},
+		{decide.TraceRule, "exp_with_audience", false},
+		{decide.TraceAudience, "3332020515", false},
+		{decide.TraceRule, "3332020515", false},
+		{decide.TraceAudience, "3332020494", false},
+		{decide.TraceRule, "3332020494", false},
+		{decide.TraceAudience, "18322080788", true},
+		{decide.TraceAudience, "18322080788", true},
+		{decide.TraceBucketing, "18322080788", true},
+		{decide.TraceRule, "18322080788", true},
+	}, steps)
+
+	s.Equal("Does not meet audience targeting conditions", trace.Steps[1].Reason)
+	leaf := &decide.NodeResult{Name: "gender", Type: "custom_attribute", Matcher: "exact", Value: "f", NullReason: decide.NullMissingAttribute}
+	s.Equal(audienceResult(leaf, nil, decide.NullChild), trace.Steps[0].Audiences)
+	s.Equal(&decide.BucketingResult{BucketingID: s.userID, BucketValue: 8363, RangeEnd: 10000, EntityID: "18257766532"}, trace.Steps[8].Bucketing)
+	s.Equal("18257766532", trace.Steps[9].VariationKey)
+
+	// explaining never dispatches a decision event
+	s.Len(s.eventProcessor.Events, 0)
+}
+
+func (s *OptimizelyUserContextTestSuite) TestExplainSendsNoDecisionNotification() {
+	notified := false
+	_, err := s.OptimizelyClient.DecisionService.OnDecision(func(notification.DecisionNotification) {
+		notified = true
+	})
+	s.NoError(err)
+
+	user := s.OptimizelyClient.CreateUserContext(s.userID, nil)
+	trace := user.Explain("feature_1")
+
+	s.Equal("18257766532", trace.VariationKey)
+	s.False(notified)
+}
+
+func (s *OptimizelyUserContextTestSuite) TestExplainAudienceResults() {
+	user := s.OptimizelyClient.CreateUserContext(s.userID, map[string]interface{}{"gender": "f"})
+	trace := user.Explain("feature_1")
+
+	matched := true
+	leaf := &decide.NodeResult{Name: "gender", Type: "custom_attribute", Matcher: "exact", Value: "f", AttributeValue: "f", Result: &matched}
+	s.Equal(audienceResult(leaf, &matched, ""), trace.Steps[0].Audiences)
+	s.True(trace.Steps[0].Matched)
+	s.Equal("exp_with_audience", trace.RuleKey)
+}
+
+// audienceResult returns the result of the audience condition tree of "exp_with_audience" around the given leaf
+func audienceResult(leaf *decide.NodeResult, result *bool, nullReason decide.NullReason) *decide.NodeResult {
+	node := leaf
+	for _, operator := range []string{"or", "or", "and", ""} {
+		node = &decide.NodeResult{Operator: operator, Result: result, NullReason: nullReason, Nodes: []*decide.NodeResult{node}}
+	}
+	node.AudienceID = "13389141123"
+	return &decide.NodeResult{Operator: "or", Result: result, NullReason: nullReason, Nodes: []*decide.NodeResult{node}}
+}
+
+func (s *OptimizelyUserContextTestSuite) TestExplainUserProfile() {
+	flagKey := "feature_2" // embedding experiment: "exp_no_audience"
+	userProfileService := new(MockUserProfileService)
+	s.OptimizelyClient, _ = s.factory.Client(
+		WithEventProcessor(s.eventProcessor),
+		WithUserProfileService(userProfileService),
+	)
+	userProfileService.On("Lookup", s.userID).Return(decision.UserProfile{})
+	userProfileService.On("Save", mock.Anything)
+
+	user := s.OptimizelyClient.CreateUserContext(s.userID, nil)
+	trace := user.Explain(flagKey)
+
+	s.Equal("variation_with_traffic", trace.VariationKey)
+	s.Equal(decide.TraceUserProfile, trace.Steps[0].Kind)
+	s.Equal("exp_no_audience", trace.Steps[0].RuleKey)
+	s.False(trace.Steps[0].Matched)
+	userProfileService.AssertCalled(s.T(), "Lookup", s.userID)
+	userProfileService.AssertNotCalled(s.T(), "Save", mock.Anything)
+}
+
+func (s *OptimizelyUserContextTestSuite) TestExplainForcedDecision() {
+	flagKey := "feature_1"
+	user := s.OptimizelyClient.CreateUserContext(s.userID, nil)
+	user.SetForcedDecision(decision.OptimizelyDecisionContext{FlagKey: flagKey}, decision.OptimizelyForcedDecision{VariationKey: "3324490562"})
+	trace := user.Explain(flagKey)
+
+	s.Equal("3324490562", trace.VariationKey)
+	s.Len(trace.Steps, 1)
+	s.Equal(decide.TraceForcedDecision, trace.Steps[0].Kind)
+	s.True(trace.Steps[0].Matched)
+	s.Equal("3324490562", trace.Steps[0].VariationKey)
+}
+
 func (s *OptimizelyUserContextTestSuite) TestDecideSDKNotReady() {
 	flagKey := "feature_1"
 	factory := OptimizelyFactory{SDKKey: "121"}
@@ -1248,6 +1360,147 @@ func (s *OptimizelyUserContextTestSuite) TestDecideAllFlagsWithBatchUPS() {
 	userProfileService.AssertNumberOfCalls(s.T(), "Save", 1)
 }
 
+func (s *OptimizelyUserContextTestSuite) TestDecideAllWithUserProfileLookupFailure() {
+	userProfileService := new(MockContextUserProfileService)
+	var err error
+	s.OptimizelyClient, err = s.factory.Client(
+		WithEventProcessor(s.eventProcessor),
+		WithUserProfileService(userProfileService),
+	)
+	s.Nil(err)
+
+	userProfileService.On("LookupWithContext", mock.Anything, s.userID).Return(decision.UserProfile{}, errors.New("connection refused"))
+
+	user := s.OptimizelyClient.CreateUserContext(s.userID, nil)
+	decisions := user.DecideAll(nil)
+	s.Len(decisions, 3)
+	for _, flagDecision := range decisions {
+		s.Contains(flagDecision.Reasons, `user profile lookup failed for user "tester": connection refused`)
+	}
+	// decisions are not saved over the profile that could not be looked up
+	userProfileService.AssertNotCalled(s.T(), "SaveWithContext", mock.Anything, mock.Anything)
+}
+
+func (s *OptimizelyUserContextTestSuite) TestDecideAllWithUserProfileSaveFailure() {
+	userProfileService := new(MockContextUserProfileService)
+	var err error
+	s.OptimizelyClient, err = s.factory.Client(
+		WithEventProcessor(s.eventProcessor),
+		WithUserProfileService(userProfileService),
+	)
+	s.Nil(err)
+
+	userProfileService.On("LookupWithContext", mock.Anything, s.userID).Return(decision.UserProfile{ID: s.userID}, nil)
+	userProfileService.On("SaveWithContext", mock.Anything, mock.Anything).Return(errors.New("read-only replica"))
+
+	user := s.OptimizelyClient.CreateUserContext(s.userID, nil)
+	decisions := user.DecideAll(nil)
+	s.Len(decisions, 3)
+	for _, flagDecision := range decisions {
+		s.Contains(flagDecision.Reasons, `user profile save failed for user "tester": read-only replica`)
+	}
+	userProfileService.AssertNumberOfCalls(s.T(), "SaveWithContext", 1)
+}
+
+func (s *OptimizelyUserContextTestSuite) TestDecideForUsersWithBatchUserProfileService() {
+	flagKey := "feature_2" // embedding experiment: "exp_no_audience"
+	experimentID := "10420810910"
+	variationID2 := "10418510624"
+	variationKey1 := "variation_with_traffic"
+	variationKey2 := "variation_no_traffic"
+	userProfileService := new(MockBatchUserProfileService)
+	var err error
+	s.OptimizelyClient, err = s.factory.Client(
+		WithEventProcessor(s.eventProcessor),
+		WithUserProfileService(userProfileService),
+	)
+	s.Nil(err)
+
+	savedUserProfile := decision.UserProfile{
+		ID:                  s.userID,
+		ExperimentBucketMap: map[decision.UserDecisionKey]string{decision.NewUserDecisionKey(experimentID): variationID2},
+	}
+	userProfileService.On("LookupBatch", mock.Anything, []string{s.userID, "tester_2", s.userID}).Return(map[string]decision.UserProfile{s.userID: savedUserProfile}, nil)
+	userProfileService.On("SaveBatch", mock.Anything, mock.Anything).Return(nil)
+
+	users := []OptimizelyUserContext{
+		s.OptimizelyClient.CreateUserContext(s.userID, nil),
+		s.OptimizelyClient.CreateUserContext("tester_2", nil),
+		s.OptimizelyClient.CreateUserContext(s.userID, nil),
+	}
+	decisions := s.OptimizelyClient.DecideForUsers(users, []string{flagKey}, nil)
+	s.Len(decisions, 3)
+	s.Equal(variationKey2, decisions[0][flagKey].VariationKey)
+	s.Equal(variationKey1, decisions[1][flagKey].VariationKey)
+	s.Equal(variationKey2, decisions[2][flagKey].VariationKey)
+
+	// only the profile of the user bucketed for the first time changed
+	userProfileService.AssertNumberOfCalls(s.T(), "LookupBatch", 1)
+	userProfileService.AssertNumberOfCalls(s.T(), "SaveBatch", 1)
+	savedProfiles := userProfileService.Calls[1].Arguments.Get(1).([]decision.UserProfile)
+	s.Len(savedProfiles, 1)
+	s.Equal("tester_2", savedProfiles[0].ID)
+	userProfileService.AssertNotCalled(s.T(), "Lookup", mock.Anything)
+	userProfileService.AssertNotCalled(s.T(), "Save", mock.Anything)
+}
+
+func (s *OptimizelyUserContextTestSuite) TestDecideAllForUsersWithUserProfileService() {
+	userProfileService := new(MockUserProfileService)
+	var err error
+	s.OptimizelyClient, err = s.factory.Client(
+		WithEventProcessor(s.eventProcessor),
+		WithUserProfileService(userProfileService),
+	)
+	s.Nil(err)
+
+	userProfileService.On("Lookup", mock.Anything).Return(decision.UserProfile{})
+	userProfileService.On("Save", mock.Anything)
+
+	users := []OptimizelyUserContext{
+		s.OptimizelyClient.CreateUserContext(s.userID, nil),
+		s.OptimizelyClient.CreateUserContext("tester_2", nil),
+	}
+	decisions := s.OptimizelyClient.DecideAllForUsers(users, nil)
+	s.Len(decisions, 2)
+	s.Len(decisions[0], 3)
+	s.Len(decisions[1], 3)
+	s.Equal(users[0].DecideAll(nil), decisions[0])
+
+	// the decisions of each user are saved once for all the flags
+	userProfileService.AssertNumberOfCalls(s.T(), "Lookup", 3)
+	userProfileService.AssertNumberOfCalls(s.T(), "Save", 3)
+
+	decisions = s.OptimizelyClient.DecideAllForUsers(users, []decide.OptimizelyDecideOptions{decide.EnabledFlagsOnly, decide.IgnoreUserProfileService})
+	for _, decisionMap := range decisions {
+		for _, flagDecision := range decisionMap {
+			s.True(flagDecision.Enabled)
+		}
+	}
+	userProfileService.AssertNumberOfCalls(s.T(), "Lookup", 3)
+}
+
+func (s *OptimizelyUserContextTestSuite) TestDecideForUsersWithBatchLookupFailure() {
+	userProfileService := new(MockBatchUserProfileService)
+	var err error
+	s.OptimizelyClient, err = s.factory.Client(
+		WithEventProcessor(s.eventProcessor),
+		WithUserProfileService(userProfileService),
+	)
+	s.Nil(err)
+
+	userProfileService.On("LookupBatch", mock.Anything, mock.Anything).Return(map[string]decision.UserProfile(nil), errors.New("connection refused"))
+
+	users := []OptimizelyUserContext{
+		s.OptimizelyClient.CreateUserContext(s.userID, nil),
+		s.OptimizelyClient.CreateUserContext("tester_2", nil),
+	}
+	decisions := s.OptimizelyClient.DecideForUsers(users, []string{"feature_1", "feature_2"}, nil)
+	s.Len(decisions, 2)
+	s.Contains(decisions[0]["feature_1"].Reasons, `user profile lookup failed for user "tester": connection refused`)
+	s.Contains(decisions[1]["feature_2"].Reasons, `user profile lookup failed for user "tester_2": connection refused`)
+	userProfileService.AssertNotCalled(s.T(), "SaveBatch", mock.Anything, mock.Anything)
+}
+
 func (s *OptimizelyUserContextTestSuite) TestDecideForKeysWithBatchUPS() {
 	flagKey1 := "feature_1"
 	experimentID1 := "10390977673"
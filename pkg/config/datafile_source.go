@@ -0,0 +1,98 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package config //
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/optimizely/go-sdk/v2/pkg/utils"
+)
+
+// DatafileSource is a location the polling manager can fetch the datafile from
+type DatafileSource interface {
+	// Name identifies the source in logs and notifications
+	Name() string
+	// Fetch returns the datafile for the given sdk key. The requester is the one configured on the polling manager
+	// and headers carry the conditional request headers from the previous response of this source.
+	Fetch(sdkKey string, requester utils.Requester, headers ...utils.Header) (datafile []byte, respHeaders http.Header, code int, err error)
+}
+
+// DatafileFetcherFunc is a user supplied function returning the datafile for the given sdk key
+type DatafileFetcherFunc func(sdkKey string) ([]byte, error)
+
+type urlTemplateSource struct {
+	template string
+}
+
+// NewURLTemplateSource returns a source fetching the datafile over http from a URL template formatted with the sdk key
+func NewURLTemplateSource(template string) DatafileSource {
+	return urlTemplateSource{template: template}
+}
+
+func (s urlTemplateSource) Name() string {
+	return s.template
+}
+
+func (s urlTemplateSource) Fetch(sdkKey string, requester utils.Requester, headers ...utils.Header) ([]byte, http.Header, int, error) {
+	return requester.Get(fmt.Sprintf(s.template, sdkKey), headers...)
+}
+
+type fileSource struct {
+	path string
+}
+
+// NewFileSource returns a source reading the datafile from a local file
+func NewFileSource(path string) DatafileSource {
+	return fileSource{path: path}
+}
+
+func (s fileSource) Name() string {
+	return "file:" + s.path
+}
+
+func (s fileSource) Fetch(sdkKey string, requester utils.Requester, headers ...utils.Header) ([]byte, http.Header, int, error) {
+	datafile, err := os.ReadFile(s.path) // #nosec G304 - path is configured by the user
+	if err != nil {
+		return nil, http.Header{}, 0, err
+	}
+	return datafile, http.Header{}, http.StatusOK, nil
+}
+
+type fetcherSource struct {
+	name  string
+	fetch DatafileFetcherFunc
+}
+
+// NewFetcherSource returns a source calling the given function, identified by name
+func NewFetcherSource(name string, fetch DatafileFetcherFunc) DatafileSource {
+	return fetcherSource{name: name, fetch: fetch}
+}
+
+func (s fetcherSource) Name() string {
+	return s.name
+}
+
+func (s fetcherSource) Fetch(sdkKey string, requester utils.Requester, headers ...utils.Header) ([]byte, http.Header, int, error) {
+	datafile, err := s.fetch(sdkKey)
+	if err != nil {
+		return nil, http.Header{}, 0, err
+	}
+	return datafile, http.Header{}, http.StatusOK, nil
+}
@@ -0,0 +1,81 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package config
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/optimizely/go-sdk/v2/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestURLTemplateSource(t *testing.T) {
+	mockDatafile := []byte(`{"revision":"42","version": "4"}`)
+	headers := []utils.Header{{Name: ModifiedSince, Value: "Wed, 16 Oct 2019 20:16:45 GMT"}}
+	mockRequester := new(MockRequester)
+	mockRequester.On("Get", headers).Return(mockDatafile, http.Header{}, http.StatusOK, nil)
+
+	source := NewURLTemplateSource(DatafileURLTemplate)
+	assert.Equal(t, DatafileURLTemplate, source.Name())
+
+	datafile, _, code, err := source.Fetch("test_sdk_key", mockRequester, headers...)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, mockDatafile, datafile)
+	mockRequester.AssertExpectations(t)
+}
+
+func TestFileSource(t *testing.T) {
+	mockDatafile := []byte(`{"revision":"42","version": "4"}`)
+	path := filepath.Join(t.TempDir(), "datafile.json")
+	assert.NoError(t, os.WriteFile(path, mockDatafile, 0o600))
+
+	source := NewFileSource(path)
+	assert.Equal(t, "file:"+path, source.Name())
+
+	datafile, _, code, err := source.Fetch("test_sdk_key", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, mockDatafile, datafile)
+
+	_, _, _, err = NewFileSource(filepath.Join(t.TempDir(), "missing.json")).Fetch("test_sdk_key", nil)
+	assert.Error(t, err)
+}
+
+func TestFetcherSource(t *testing.T) {
+	mockDatafile := []byte(`{"revision":"42","version": "4"}`)
+	source := NewFetcherSource("custom", func(sdkKey string) ([]byte, error) {
+		if sdkKey != "test_sdk_key" {
+			return nil, errors.New("unknown sdk key")
+		}
+		return mockDatafile, nil
+	})
+	assert.Equal(t, "custom", source.Name())
+
+	datafile, _, code, err := source.Fetch("test_sdk_key", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, mockDatafile, datafile)
+
+	_, _, _, err = source.Fetch("other_sdk_key", nil)
+	assert.Error(t, err)
+}
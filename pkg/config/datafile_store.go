@@ -0,0 +1,133 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package config //
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// StoredDatafile is a datafile revision persisted by a DatafileStore along with its response metadata
+type StoredDatafile struct {
+	Datafile     []byte
+	LastModified string
+	ETag         string
+	Source       string
+	// Headers are the response headers the datafile was served with so that its signature can be verified on load
+	Headers http.Header
+}
+
+// DatafileStore persists the latest datafile fetched by a config manager so that it can be restored on restart
+type DatafileStore interface {
+	// Load returns the stored datafile for the given sdk key, or nil if nothing has been stored yet
+	Load(sdkKey string) (*StoredDatafile, error)
+	// Save replaces the stored datafile for the given sdk key
+	Save(sdkKey string, storedDatafile StoredDatafile) error
+}
+
+// FileDatafileStore is a DatafileStore that keeps one file per sdk key inside a directory
+type FileDatafileStore struct {
+	dir  string
+	lock sync.Mutex
+}
+
+type fileDatafileEntry struct {
+	LastModified string      `json:"lastModified,omitempty"`
+	ETag         string      `json:"etag,omitempty"`
+	Source       string      `json:"source,omitempty"`
+	Headers      http.Header `json:"headers,omitempty"`
+	// the datafile is kept as a string since its exact bytes are needed to verify its signature
+	Datafile string `json:"datafile"`
+}
+
+// NewFileDatafileStore returns a file backed DatafileStore writing into the given directory
+func NewFileDatafileStore(dir string) *FileDatafileStore {
+	return &FileDatafileStore{dir: dir}
+}
+
+// Load reads the stored datafile for the given sdk key
+func (s *FileDatafileStore) Load(sdkKey string) (*StoredDatafile, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	data, err := os.ReadFile(s.path(sdkKey)) // #nosec G304 - path is built from the configured directory
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var entry fileDatafileEntry
+	if err = json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+	if len(entry.Datafile) == 0 {
+		return nil, errors.New("stored datafile is empty")
+	}
+	return &StoredDatafile{Datafile: []byte(entry.Datafile), LastModified: entry.LastModified, ETag: entry.ETag, Source: entry.Source,
+		Headers: entry.Headers}, nil
+}
+
+// Save atomically replaces the stored datafile for the given sdk key
+func (s *FileDatafileStore) Save(sdkKey string, storedDatafile StoredDatafile) error {
+	data, err := json.Marshal(fileDatafileEntry{
+		LastModified: storedDatafile.LastModified,
+		ETag:         storedDatafile.ETag,
+		Source:       storedDatafile.Source,
+		Headers:      storedDatafile.Headers,
+		Datafile:     string(storedDatafile.Datafile),
+	})
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err = os.MkdirAll(s.dir, 0o750); err != nil {
+		return err
+	}
+	// Write into a temporary file first so that a crash never leaves a truncated datafile behind
+	tmp, err := os.CreateTemp(s.dir, ".datafile-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// no-op once the temporary file has been renamed
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(sdkKey))
+}
+
+func (s *FileDatafileStore) path(sdkKey string) string {
+	return filepath.Join(s.dir, url.PathEscape(sdkKey)+".json")
+}
@@ -0,0 +1,85 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileDatafileStoreLoadMissing(t *testing.T) {
+	store := NewFileDatafileStore(t.TempDir())
+
+	storedDatafile, err := store.Load("test_sdk_key")
+	assert.NoError(t, err)
+	assert.Nil(t, storedDatafile)
+}
+
+func TestFileDatafileStoreSaveAndLoad(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "nested")
+	store := NewFileDatafileStore(dir)
+	datafile := []byte(`{"revision":"42","version":"4"}`)
+
+	err := store.Save("test_sdk_key", StoredDatafile{Datafile: datafile, LastModified: "Wed, 16 Oct 2019 20:16:45 GMT"})
+	assert.NoError(t, err)
+
+	storedDatafile, err := store.Load("test_sdk_key")
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(datafile), string(storedDatafile.Datafile))
+	assert.Equal(t, "Wed, 16 Oct 2019 20:16:45 GMT", storedDatafile.LastModified)
+
+	// Saving again replaces the previous revision
+	err = store.Save("test_sdk_key", StoredDatafile{Datafile: []byte(`{"revision":"43","version":"4"}`)})
+	assert.NoError(t, err)
+	storedDatafile, err = store.Load("test_sdk_key")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"revision":"43","version":"4"}`, string(storedDatafile.Datafile))
+	assert.Equal(t, "", storedDatafile.LastModified)
+
+	// No temporary files are left behind
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestFileDatafileStoreKeepsSDKKeysApart(t *testing.T) {
+	store := NewFileDatafileStore(t.TempDir())
+
+	assert.NoError(t, store.Save("key_1", StoredDatafile{Datafile: []byte(`{"revision":"1"}`)}))
+	assert.NoError(t, store.Save("../key_2", StoredDatafile{Datafile: []byte(`{"revision":"2"}`)}))
+
+	storedDatafile, err := store.Load("key_1")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"revision":"1"}`, string(storedDatafile.Datafile))
+
+	storedDatafile, err = store.Load("../key_2")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"revision":"2"}`, string(storedDatafile.Datafile))
+}
+
+func TestFileDatafileStoreLoadCorrupted(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileDatafileStore(dir)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "test_sdk_key.json"), []byte("NOT-VALID"), 0o600))
+
+	storedDatafile, err := store.Load("test_sdk_key")
+	assert.Error(t, err)
+	assert.Nil(t, storedDatafile)
+}
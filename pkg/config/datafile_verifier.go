@@ -0,0 +1,119 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package config //
+package config
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/optimizely/go-sdk/v2/pkg/utils"
+)
+
+// DatafileSignatureHeader is the default response header carrying a base64 encoded datafile signature
+const DatafileSignatureHeader = "X-Datafile-Signature"
+
+// ErrInvalidDatafileSignature is returned when a datafile does not match its signature
+var ErrInvalidDatafileSignature = errors.New("datafile signature is invalid")
+
+// DatafileVerifier checks the integrity of a fetched datafile before it replaces the current config
+type DatafileVerifier interface {
+	Verify(sdkKey string, datafile []byte, respHeaders http.Header) error
+}
+
+// DatafileVerifierFunc adapts a function to the DatafileVerifier interface
+type DatafileVerifierFunc func(sdkKey string, datafile []byte, respHeaders http.Header) error
+
+// Verify calls f
+func (f DatafileVerifierFunc) Verify(sdkKey string, datafile []byte, respHeaders http.Header) error {
+	return f(sdkKey, datafile, respHeaders)
+}
+
+// SignatureFunc returns the detached signature of a datafile
+type SignatureFunc func(sdkKey string, respHeaders http.Header) ([]byte, error)
+
+// SignatureFromHeader reads a base64 encoded signature from the given response header
+func SignatureFromHeader(name string) SignatureFunc {
+	return func(sdkKey string, respHeaders http.Header) ([]byte, error) {
+		value := respHeaders.Get(name)
+		if value == "" {
+			return nil, fmt.Errorf("missing signature header %s", name)
+		}
+		return base64.StdEncoding.DecodeString(value)
+	}
+}
+
+// SignatureFromURL fetches a base64 encoded signature from a URL template formatted with the sdk key
+func SignatureFromURL(template string, requester utils.Requester) SignatureFunc {
+	return func(sdkKey string, respHeaders http.Header) ([]byte, error) {
+		body, _, _, err := requester.Get(fmt.Sprintf(template, sdkKey))
+		if err != nil {
+			return nil, fmt.Errorf("unable to fetch signature: %w", err)
+		}
+		return base64.StdEncoding.DecodeString(strings.TrimSpace(string(body)))
+	}
+}
+
+type ed25519Verifier struct {
+	publicKey ed25519.PublicKey
+	signature SignatureFunc
+}
+
+// NewEd25519Verifier returns a verifier checking an Ed25519 detached signature of the datafile
+func NewEd25519Verifier(publicKey ed25519.PublicKey, signature SignatureFunc) DatafileVerifier {
+	return ed25519Verifier{publicKey: publicKey, signature: signature}
+}
+
+func (v ed25519Verifier) Verify(sdkKey string, datafile []byte, respHeaders http.Header) error {
+	signature, err := v.signature(sdkKey, respHeaders)
+	if err != nil {
+		return err
+	}
+	if len(v.publicKey) != ed25519.PublicKeySize || !ed25519.Verify(v.publicKey, datafile, signature) {
+		return ErrInvalidDatafileSignature
+	}
+	return nil
+}
+
+type hmacVerifier struct {
+	key       []byte
+	signature SignatureFunc
+}
+
+// NewHMACVerifier returns a verifier checking an HMAC-SHA256 of the datafile computed with a shared key
+func NewHMACVerifier(key []byte, signature SignatureFunc) DatafileVerifier {
+	return hmacVerifier{key: key, signature: signature}
+}
+
+func (v hmacVerifier) Verify(sdkKey string, datafile []byte, respHeaders http.Header) error {
+	signature, err := v.signature(sdkKey, respHeaders)
+	if err != nil {
+		return err
+	}
+	mac := hmac.New(sha256.New, v.key)
+	mac.Write(datafile)
+	if !hmac.Equal(mac.Sum(nil), signature) {
+		return ErrInvalidDatafileSignature
+	}
+	return nil
+}
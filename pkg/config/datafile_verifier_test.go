@@ -0,0 +1,181 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package config
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/optimizely/go-sdk/v2/pkg/notification"
+	"github.com/optimizely/go-sdk/v2/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func signatureHeaders(signature []byte) http.Header {
+	headers := http.Header{}
+	headers.Set(DatafileSignatureHeader, base64.StdEncoding.EncodeToString(signature))
+	return headers
+}
+
+func TestEd25519Verifier(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	datafile := []byte(`{"revision":"42","version": "4"}`)
+	verifier := NewEd25519Verifier(publicKey, SignatureFromHeader(DatafileSignatureHeader))
+
+	assert.NoError(t, verifier.Verify("test_sdk_key", datafile, signatureHeaders(ed25519.Sign(privateKey, datafile))))
+	assert.Equal(t, ErrInvalidDatafileSignature, verifier.Verify("test_sdk_key", []byte(`{"revision":"43"}`), signatureHeaders(ed25519.Sign(privateKey, datafile))))
+	assert.Error(t, verifier.Verify("test_sdk_key", datafile, http.Header{}))
+}
+
+func TestHMACVerifier(t *testing.T) {
+	key := []byte("shared-secret")
+	datafile := []byte(`{"revision":"42","version": "4"}`)
+	mac := hmac.New(sha256.New, key)
+	mac.Write(datafile)
+	verifier := NewHMACVerifier(key, SignatureFromHeader(DatafileSignatureHeader))
+
+	assert.NoError(t, verifier.Verify("test_sdk_key", datafile, signatureHeaders(mac.Sum(nil))))
+	assert.Equal(t, ErrInvalidDatafileSignature, verifier.Verify("test_sdk_key", datafile, signatureHeaders([]byte("forged"))))
+}
+
+func TestSignatureFromURL(t *testing.T) {
+	signature := []byte("signature")
+	mockRequester := new(MockRequester)
+	mockRequester.On("Get", []utils.Header(nil)).Return([]byte(base64.StdEncoding.EncodeToString(signature)+"\n"), http.Header{}, http.StatusOK, nil).Times(1)
+
+	actual, err := SignatureFromURL("https://localhost/%s.sig", mockRequester)("test_sdk_key", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, signature, actual)
+
+	mockRequester.On("Get", []utils.Header(nil)).Return([]byte{}, http.Header{}, http.StatusNotFound, errors.New("404 Not Found")).Times(1)
+	_, err = SignatureFromURL("https://localhost/%s.sig", mockRequester)("test_sdk_key", nil)
+	assert.Error(t, err)
+	mockRequester.AssertExpectations(t)
+}
+
+func TestSyncConfigRejectsUnverifiedDatafile(t *testing.T) {
+	mockDatafile1 := []byte(`{"revision":"42","version": "4"}`)
+	mockDatafile2 := []byte(`{"revision":"43","version": "4"}`)
+	forgedHeaders := signatureHeaders([]byte("forged"))
+	forgedHeaders.Set(LastModified, "Wed, 16 Oct 2019 20:16:45 GMT")
+	key := []byte("shared-secret")
+	mac := hmac.New(sha256.New, key)
+	mac.Write(mockDatafile2)
+
+	mockRequester := new(MockRequester)
+	mockRequester.On("Get", []utils.Header(nil)).Return(mockDatafile2, forgedHeaders, http.StatusOK, nil).Times(1)
+
+	sdkKey := "test_sdk_key_verifier"
+	configManager := NewAsyncPollingProjectConfigManager(sdkKey, WithRequester(mockRequester), WithInitialDatafile(mockDatafile1),
+		WithDatafileVerifier(NewHMACVerifier(key, SignatureFromHeader(DatafileSignatureHeader))))
+	var rejections []notification.DatafileRejectedNotification
+	id, err := configManager.OnDatafileRejected(func(n notification.DatafileRejectedNotification) {
+		rejections = append(rejections, n)
+	})
+	assert.NoError(t, err)
+
+	// The forged revision is refused and the current config is kept
+	configManager.SyncConfig()
+	actual, err := configManager.GetConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "42", actual.GetRevision())
+	assert.Len(t, rejections, 1)
+	assert.Equal(t, "43", rejections[0].Revision)
+	assert.Equal(t, DatafileURLTemplate, rejections[0].Source)
+	assert.ErrorIs(t, rejections[0].Reason, ErrInvalidDatafileSignature)
+	assert.Equal(t, notification.PollRejected, configManager.lastPollOutcome)
+
+	// Validators of the refused response are not kept, so the next poll fetches the full datafile again
+	mockRequester.On("Get", []utils.Header(nil)).Return(mockDatafile2, signatureHeaders(mac.Sum(nil)), http.StatusOK, nil).Times(1)
+	configManager.SyncConfig()
+	actual, _ = configManager.GetConfig()
+	assert.Equal(t, "43", actual.GetRevision())
+	assert.Len(t, rejections, 1)
+	mockRequester.AssertExpectations(t)
+	assert.NoError(t, configManager.RemoveOnDatafileRejected(id))
+}
+
+func TestSyncConfigVerifiesWithoutBlockingGetConfig(t *testing.T) {
+	mockDatafile1 := []byte(`{"revision":"42","version": "4"}`)
+	mockDatafile2 := []byte(`{"revision":"43","version": "4"}`)
+	mockRequester := new(MockRequester)
+	mockRequester.On("Get", []utils.Header(nil)).Return(mockDatafile2, http.Header{}, http.StatusOK, nil).Times(1)
+
+	verifying := make(chan struct{})
+	release := make(chan struct{})
+	verifier := DatafileVerifierFunc(func(sdkKey string, datafile []byte, respHeaders http.Header) error {
+		// e.g. SignatureFromURL fetching the signature
+		close(verifying)
+		<-release
+		return nil
+	})
+	configManager := NewAsyncPollingProjectConfigManager("test_sdk_key_slow_verifier", WithRequester(mockRequester),
+		WithInitialDatafile(mockDatafile1), WithDatafileVerifier(verifier))
+
+	synced := make(chan struct{})
+	go func() {
+		configManager.SyncConfig()
+		close(synced)
+	}()
+	<-verifying
+
+	// the current config is served while the fetched datafile is verified
+	actual, err := configManager.GetConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "42", actual.GetRevision())
+
+	close(release)
+	<-synced
+	actual, _ = configManager.GetConfig()
+	assert.Equal(t, "43", actual.GetRevision())
+	mockRequester.AssertExpectations(t)
+}
+
+func TestStoredDatafileIsVerifiedOnLoad(t *testing.T) {
+	key := []byte("shared-secret")
+	datafile := []byte(`{"revision":"42","version": "4"}`)
+	mac := hmac.New(sha256.New, key)
+	mac.Write(datafile)
+	verifier := NewHMACVerifier(key, SignatureFromHeader(DatafileSignatureHeader))
+	modifiedDate := "Wed, 16 Oct 2019 20:16:45 GMT"
+
+	store := NewFileDatafileStore(t.TempDir())
+	sdkKey := "test_sdk_key_stored_verifier"
+	assert.NoError(t, store.Save(sdkKey, StoredDatafile{Datafile: datafile, LastModified: modifiedDate, Headers: signatureHeaders(mac.Sum(nil))}))
+
+	configManager := NewAsyncPollingProjectConfigManager(sdkKey, WithDatafileStore(store), WithDatafileVerifier(verifier))
+	actual, err := configManager.GetConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "42", actual.GetRevision())
+	assert.Equal(t, modifiedDate, configManager.lastModified)
+
+	// A tampered datafile is not served and its validators are not reused, so the next poll fetches the full datafile
+	assert.NoError(t, store.Save(sdkKey, StoredDatafile{Datafile: []byte(`{"revision":"666","version": "4"}`), LastModified: modifiedDate,
+		Headers: signatureHeaders(mac.Sum(nil))}))
+	configManager = NewAsyncPollingProjectConfigManager(sdkKey, WithDatafileStore(store), WithDatafileVerifier(verifier))
+	actual, _ = configManager.GetConfig()
+	assert.Nil(t, actual)
+	assert.Empty(t, configManager.lastModified)
+}
@@ -0,0 +1,310 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package config //
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/optimizely/go-sdk/v2/pkg/entities"
+)
+
+// ChangeType tells how an entity differs between two revisions
+type ChangeType string
+
+const (
+	// ChangeAdded is used for entities only present in the new revision
+	ChangeAdded ChangeType = "added"
+	// ChangeRemoved is used for entities only present in the old revision
+	ChangeRemoved ChangeType = "removed"
+	// ChangeModified is used for entities present in both revisions with different fields
+	ChangeModified ChangeType = "changed"
+)
+
+// EntityKind is the kind of entity a change applies to
+type EntityKind string
+
+const (
+	// EntityFlag is used for feature flags, keyed by flag key
+	EntityFlag EntityKind = "flag"
+	// EntityExperiment is used for experiments, keyed by experiment key
+	EntityExperiment EntityKind = "experiment"
+	// EntityRolloutRule is used for delivery rules, keyed by "<flag key>/<rule key>"
+	EntityRolloutRule EntityKind = "rollout_rule"
+	// EntityAudience is used for audiences, keyed by audience id
+	EntityAudience EntityKind = "audience"
+	// EntityHoldout is used for holdouts, keyed by holdout key
+	EntityHoldout EntityKind = "holdout"
+	// EntityVariable is used for flag variables, keyed by "<flag key>/<variable key>"
+	EntityVariable EntityKind = "variable"
+	// EntityTrafficAllocation is used for the traffic allocation of a rule, keyed like the rule itself
+	EntityTrafficAllocation EntityKind = "traffic_allocation"
+)
+
+// FieldChange is the old and new value of a single changed field
+type FieldChange struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// Change describes how a single entity differs between two revisions
+type Change struct {
+	Kind   EntityKind
+	Type   ChangeType
+	Key    string
+	Fields []FieldChange // only set for ChangeModified
+}
+
+func (c Change) String() string {
+	if c.Type != ChangeModified {
+		return fmt.Sprintf("%s %s %s", c.Kind, c.Key, c.Type)
+	}
+	fields := make([]string, 0, len(c.Fields))
+	for _, f := range c.Fields {
+		fields = append(fields, fmt.Sprintf("%s: %q -> %q", f.Field, f.Old, f.New))
+	}
+	return fmt.Sprintf("%s %s %s (%s)", c.Kind, c.Key, c.Type, strings.Join(fields, ", "))
+}
+
+// ConfigDiff lists the changes between two project config revisions
+type ConfigDiff struct {
+	OldRevision string
+	NewRevision string
+	Changes     []Change
+}
+
+// IsEmpty returns whether the two revisions have no differences
+func (d *ConfigDiff) IsEmpty() bool {
+	return len(d.Changes) == 0
+}
+
+// Diff reports the flags, experiments, rollout rules, audiences, holdouts, variables and traffic allocations
+// that were added, removed or changed from oldConfig to newConfig. A nil oldConfig reports everything as added.
+func Diff(oldConfig, newConfig ProjectConfig) *ConfigDiff {
+	diff := &ConfigDiff{}
+	if oldConfig != nil {
+		diff.OldRevision = oldConfig.GetRevision()
+	}
+	if newConfig != nil {
+		diff.NewRevision = newConfig.GetRevision()
+	}
+
+	oldSnapshot, newSnapshot := newConfigSnapshot(oldConfig), newConfigSnapshot(newConfig)
+	for _, kind := range diffedKinds {
+		oldEntities, newEntities := oldSnapshot[kind], newSnapshot[kind]
+		keys := make([]string, 0, len(oldEntities)+len(newEntities))
+		for key := range oldEntities {
+			keys = append(keys, key)
+		}
+		for key := range newEntities {
+			if _, ok := oldEntities[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			oldEntity, inOld := oldEntities[key]
+			newEntity, inNew := newEntities[key]
+			switch {
+			case !inOld:
+				diff.Changes = append(diff.Changes, Change{Kind: kind, Type: ChangeAdded, Key: key})
+			case !inNew:
+				diff.Changes = append(diff.Changes, Change{Kind: kind, Type: ChangeRemoved, Key: key})
+			default:
+				diff.Changes = append(diff.Changes, compareEntity(kind, key, oldEntity, newEntity)...)
+			}
+		}
+	}
+	return diff
+}
+
+// diffedKinds are the entity kinds a config snapshot holds, in the order their changes are reported
+var diffedKinds = []EntityKind{EntityFlag, EntityVariable, EntityExperiment, EntityRolloutRule, EntityHoldout, EntityAudience}
+
+// configSnapshot indexes the entities of a project config by kind and by the key they are diffed on
+type configSnapshot map[EntityKind]map[string]interface{}
+
+func newConfigSnapshot(projectConfig ProjectConfig) configSnapshot {
+	snapshot := configSnapshot{}
+	for _, kind := range diffedKinds {
+		snapshot[kind] = map[string]interface{}{}
+	}
+	if projectConfig == nil {
+		return snapshot
+	}
+
+	for _, experiment := range projectConfig.GetExperimentList() {
+		snapshot[EntityExperiment][experiment.Key] = experiment
+	}
+	for _, holdout := range projectConfig.GetGlobalHoldouts() {
+		snapshot[EntityHoldout][holdout.Key] = holdout
+	}
+	for _, feature := range projectConfig.GetFeatureList() {
+		snapshot[EntityFlag][feature.Key] = feature
+		for _, variable := range feature.VariableMap {
+			snapshot[EntityVariable][feature.Key+"/"+variable.Key] = variable
+		}
+		for _, rule := range feature.Rollout.Experiments {
+			snapshot[EntityRolloutRule][feature.Key+"/"+rule.Key] = rule
+		}
+		for _, rule := range append(append([]entities.Experiment{}, feature.FeatureExperiments...), feature.Rollout.Experiments...) {
+			for _, holdout := range projectConfig.GetHoldoutsForRule(rule.ID) {
+				snapshot[EntityHoldout][holdout.Key] = holdout
+			}
+		}
+	}
+	for _, audience := range projectConfig.GetAudienceList() {
+		snapshot[EntityAudience][audience.ID] = audience
+	}
+	return snapshot
+}
+
+// compareEntity returns the change of an entity present in both revisions, followed by the change of its traffic allocation
+func compareEntity(kind EntityKind, key string, oldEntity, newEntity interface{}) (changes []Change) {
+	var fields []FieldChange
+	var oldRanges, newRanges []entities.Range
+	switch oldValue := oldEntity.(type) {
+	case entities.Feature:
+		fields = compareFlag(oldValue, newEntity.(entities.Feature))
+	case entities.Variable:
+		fields = compareVariable(oldValue, newEntity.(entities.Variable))
+	case entities.Experiment:
+		newValue := newEntity.(entities.Experiment)
+		fields = compareRule(oldValue, newValue)
+		oldRanges, newRanges = oldValue.TrafficAllocation, newValue.TrafficAllocation
+	case entities.Holdout:
+		newValue := newEntity.(entities.Holdout)
+		fields = compareHoldout(oldValue, newValue)
+		oldRanges, newRanges = oldValue.TrafficAllocation, newValue.TrafficAllocation
+	case entities.Audience:
+		fields = compareAudience(oldValue, newEntity.(entities.Audience))
+	}
+
+	if len(fields) > 0 {
+		changes = append(changes, Change{Kind: kind, Type: ChangeModified, Key: key, Fields: fields})
+	}
+	if oldAllocation, newAllocation := describeRanges(oldRanges), describeRanges(newRanges); oldAllocation != newAllocation {
+		changes = append(changes, Change{Kind: EntityTrafficAllocation, Type: ChangeModified, Key: key, Fields: []FieldChange{
+			{Field: "trafficAllocation", Old: oldAllocation, New: newAllocation},
+		}})
+	}
+	return changes
+}
+
+func compareFlag(oldFlag, newFlag entities.Feature) (fields []FieldChange) {
+	fields = appendFieldChange(fields, "rolloutId", oldFlag.Rollout.ID, newFlag.Rollout.ID)
+	fields = appendFieldChange(fields, "experimentIds", sortedList(oldFlag.ExperimentIDs), sortedList(newFlag.ExperimentIDs))
+	return fields
+}
+
+func compareVariable(oldVariable, newVariable entities.Variable) (fields []FieldChange) {
+	fields = appendFieldChange(fields, "type", string(oldVariable.Type), string(newVariable.Type))
+	fields = appendFieldChange(fields, "defaultValue", oldVariable.DefaultValue, newVariable.DefaultValue)
+	return fields
+}
+
+// compareRule compares experiments and rollout rules, traffic allocation is reported as a change of its own
+func compareRule(oldRule, newRule entities.Experiment) (fields []FieldChange) {
+	fields = appendFieldChange(fields, "layerId", oldRule.LayerID, newRule.LayerID)
+	fields = appendFieldChange(fields, "audienceIds", sortedList(oldRule.AudienceIds), sortedList(newRule.AudienceIds))
+	fields = appendFieldChange(fields, "audienceConditions", marshalToString(oldRule.AudienceConditions), marshalToString(newRule.AudienceConditions))
+	fields = appendFieldChange(fields, "variations", describeVariations(oldRule.Variations), describeVariations(newRule.Variations))
+	return fields
+}
+
+func compareHoldout(oldHoldout, newHoldout entities.Holdout) (fields []FieldChange) {
+	fields = appendFieldChange(fields, "status", string(oldHoldout.Status), string(newHoldout.Status))
+	fields = appendFieldChange(fields, "audienceIds", sortedList(oldHoldout.AudienceIds), sortedList(newHoldout.AudienceIds))
+	fields = appendFieldChange(fields, "audienceConditions", marshalToString(oldHoldout.AudienceConditions), marshalToString(newHoldout.AudienceConditions))
+	fields = appendFieldChange(fields, "variations", describeVariations(oldHoldout.Variations), describeVariations(newHoldout.Variations))
+	var oldRules, newRules []string
+	if oldHoldout.IncludedRules != nil {
+		oldRules = *oldHoldout.IncludedRules
+	}
+	if newHoldout.IncludedRules != nil {
+		newRules = *newHoldout.IncludedRules
+	}
+	fields = appendFieldChange(fields, "includedRules", sortedList(oldRules), sortedList(newRules))
+	return fields
+}
+
+func compareAudience(oldAudience, newAudience entities.Audience) (fields []FieldChange) {
+	fields = appendFieldChange(fields, "name", oldAudience.Name, newAudience.Name)
+	fields = appendFieldChange(fields, "conditions", marshalToString(oldAudience.Conditions), marshalToString(newAudience.Conditions))
+	return fields
+}
+
+func appendFieldChange(fields []FieldChange, field, oldValue, newValue string) []FieldChange {
+	if oldValue == newValue {
+		return fields
+	}
+	return append(fields, FieldChange{Field: field, Old: oldValue, New: newValue})
+}
+
+func sortedList(values []string) string {
+	sorted := append([]string{}, values...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+func marshalToString(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	if value == nil {
+		return ""
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// describeVariations renders variations as "key[on|off]{variableId=value,...}" in key order
+func describeVariations(variations map[string]entities.Variation) string {
+	descriptions := make([]string, 0, len(variations))
+	for _, variation := range variations {
+		variables := make([]string, 0, len(variation.Variables))
+		for id, variable := range variation.Variables {
+			variables = append(variables, id+"="+variable.Value)
+		}
+		sort.Strings(variables)
+		enabled := "off"
+		if variation.FeatureEnabled {
+			enabled = "on"
+		}
+		descriptions = append(descriptions, fmt.Sprintf("%s[%s]{%s}", variation.Key, enabled, strings.Join(variables, ",")))
+	}
+	sort.Strings(descriptions)
+	return strings.Join(descriptions, ";")
+}
+
+// describeRanges renders traffic allocation ranges as "entityId:endOfRange" in bucketing order
+func describeRanges(ranges []entities.Range) string {
+	descriptions := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		descriptions = append(descriptions, r.EntityID+":"+strconv.Itoa(r.EndOfRange))
+	}
+	return strings.Join(descriptions, ",")
+}
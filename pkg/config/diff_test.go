@@ -0,0 +1,143 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package config
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/optimizely/go-sdk/v2/pkg/config/datafileprojectconfig"
+	"github.com/optimizely/go-sdk/v2/pkg/logging"
+	"github.com/optimizely/go-sdk/v2/pkg/notification"
+	"github.com/optimizely/go-sdk/v2/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const diffOldDatafile = `{
+	"version": "4", "revision": "1",
+	"audiences": [{"id": "a1", "name": "adults", "conditions": "[\"or\", {\"name\": \"age\", \"type\": \"custom_attribute\", \"value\": 18}]"},
+		{"id": "a2", "name": "gone", "conditions": "[\"or\"]"}],
+	"experiments": [{
+		"id": "e1", "key": "exp", "layerId": "l1", "status": "Running", "audienceIds": ["a1"],
+		"variations": [{"id": "v1", "key": "control", "featureEnabled": true, "variables": [{"id": "var1", "value": "1"}]}],
+		"trafficAllocation": [{"entityId": "v1", "endOfRange": 5000}]
+	}],
+	"rollouts": [{"id": "r1", "experiments": [{
+		"id": "rule1", "key": "everyone", "layerId": "l2", "status": "Running", "audienceIds": [],
+		"variations": [{"id": "v3", "key": "on", "featureEnabled": true, "variables": []}],
+		"trafficAllocation": [{"entityId": "v3", "endOfRange": 10000}]
+	}]}],
+	"featureFlags": [{
+		"id": "f1", "key": "flag", "rolloutId": "r1", "experimentIds": ["e1"],
+		"variables": [{"id": "var1", "key": "count", "type": "integer", "defaultValue": "1"}]
+	}, {"id": "f2", "key": "old_flag", "rolloutId": "", "experimentIds": [], "variables": []}]
+}`
+
+const diffNewDatafile = `{
+	"version": "4", "revision": "2",
+	"audiences": [{"id": "a1", "name": "adults", "conditions": "[\"or\", {\"name\": \"age\", \"type\": \"custom_attribute\", \"value\": 21}]"}],
+	"experiments": [{
+		"id": "e1", "key": "exp", "layerId": "l1", "status": "Running", "audienceIds": ["a1"],
+		"variations": [{"id": "v1", "key": "control", "featureEnabled": true, "variables": [{"id": "var1", "value": "1"}]}],
+		"trafficAllocation": [{"entityId": "v1", "endOfRange": 10000}]
+	}],
+	"rollouts": [{"id": "r1", "experiments": [{
+		"id": "rule1", "key": "everyone", "layerId": "l2", "status": "Running", "audienceIds": [],
+		"variations": [{"id": "v3", "key": "on", "featureEnabled": false, "variables": []}],
+		"trafficAllocation": [{"entityId": "v3", "endOfRange": 10000}]
+	}]}],
+	"featureFlags": [{
+		"id": "f1", "key": "flag", "rolloutId": "r1", "experimentIds": ["e1"],
+		"variables": [{"id": "var1", "key": "count", "type": "integer", "defaultValue": "2"},
+			{"id": "var2", "key": "label", "type": "string", "defaultValue": "x"}]
+	}, {"id": "f3", "key": "new_flag", "rolloutId": "", "experimentIds": [], "variables": []}]
+}`
+
+func newDiffTestConfig(t *testing.T, datafile string) ProjectConfig {
+	projectConfig, err := datafileprojectconfig.NewDatafileProjectConfig([]byte(datafile), logging.GetLogger("", "DiffTest"))
+	require.NoError(t, err)
+	return projectConfig
+}
+
+func TestDiff(t *testing.T) {
+	diff := Diff(newDiffTestConfig(t, diffOldDatafile), newDiffTestConfig(t, diffNewDatafile))
+
+	assert.Equal(t, "1", diff.OldRevision)
+	assert.Equal(t, "2", diff.NewRevision)
+	assert.Equal(t, []Change{
+		{Kind: EntityFlag, Type: ChangeAdded, Key: "new_flag"},
+		{Kind: EntityFlag, Type: ChangeRemoved, Key: "old_flag"},
+		{Kind: EntityVariable, Type: ChangeModified, Key: "flag/count", Fields: []FieldChange{{Field: "defaultValue", Old: "1", New: "2"}}},
+		{Kind: EntityVariable, Type: ChangeAdded, Key: "flag/label"},
+		{Kind: EntityTrafficAllocation, Type: ChangeModified, Key: "exp", Fields: []FieldChange{{Field: "trafficAllocation", Old: "v1:5000", New: "v1:10000"}}},
+		{Kind: EntityRolloutRule, Type: ChangeModified, Key: "flag/everyone", Fields: []FieldChange{{Field: "variations", Old: "on[on]{}", New: "on[off]{}"}}},
+		{Kind: EntityAudience, Type: ChangeModified, Key: "a1", Fields: []FieldChange{{Field: "conditions",
+			Old: `["or", {"name": "age", "type": "custom_attribute", "value": 18}]`,
+			New: `["or", {"name": "age", "type": "custom_attribute", "value": 21}]`}}},
+		{Kind: EntityAudience, Type: ChangeRemoved, Key: "a2"},
+	}, diff.Changes)
+	assert.Equal(t, `variable flag/count changed (defaultValue: "1" -> "2")`, diff.Changes[2].String())
+}
+
+func TestDiffSameConfig(t *testing.T) {
+	diff := Diff(newDiffTestConfig(t, diffOldDatafile), newDiffTestConfig(t, diffOldDatafile))
+	assert.True(t, diff.IsEmpty())
+}
+
+func TestDiffFromNilConfig(t *testing.T) {
+	diff := Diff(nil, newDiffTestConfig(t, diffOldDatafile))
+	assert.Equal(t, "", diff.OldRevision)
+	assert.Len(t, diff.Changes, 7)
+	for _, change := range diff.Changes {
+		assert.Equal(t, ChangeAdded, change.Type)
+	}
+}
+
+func TestSyncConfigIncludesConfigDiff(t *testing.T) {
+	mockRequester := new(MockRequester)
+	mockRequester.On("Get", []utils.Header(nil)).Return([]byte(diffNewDatafile), http.Header{}, http.StatusOK, nil)
+
+	var notifications []notification.ProjectConfigUpdateNotification
+	callback := func(n notification.ProjectConfigUpdateNotification) {
+		notifications = append(notifications, n)
+	}
+
+	configManager := NewAsyncPollingProjectConfigManager("test_sdk_key_diff", WithRequester(mockRequester),
+		WithInitialDatafile([]byte(diffOldDatafile)), WithConfigDiff())
+	id, _ := configManager.OnProjectConfigUpdate(callback)
+	configManager.SyncConfig()
+	assert.NoError(t, configManager.RemoveOnProjectConfigUpdate(id))
+
+	require.Len(t, notifications, 1)
+	diff, ok := notifications[0].Diff.(*ConfigDiff)
+	require.True(t, ok)
+	assert.Equal(t, "1", diff.OldRevision)
+	assert.Equal(t, "2", diff.NewRevision)
+	assert.Len(t, diff.Changes, 8)
+
+	// the diff is only computed when asked for
+	configManager = NewAsyncPollingProjectConfigManager("test_sdk_key_diff", WithRequester(mockRequester),
+		WithInitialDatafile([]byte(diffOldDatafile)))
+	id, _ = configManager.OnProjectConfigUpdate(callback)
+	configManager.SyncConfig()
+	assert.NoError(t, configManager.RemoveOnProjectConfigUpdate(id))
+
+	require.Len(t, notifications, 2)
+	assert.Nil(t, notifications[1].Diff)
+}
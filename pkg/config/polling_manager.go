@@ -20,12 +20,14 @@ package config
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"net/http"
 	"sync"
 	"time"
 
 	"github.com/optimizely/go-sdk/v2/pkg/config/datafileprojectconfig"
 	"github.com/optimizely/go-sdk/v2/pkg/logging"
+	"github.com/optimizely/go-sdk/v2/pkg/metrics"
 	"github.com/optimizely/go-sdk/v2/pkg/notification"
 	"github.com/optimizely/go-sdk/v2/pkg/registry"
 	"github.com/optimizely/go-sdk/v2/pkg/utils"
@@ -42,32 +44,70 @@ const ModifiedSince = "If-Modified-Since"
 // LastModified header key for response
 const LastModified = "Last-Modified"
 
+// IfNoneMatch header key for request
+const IfNoneMatch = "If-None-Match"
+
+// ETag header key for response
+const ETag = "ETag"
+
 // DatafileURLTemplate is used to construct the endpoint for retrieving regular datafile from the CDN
 const DatafileURLTemplate = "https://cdn.optimizely.com/datafiles/%s.json"
 
 // AuthDatafileURLTemplate is used to construct the endpoint for retrieving authenticated datafile from the CDN
 const AuthDatafileURLTemplate = "https://config.optimizely.com/datafiles/auth/%s.json"
 
+// DefaultRetainedRevisions is the default number of project config revisions retained for rollback
+const DefaultRetainedRevisions = 5
+
 // Err403Forbidden is 403Forbidden specific error
 var Err403Forbidden = errors.New("unable to fetch fresh datafile (consider rechecking SDK key), status code: 403 Forbidden")
 
+// ErrRevisionNotRetained is returned when rolling back to a revision that is no longer retained
+var ErrRevisionNotRetained = errors.New("revision is not retained")
+
 // PollingProjectConfigManager maintains a dynamic copy of the project config by continuously polling for the datafile
 // from the Optimizely CDN at a given (configurable) interval.
 type PollingProjectConfigManager struct {
 	datafileURLTemplate string
 	initDatafile        []byte
 	lastModified        string
+	etag                string
 	notificationCenter  notification.Center
 	pollingInterval     time.Duration
 	requester           utils.Requester
 	sdkKey              string
 	logger              logging.OptimizelyLogProducer
 	datafileAccessToken string
+	datafileStore       DatafileStore
+	datafileSources     []DatafileSource
+	datafileVerifier    DatafileVerifier
+	validateDatafile    bool
+	includeConfigDiff   bool
+	metricsRegistry     metrics.Registry
+	pollCounters        map[notification.ConfigPollOutcome]metrics.Counter
+	pollingJitter       time.Duration
+	maxBackoffInterval  time.Duration
+	forbiddenInterval   time.Duration
+	stopOnForbidden     bool
+	retainedRevisions   int
+
+	applyLock          sync.Mutex
+	configLock         sync.RWMutex
+	err                error
+	projectConfig      ProjectConfig
+	optimizelyConfig   *OptimizelyConfig
+	lastSuccessfulPoll time.Time
+	lastPollOutcome    notification.ConfigPollOutcome
+	datafileSource     string
+	pinned             bool
+	revisionHistory    []retainedConfig // in fetch order
+	latestConfig       retainedConfig   // most recently fetched, applied or not
+}
 
-	configLock       sync.RWMutex
-	err              error
-	projectConfig    ProjectConfig
-	optimizelyConfig *OptimizelyConfig
+// retainedConfig is a project config revision kept for rollback along with the datafile source that served it
+type retainedConfig struct {
+	projectConfig ProjectConfig
+	source        string
 }
 
 // OptionFunc is used to provide custom configuration to the PollingProjectConfigManager.
@@ -87,6 +127,43 @@ func WithDatafileURLTemplate(datafileTemplate string) OptionFunc {
 	}
 }
 
+// WithDatafileSources is an optional function, sets an ordered list of sources to fetch the datafile from.
+// Every poll tries the sources in order and uses the first one that responds successfully.
+func WithDatafileSources(sources ...DatafileSource) OptionFunc {
+	return func(p *PollingProjectConfigManager) {
+		p.datafileSources = sources
+	}
+}
+
+// WithDatafileVerifier is an optional function, sets a verifier every fetched datafile must pass before it is applied
+func WithDatafileVerifier(datafileVerifier DatafileVerifier) OptionFunc {
+	return func(p *PollingProjectConfigManager) {
+		p.datafileVerifier = datafileVerifier
+	}
+}
+
+// WithDatafileValidation is an optional function, validates every fetched datafile and refuses revisions with fatal issues
+func WithDatafileValidation() OptionFunc {
+	return func(p *PollingProjectConfigManager) {
+		p.validateDatafile = true
+	}
+}
+
+// WithConfigDiff is an optional function, includes the Diff from the previous revision in ProjectConfigUpdate notifications
+func WithConfigDiff() OptionFunc {
+	return func(p *PollingProjectConfigManager) {
+		p.includeConfigDiff = true
+	}
+}
+
+// WithRetainedRevisions is an optional function, sets how many of the last fetched revisions are retained for rollback,
+// the served revision is always retained
+func WithRetainedRevisions(retainedRevisions int) OptionFunc {
+	return func(p *PollingProjectConfigManager) {
+		p.retainedRevisions = retainedRevisions
+	}
+}
+
 // WithPollingInterval is an optional function, sets a passed polling interval
 func WithPollingInterval(interval time.Duration) OptionFunc {
 	return func(p *PollingProjectConfigManager) {
@@ -108,57 +185,139 @@ func WithDatafileAccessToken(datafileAccessToken string) OptionFunc {
 	}
 }
 
+// WithDatafileStore is an optional function, sets a store used to persist fetched datafiles across restarts
+func WithDatafileStore(datafileStore DatafileStore) OptionFunc {
+	return func(p *PollingProjectConfigManager) {
+		p.datafileStore = datafileStore
+	}
+}
+
+// WithMetricsRegistry is an optional function, sets a registry used to count datafile poll outcomes
+func WithMetricsRegistry(metricsRegistry metrics.Registry) OptionFunc {
+	return func(p *PollingProjectConfigManager) {
+		p.metricsRegistry = metricsRegistry
+	}
+}
+
+// WithPollingJitter is an optional function, delays the first poll and every backoff by a random duration up to jitter
+func WithPollingJitter(jitter time.Duration) OptionFunc {
+	return func(p *PollingProjectConfigManager) {
+		p.pollingJitter = jitter
+	}
+}
+
+// WithPollingBackoff is an optional function, doubles the polling interval on every consecutive failed poll up to maxInterval
+func WithPollingBackoff(maxInterval time.Duration) OptionFunc {
+	return func(p *PollingProjectConfigManager) {
+		p.maxBackoffInterval = maxInterval
+	}
+}
+
+// WithForbiddenPollingInterval is an optional function, sets the interval used after a poll failed with 403 Forbidden
+func WithForbiddenPollingInterval(interval time.Duration) OptionFunc {
+	return func(p *PollingProjectConfigManager) {
+		p.forbiddenInterval = interval
+	}
+}
+
+// WithStopPollingOnForbidden is an optional function, stops polling once a poll failed with 403 Forbidden
+func WithStopPollingOnForbidden() OptionFunc {
+	return func(p *PollingProjectConfigManager) {
+		p.stopOnForbidden = true
+	}
+}
+
 // SyncConfig downloads datafile and updates projectConfig
 func (cm *PollingProjectConfigManager) SyncConfig() {
 	var e error
 	var code int
 	var respHeaders http.Header
 	var datafile []byte
+	var source DatafileSource
 
-	closeMutex := func(e error) {
-		cm.err = e
-		cm.configLock.Unlock()
-	}
-
-	url := fmt.Sprintf(cm.datafileURLTemplate, cm.sdkKey)
-	if cm.lastModified != "" {
-		lastModifiedHeader := utils.Header{Name: ModifiedSince, Value: cm.lastModified}
-		datafile, respHeaders, code, e = cm.requester.Get(url, lastModifiedHeader)
-	} else {
-		datafile, respHeaders, code, e = cm.requester.Get(url)
+	for i, s := range cm.datafileSources {
+		source = s
+		datafile, respHeaders, code, e = source.Fetch(cm.sdkKey, cm.requester, cm.conditionalHeaders(source)...)
+		if e == nil {
+			break
+		}
+		if i < len(cm.datafileSources)-1 {
+			cm.logger.Warning(fmt.Sprintf("Unable to fetch datafile from %s, failing over to the next source: %s", source.Name(), e))
+		}
 	}
 
 	if e != nil {
 		msg := "unable to fetch fresh datafile"
 		cm.logger.Error(msg, e)
-		cm.configLock.Lock()
 
+		err := errors.New(fmt.Sprintf("%s, reason (http status code): %s", msg, e.Error()))
+		outcome := notification.PollError
 		if code == http.StatusForbidden {
-			closeMutex(Err403Forbidden)
-			return
+			err = Err403Forbidden
+			outcome = notification.PollForbidden
 		}
-
-		closeMutex(errors.New(fmt.Sprintf("%s, reason (http status code): %s", msg, e.Error())))
+		cm.configLock.Lock()
+		cm.err = err
+		cm.configLock.Unlock()
+		cm.pollCompleted(outcome, code, err)
 		return
 	}
 
 	if code == http.StatusNotModified {
 		cm.logger.Debug("The datafile was not modified and won't be downloaded again")
+		cm.pollCompleted(notification.PollNotModified, code, nil)
 		return
 	}
 
-	// Save last-modified date from response header
-	cm.configLock.Lock()
-	lastModified := respHeaders.Get(LastModified)
-	if lastModified != "" {
-		cm.lastModified = lastModified
+	outcome, err := cm.applyDatafile(datafile, respHeaders, source.Name(), false)
+	cm.pollCompleted(outcome, code, err)
+}
+
+// applyDatafile parses the given datafile served by source and sets it as the current config if its revision is new.
+// The datafile is parsed and checked before the config lock is taken since verifying it may fetch its signature.
+// A pushed datafile leaves the polling source and its validators untouched as they only describe polled responses.
+func (cm *PollingProjectConfigManager) applyDatafile(datafile []byte, respHeaders http.Header, source string,
+	pushed bool) (notification.ConfigPollOutcome, error) {
+	// applies are serialized so that a slow check cannot apply a revision over a newer one
+	cm.applyLock.Lock()
+	defer cm.applyLock.Unlock()
+
+	setErr := func(e error) {
+		cm.configLock.Lock()
+		cm.err = e
+		cm.configLock.Unlock()
 	}
 
 	projectConfig, err := datafileprojectconfig.NewDatafileProjectConfig(datafile, logging.GetLogger(cm.sdkKey, "NewDatafileProjectConfig"))
 	if err != nil {
 		cm.logger.Error("failed to create project config", err)
-		closeMutex(errors.New("unable to parse datafile"))
-		return
+		err = errors.New("unable to parse datafile")
+		setErr(err)
+		return notification.PollError, err
+	}
+
+	if err = cm.checkDatafile(projectConfig, datafile, respHeaders); err != nil {
+		cm.logger.Error(fmt.Sprintf("Rejected datafile with revision %s from %s", projectConfig.GetRevision(), source), err)
+		setErr(err)
+		cm.sendDatafileRejectedNotification(projectConfig.GetRevision(), source, err)
+		return notification.PollRejected, err
+	}
+
+	cm.configLock.Lock()
+	// Save validators from response header once the datafile is known to be usable,
+	// validators of another source are meaningless for this one
+	if !pushed {
+		if source != cm.datafileSource {
+			cm.datafileSource = source
+			cm.lastModified = ""
+			cm.etag = ""
+		}
+		if lastModified := respHeaders.Get(LastModified); lastModified != "" {
+			cm.lastModified = lastModified
+		}
+		if etag := respHeaders.Get(ETag); etag != "" {
+			cm.etag = etag
+		}
 	}
 
 	var previousRevision string
@@ -166,16 +325,40 @@ func (cm *PollingProjectConfigManager) SyncConfig() {
 		previousRevision = cm.projectConfig.GetRevision()
 	}
 	if projectConfig.GetRevision() == previousRevision {
-		cm.logger.Debug(fmt.Sprintf("No datafile updates. Current revision number: %s", cm.projectConfig.GetRevision()))
-		closeMutex(nil)
-		return
+		cm.err = nil
+		cm.configLock.Unlock()
+		cm.logger.Debug(fmt.Sprintf("No datafile updates. Current revision number: %s", previousRevision))
+		return notification.PollSameRevision, nil
+	}
+	storedDatafile := StoredDatafile{Datafile: datafile, Source: source, Headers: respHeaders}
+	if !pushed {
+		storedDatafile.LastModified = cm.lastModified
+		storedDatafile.ETag = cm.etag
+	}
+	cm.latestConfig = retainedConfig{projectConfig: projectConfig, source: source}
+	if cm.pinned {
+		cm.retain(cm.latestConfig)
+		cm.err = nil
+		cm.configLock.Unlock()
+		cm.logger.Info(fmt.Sprintf("Revision %s not applied, config is pinned to revision %s", projectConfig.GetRevision(), previousRevision))
+		// the store keeps the latest revision since pinning does not survive a restart
+		cm.storeDatafile(storedDatafile)
+		return notification.PollPinned, nil
 	}
+	previousConfig := cm.projectConfig
 	err = cm.setConfig(projectConfig)
-	closeMutex(err)
 	if err == nil {
-		cm.logger.Debug(fmt.Sprintf("New datafile set with revision: %s. Old revision: %s", projectConfig.GetRevision(), previousRevision))
-		cm.sendConfigUpdateNotification()
+		cm.retain(cm.latestConfig)
+	}
+	cm.err = err
+	cm.configLock.Unlock()
+	if err != nil {
+		return notification.PollError, err
 	}
+	cm.logger.Debug(fmt.Sprintf("New datafile set with revision: %s. Old revision: %s", projectConfig.GetRevision(), previousRevision))
+	cm.storeDatafile(storedDatafile)
+	cm.sendConfigUpdateNotification(previousConfig, projectConfig, source, false)
+	return notification.PollNewRevision, nil
 }
 
 // Start starts the polling
@@ -188,11 +371,18 @@ func (cm *PollingProjectConfigManager) Start(ctx context.Context) {
 		cm.logger.Warning("Polling intervals below 30 seconds are not recommended.")
 	}
 	cm.logger.Debug("Polling Config Manager Initiated")
-	t := time.NewTicker(cm.pollingInterval)
+	failures := 0
+	t := time.NewTimer(cm.pollingInterval + cm.jitter())
 	for {
 		select {
 		case <-t.C:
 			cm.SyncConfig()
+			interval, ok := cm.nextPollingInterval(&failures)
+			if !ok {
+				cm.logger.Warning("Polling Config Manager Stopped: access to the datafile is forbidden")
+				return
+			}
+			t.Reset(interval)
 		case <-ctx.Done():
 			t.Stop()
 			cm.logger.Debug("Polling Config Manager Stopped")
@@ -201,6 +391,89 @@ func (cm *PollingProjectConfigManager) Start(ctx context.Context) {
 	}
 }
 
+// nextPollingInterval returns the delay until the next poll based on the outcome of the last one,
+// and false if polling should stop
+func (cm *PollingProjectConfigManager) nextPollingInterval(failures *int) (time.Duration, bool) {
+	cm.configLock.RLock()
+	outcome := cm.lastPollOutcome
+	cm.configLock.RUnlock()
+
+	switch outcome {
+	case notification.PollForbidden:
+		if cm.stopOnForbidden {
+			return 0, false
+		}
+		if cm.forbiddenInterval > 0 {
+			*failures++
+			return cm.forbiddenInterval + cm.jitter(), true
+		}
+	case notification.PollError:
+	default:
+		*failures = 0
+		return cm.pollingInterval, true
+	}
+
+	*failures++
+	if cm.maxBackoffInterval <= cm.pollingInterval {
+		return cm.pollingInterval, true
+	}
+	interval := cm.pollingInterval
+	for i := 0; i < *failures && interval < cm.maxBackoffInterval; i++ {
+		interval *= 2
+	}
+	if interval > cm.maxBackoffInterval {
+		interval = cm.maxBackoffInterval
+	}
+	return interval + cm.jitter(), true
+}
+
+func (cm *PollingProjectConfigManager) jitter() time.Duration {
+	if cm.pollingJitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(cm.pollingJitter))) // #nosec G404 - jitter does not need a secure source
+}
+
+// checkDatafile returns an error if the fetched datafile must not replace the current config
+func (cm *PollingProjectConfigManager) checkDatafile(projectConfig ProjectConfig, datafile []byte, respHeaders http.Header) error {
+	if cm.datafileVerifier != nil {
+		if err := cm.datafileVerifier.Verify(cm.sdkKey, datafile, respHeaders); err != nil {
+			return fmt.Errorf("datafile verification failed: %w", err)
+		}
+	}
+	if !cm.validateDatafile {
+		return nil
+	}
+
+	var fatalIssues []ValidationIssue
+	for _, issue := range ValidateProjectConfig(projectConfig) {
+		if issue.Severity == SeverityFatal {
+			fatalIssues = append(fatalIssues, issue)
+		} else {
+			cm.logger.Warning(fmt.Sprintf("Datafile revision %s: %s", projectConfig.GetRevision(), issue))
+		}
+	}
+	if len(fatalIssues) > 0 {
+		return &DatafileValidationError{Issues: fatalIssues}
+	}
+	return nil
+}
+
+func (cm *PollingProjectConfigManager) conditionalHeaders(source DatafileSource) (headers []utils.Header) {
+	cm.configLock.RLock()
+	defer cm.configLock.RUnlock()
+	if source.Name() != cm.datafileSource {
+		return nil
+	}
+	if cm.lastModified != "" {
+		headers = append(headers, utils.Header{Name: ModifiedSince, Value: cm.lastModified})
+	}
+	if cm.etag != "" {
+		headers = append(headers, utils.Header{Name: IfNoneMatch, Value: cm.etag})
+	}
+	return headers
+}
+
 func (cm *PollingProjectConfigManager) setAuthHeaderIfDatafileAccessTokenPresent() {
 	if cm.datafileAccessToken != "" {
 		headers := []utils.Header{{Name: utils.HeaderContentType, Value: utils.ContentTypeJSON}, {Name: utils.HeaderAccept, Value: utils.ContentTypeJSON}}
@@ -216,6 +489,7 @@ func newConfigManager(sdkKey string, logger logging.OptimizelyLogProducer, confi
 		requester:          utils.NewHTTPRequester(logging.GetLogger(sdkKey, "HTTPRequester")),
 		sdkKey:             sdkKey,
 		logger:             logger,
+		retainedRevisions:  DefaultRetainedRevisions,
 	}
 
 	for _, opt := range configOptions {
@@ -229,6 +503,25 @@ func newConfigManager(sdkKey string, logger logging.OptimizelyLogProducer, confi
 			pollingProjectConfigManager.datafileURLTemplate = DatafileURLTemplate
 		}
 	}
+	if len(pollingProjectConfigManager.datafileSources) == 0 {
+		pollingProjectConfigManager.datafileSources = []DatafileSource{NewURLTemplateSource(pollingProjectConfigManager.datafileURLTemplate)}
+	}
+	if pollingProjectConfigManager.retainedRevisions < 1 {
+		// the served revision is always retained
+		pollingProjectConfigManager.retainedRevisions = 1
+	}
+	if pollingProjectConfigManager.metricsRegistry == nil {
+		pollingProjectConfigManager.metricsRegistry = metrics.NewNoopRegistry()
+	}
+	pollingProjectConfigManager.pollCounters = map[notification.ConfigPollOutcome]metrics.Counter{
+		notification.PollNotModified:  pollingProjectConfigManager.metricsRegistry.GetCounter(metrics.ConfigPollNotModified),
+		notification.PollNewRevision:  pollingProjectConfigManager.metricsRegistry.GetCounter(metrics.ConfigPollNewRevision),
+		notification.PollSameRevision: pollingProjectConfigManager.metricsRegistry.GetCounter(metrics.ConfigPollSameRevision),
+		notification.PollError:        pollingProjectConfigManager.metricsRegistry.GetCounter(metrics.ConfigPollError),
+		notification.PollForbidden:    pollingProjectConfigManager.metricsRegistry.GetCounter(metrics.ConfigPollForbidden),
+		notification.PollRejected:     pollingProjectConfigManager.metricsRegistry.GetCounter(metrics.ConfigPollRejected),
+		notification.PollPinned:       pollingProjectConfigManager.metricsRegistry.GetCounter(metrics.ConfigPollPinned),
+	}
 	pollingProjectConfigManager.setAuthHeaderIfDatafileAccessTokenPresent()
 	return &pollingProjectConfigManager
 }
@@ -241,6 +534,8 @@ func NewPollingProjectConfigManager(sdkKey string, pollingMangerOptions ...Optio
 	if len(pollingProjectConfigManager.initDatafile) > 0 {
 		pollingProjectConfigManager.setInitialDatafile(pollingProjectConfigManager.initDatafile)
 	} else {
+		// serve the stored datafile if the initial poll fails
+		pollingProjectConfigManager.loadStoredDatafile()
 		pollingProjectConfigManager.SyncConfig() // initial poll
 	}
 	return pollingProjectConfigManager
@@ -252,6 +547,8 @@ func NewAsyncPollingProjectConfigManager(sdkKey string, pollingMangerOptions ...
 	pollingProjectConfigManager := newConfigManager(sdkKey, logging.GetLogger(sdkKey, "PollingProjectConfigManager"), pollingMangerOptions...)
 	if len(pollingProjectConfigManager.initDatafile) > 0 {
 		pollingProjectConfigManager.setInitialDatafile(pollingProjectConfigManager.initDatafile)
+	} else {
+		pollingProjectConfigManager.loadStoredDatafile()
 	}
 	return pollingProjectConfigManager
 }
@@ -278,6 +575,88 @@ func (cm *PollingProjectConfigManager) GetOptimizelyConfig() *OptimizelyConfig {
 	return cm.optimizelyConfig
 }
 
+// Pin freezes the manager on the current revision, newer revisions that are fetched are retained but not applied until Unpin
+func (cm *PollingProjectConfigManager) Pin() error {
+	cm.configLock.Lock()
+	if cm.projectConfig == nil {
+		cm.configLock.Unlock()
+		return errors.New("no project config to pin")
+	}
+	cm.pinned = true
+	projectConfig, source := cm.projectConfig, cm.servedSource()
+	cm.configLock.Unlock()
+
+	cm.logger.Info(fmt.Sprintf("Config pinned to revision %s", projectConfig.GetRevision()))
+	cm.sendConfigUpdateNotification(projectConfig, projectConfig, source, true)
+	return nil
+}
+
+// Rollback serves one of the retained revisions and pins the manager to it
+func (cm *PollingProjectConfigManager) Rollback(revision string) error {
+	cm.configLock.Lock()
+	var target retainedConfig
+	for _, retained := range cm.revisionHistory {
+		if retained.projectConfig.GetRevision() == revision {
+			target = retained
+		}
+	}
+	if target.projectConfig == nil {
+		cm.configLock.Unlock()
+		return fmt.Errorf("%w: %s", ErrRevisionNotRetained, revision)
+	}
+	previousConfig := cm.projectConfig
+	cm.pinned = true
+	err := cm.setConfig(target.projectConfig)
+	cm.configLock.Unlock()
+	if err != nil {
+		return err
+	}
+
+	cm.logger.Info(fmt.Sprintf("Config rolled back to revision %s", revision))
+	cm.sendConfigUpdateNotification(previousConfig, target.projectConfig, target.source, true)
+	return nil
+}
+
+// Unpin resumes updates, serving the most recently fetched revision right away
+func (cm *PollingProjectConfigManager) Unpin() {
+	cm.configLock.Lock()
+	if !cm.pinned {
+		cm.configLock.Unlock()
+		return
+	}
+	cm.pinned = false
+	previousConfig, source := cm.projectConfig, cm.servedSource()
+	if latest := cm.latestConfig; latest.projectConfig != nil && latest.projectConfig != previousConfig {
+		if err := cm.setConfig(latest.projectConfig); err == nil {
+			cm.retain(latest)
+			source = latest.source
+		}
+	}
+	projectConfig := cm.projectConfig
+	cm.configLock.Unlock()
+
+	cm.logger.Info(fmt.Sprintf("Config unpinned at revision %s", projectConfig.GetRevision()))
+	cm.sendConfigUpdateNotification(previousConfig, projectConfig, source, false)
+}
+
+// IsPinned returns whether the manager is pinned to the revision it serves
+func (cm *PollingProjectConfigManager) IsPinned() bool {
+	cm.configLock.RLock()
+	defer cm.configLock.RUnlock()
+	return cm.pinned
+}
+
+// RetainedRevisions returns the revisions available for rollback, in the order they were fetched
+func (cm *PollingProjectConfigManager) RetainedRevisions() []string {
+	cm.configLock.RLock()
+	defer cm.configLock.RUnlock()
+	revisions := make([]string, 0, len(cm.revisionHistory))
+	for _, retained := range cm.revisionHistory {
+		revisions = append(revisions, retained.projectConfig.GetRevision())
+	}
+	return revisions
+}
+
 // OnProjectConfigUpdate registers a handler for ProjectConfigUpdate notifications
 func (cm *PollingProjectConfigManager) OnProjectConfigUpdate(callback func(notification.ProjectConfigUpdateNotification)) (int, error) {
 	handler := func(payload interface{}) {
@@ -304,6 +683,58 @@ func (cm *PollingProjectConfigManager) RemoveOnProjectConfigUpdate(id int) error
 	return nil
 }
 
+// OnConfigPoll registers a handler for ConfigPoll notifications
+func (cm *PollingProjectConfigManager) OnConfigPoll(callback func(notification.ConfigPollNotification)) (int, error) {
+	handler := func(payload interface{}) {
+		if configPollNotification, ok := payload.(notification.ConfigPollNotification); ok {
+			callback(configPollNotification)
+		} else {
+			cm.logger.Warning(fmt.Sprintf("Unable to convert notification payload %v into ConfigPollNotification", payload))
+		}
+	}
+	id, err := cm.notificationCenter.AddHandler(notification.ConfigPoll, handler)
+	if err != nil {
+		cm.logger.Warning("Problem with adding notification handler")
+		return 0, err
+	}
+	return id, nil
+}
+
+// OnDatafileRejected registers a handler for DatafileRejected notifications
+func (cm *PollingProjectConfigManager) OnDatafileRejected(callback func(notification.DatafileRejectedNotification)) (int, error) {
+	handler := func(payload interface{}) {
+		if datafileRejectedNotification, ok := payload.(notification.DatafileRejectedNotification); ok {
+			callback(datafileRejectedNotification)
+		} else {
+			cm.logger.Warning(fmt.Sprintf("Unable to convert notification payload %v into DatafileRejectedNotification", payload))
+		}
+	}
+	id, err := cm.notificationCenter.AddHandler(notification.DatafileRejected, handler)
+	if err != nil {
+		cm.logger.Warning("Problem with adding notification handler")
+		return 0, err
+	}
+	return id, nil
+}
+
+// RemoveOnDatafileRejected removes handler for DatafileRejected notification with given id
+func (cm *PollingProjectConfigManager) RemoveOnDatafileRejected(id int) error {
+	if err := cm.notificationCenter.RemoveHandler(id, notification.DatafileRejected); err != nil {
+		cm.logger.Warning("Problem with removing notification handler")
+		return err
+	}
+	return nil
+}
+
+// RemoveOnConfigPoll removes handler for ConfigPoll notification with given id
+func (cm *PollingProjectConfigManager) RemoveOnConfigPoll(id int) error {
+	if err := cm.notificationCenter.RemoveHandler(id, notification.ConfigPoll); err != nil {
+		cm.logger.Warning("Problem with removing notification handler")
+		return err
+	}
+	return nil
+}
+
 func (cm *PollingProjectConfigManager) setConfig(projectConfig ProjectConfig) error {
 	if projectConfig == nil {
 		return errors.New("unable to set nil config")
@@ -315,26 +746,170 @@ func (cm *PollingProjectConfigManager) setConfig(projectConfig ProjectConfig) er
 	return nil
 }
 
+// retain appends the given revision to the history unless it is already retained, then drops the oldest revisions
+// beyond the retained count, except for the served one, must be called with the config lock held
+func (cm *PollingProjectConfigManager) retain(config retainedConfig) {
+	for _, retained := range cm.revisionHistory {
+		if retained.projectConfig.GetRevision() == config.projectConfig.GetRevision() {
+			return
+		}
+	}
+	history := append(cm.revisionHistory, config)
+	for len(history) > cm.retainedRevisions {
+		oldest := 0
+		if cm.isServed(history[oldest]) {
+			oldest++
+		}
+		history = append(history[:oldest:oldest], history[oldest+1:]...)
+	}
+	cm.revisionHistory = history
+}
+
+// servedSource returns the datafile source of the served revision, must be called with the config lock held
+func (cm *PollingProjectConfigManager) servedSource() string {
+	for _, retained := range cm.revisionHistory {
+		if cm.isServed(retained) {
+			return retained.source
+		}
+	}
+	return cm.datafileSource
+}
+
+// isServed returns whether the given revision is the served one, must be called with the config lock held
+func (cm *PollingProjectConfigManager) isServed(config retainedConfig) bool {
+	return cm.projectConfig != nil && config.projectConfig.GetRevision() == cm.projectConfig.GetRevision()
+}
+
 func (cm *PollingProjectConfigManager) setInitialDatafile(datafile []byte) {
 	if len(datafile) != 0 {
 		cm.configLock.Lock()
 		defer cm.configLock.Unlock()
 		projectConfig, err := datafileprojectconfig.NewDatafileProjectConfig(datafile, logging.GetLogger(cm.sdkKey, "DatafileProjectConfig"))
 		if projectConfig != nil {
-			err = cm.setConfig(projectConfig)
+			if err = cm.setConfig(projectConfig); err == nil {
+				cm.retain(retainedConfig{projectConfig: projectConfig})
+			}
 		}
 		cm.err = err
 	}
 }
 
-func (cm *PollingProjectConfigManager) sendConfigUpdateNotification() {
+func (cm *PollingProjectConfigManager) loadStoredDatafile() {
+	if cm.datafileStore == nil {
+		return
+	}
+	storedDatafile, err := cm.datafileStore.Load(cm.sdkKey)
+	if err != nil {
+		cm.logger.Warning(fmt.Sprintf("Unable to load stored datafile: %s", err))
+		return
+	}
+	if storedDatafile == nil {
+		return
+	}
+	projectConfig, err := datafileprojectconfig.NewDatafileProjectConfig(storedDatafile.Datafile, logging.GetLogger(cm.sdkKey, "DatafileProjectConfig"))
+	if err != nil {
+		cm.logger.Warning(fmt.Sprintf("Unable to parse stored datafile: %s", err))
+		return
+	}
+	// the store may have been tampered with, a rejected datafile is neither served nor are its validators reused
+	if err = cm.checkDatafile(projectConfig, storedDatafile.Datafile, storedDatafile.Headers); err != nil {
+		cm.logger.Warning(fmt.Sprintf("Rejected stored datafile with revision %s: %s", projectConfig.GetRevision(), err))
+		cm.sendDatafileRejectedNotification(projectConfig.GetRevision(), storedDatafile.Source, err)
+		return
+	}
+
+	cm.configLock.Lock()
+	defer cm.configLock.Unlock()
+	if cm.err = cm.setConfig(projectConfig); cm.err == nil {
+		// only reuse validators when the matching revision is being served
+		cm.lastModified = storedDatafile.LastModified
+		cm.etag = storedDatafile.ETag
+		cm.datafileSource = storedDatafile.Source
+		cm.retain(retainedConfig{projectConfig: projectConfig, source: storedDatafile.Source})
+		cm.logger.Debug(fmt.Sprintf("Stored datafile set with revision: %s", projectConfig.GetRevision()))
+	}
+}
+
+func (cm *PollingProjectConfigManager) storeDatafile(storedDatafile StoredDatafile) {
+	if cm.datafileStore == nil {
+		return
+	}
+	if err := cm.datafileStore.Save(cm.sdkKey, storedDatafile); err != nil {
+		cm.logger.Warning(fmt.Sprintf("Unable to store datafile: %s", err))
+	}
+}
+
+func (cm *PollingProjectConfigManager) sendConfigUpdateNotification(previousConfig, projectConfig ProjectConfig, source string, pinned bool) {
 	if cm.notificationCenter != nil {
 		projectConfigUpdateNotification := notification.ProjectConfigUpdateNotification{
 			Type:     notification.ProjectConfigUpdate,
-			Revision: cm.projectConfig.GetRevision(),
+			Revision: projectConfig.GetRevision(),
+			Source:   source,
+			Pinned:   pinned,
+		}
+		if cm.includeConfigDiff {
+			projectConfigUpdateNotification.Diff = Diff(previousConfig, projectConfig)
 		}
 		if err := cm.notificationCenter.Send(notification.ProjectConfigUpdate, projectConfigUpdateNotification); err != nil {
 			cm.logger.Warning("Problem with sending notification")
 		}
 	}
 }
+
+// pollCompleted counts the outcome of a poll and notifies it
+func (cm *PollingProjectConfigManager) pollCompleted(outcome notification.ConfigPollOutcome, code int, err error) {
+	cm.updateCompleted("", outcome, code, err)
+}
+
+// pushCompleted records the outcome of a datafile pushed by source the same way as the outcome of a poll
+func (cm *PollingProjectConfigManager) pushCompleted(source string, outcome notification.ConfigPollOutcome, err error) {
+	cm.updateCompleted(source, outcome, http.StatusOK, err)
+}
+
+// updateCompleted counts the outcome of an update and notifies it, an empty source stands for the polled source
+func (cm *PollingProjectConfigManager) updateCompleted(source string, outcome notification.ConfigPollOutcome, code int, err error) {
+	if counter, ok := cm.pollCounters[outcome]; ok {
+		counter.Add(1)
+	}
+
+	cm.configLock.Lock()
+	if err == nil {
+		cm.lastSuccessfulPoll = time.Now()
+	}
+	cm.lastPollOutcome = outcome
+	if source == "" {
+		source = cm.datafileSource
+	}
+	configPollNotification := notification.ConfigPollNotification{
+		Type:               notification.ConfigPoll,
+		Outcome:            outcome,
+		StatusCode:         code,
+		Err:                err,
+		LastSuccessfulPoll: cm.lastSuccessfulPoll,
+		Source:             source,
+	}
+	if cm.projectConfig != nil {
+		configPollNotification.Revision = cm.projectConfig.GetRevision()
+	}
+	cm.configLock.Unlock()
+
+	if cm.notificationCenter != nil {
+		if e := cm.notificationCenter.Send(notification.ConfigPoll, configPollNotification); e != nil {
+			cm.logger.Debug(fmt.Sprintf("Unable to send config poll notification: %s", e))
+		}
+	}
+}
+
+func (cm *PollingProjectConfigManager) sendDatafileRejectedNotification(revision, source string, reason error) {
+	if cm.notificationCenter != nil {
+		datafileRejectedNotification := notification.DatafileRejectedNotification{
+			Type:     notification.DatafileRejected,
+			Revision: revision,
+			Source:   source,
+			Reason:   reason,
+		}
+		if err := cm.notificationCenter.Send(notification.DatafileRejected, datafileRejectedNotification); err != nil {
+			cm.logger.Warning("Problem with sending notification")
+		}
+	}
+}
@@ -18,6 +18,7 @@ package config
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"sync/atomic"
 	"testing"
@@ -25,6 +26,7 @@ import (
 
 	"github.com/optimizely/go-sdk/v2/pkg/config/datafileprojectconfig"
 	"github.com/optimizely/go-sdk/v2/pkg/logging"
+	"github.com/optimizely/go-sdk/v2/pkg/metrics"
 	"github.com/optimizely/go-sdk/v2/pkg/notification"
 	"github.com/optimizely/go-sdk/v2/pkg/utils"
 
@@ -681,3 +683,498 @@ func TestSetDatafileAccessTokenRequest(t *testing.T) {
 	assert.NotEqual(t, configManagerRequester, configManager.requester)
 	assert.NotEqual(t, asyncConfigManagerRequester, asyncConfigManager.requester)
 }
+
+func TestNewPollingProjectConfigManagerWithDatafileStore(t *testing.T) {
+	mockDatafile1 := []byte(`{"revision":"42","version": "4"}`)
+	mockDatafile2 := []byte(`{"revision":"43","version": "4"}`)
+	modifiedDate := "Wed, 16 Oct 2019 20:16:45 GMT"
+	responseHeaders := http.Header{}
+	responseHeaders.Set(LastModified, modifiedDate)
+	store := NewFileDatafileStore(t.TempDir())
+	sdkKey := "test_sdk_key"
+
+	// A fetched revision is persisted together with its last-modified value
+	mockRequester := new(MockRequester)
+	mockRequester.On("Get", []utils.Header(nil)).Return(mockDatafile1, responseHeaders, http.StatusOK, nil)
+	configManager := NewPollingProjectConfigManager(sdkKey, WithRequester(mockRequester), WithDatafileStore(store))
+	mockRequester.AssertExpectations(t)
+
+	storedDatafile, err := store.Load(sdkKey)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(mockDatafile1), string(storedDatafile.Datafile))
+	assert.Equal(t, modifiedDate, storedDatafile.LastModified)
+
+	// A new revision replaces the stored one
+	mockRequester = new(MockRequester)
+	mockRequester.On("Get", []utils.Header{{Name: ModifiedSince, Value: modifiedDate}}).Return(mockDatafile2, http.Header{}, http.StatusOK, nil)
+	configManager.requester = mockRequester
+	configManager.SyncConfig()
+	mockRequester.AssertExpectations(t)
+
+	storedDatafile, err = store.Load(sdkKey)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(mockDatafile2), string(storedDatafile.Datafile))
+	assert.Equal(t, modifiedDate, storedDatafile.LastModified)
+}
+
+func TestNewPollingProjectConfigManagerRestoresFromDatafileStore(t *testing.T) {
+	mockDatafile := []byte(`{"revision":"42","version": "4"}`)
+	modifiedDate := "Wed, 16 Oct 2019 20:16:45 GMT"
+	store := NewFileDatafileStore(t.TempDir())
+	sdkKey := "test_sdk_key"
+	assert.NoError(t, store.Save(sdkKey, StoredDatafile{Datafile: mockDatafile, LastModified: modifiedDate, Source: DatafileURLTemplate}))
+
+	// The stored revision is served when the initial poll fails
+	mockRequester := new(MockRequester)
+	mockRequester.On("Get", []utils.Header{{Name: ModifiedSince, Value: modifiedDate}}).Return([]byte{}, http.Header{}, http.StatusServiceUnavailable, errors.New("503 Service Unavailable"))
+	configManager := NewPollingProjectConfigManager(sdkKey, WithRequester(mockRequester), WithDatafileStore(store))
+	mockRequester.AssertExpectations(t)
+
+	actual, err := configManager.GetConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "42", actual.GetRevision())
+}
+
+func TestNewAsyncPollingProjectConfigManagerRestoresFromDatafileStore(t *testing.T) {
+	mockDatafile := []byte(`{"revision":"42","version": "4"}`)
+	store := NewFileDatafileStore(t.TempDir())
+	sdkKey := "test_sdk_key"
+	assert.NoError(t, store.Save(sdkKey, StoredDatafile{Datafile: mockDatafile}))
+
+	mockRequester := new(MockRequester)
+	asyncConfigManager := NewAsyncPollingProjectConfigManager(sdkKey, WithRequester(mockRequester), WithDatafileStore(store))
+	mockRequester.AssertNotCalled(t, "Get", []utils.Header(nil))
+
+	actual, err := asyncConfigManager.GetConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "42", actual.GetRevision())
+}
+
+func TestInitialDatafileTakesPrecedenceOverDatafileStore(t *testing.T) {
+	store := NewFileDatafileStore(t.TempDir())
+	sdkKey := "test_sdk_key"
+	assert.NoError(t, store.Save(sdkKey, StoredDatafile{Datafile: []byte(`{"revision":"41","version": "4"}`)}))
+
+	asyncConfigManager := NewAsyncPollingProjectConfigManager(sdkKey, WithInitialDatafile([]byte(`{"revision":"42","version": "4"}`)), WithDatafileStore(store))
+
+	actual, err := asyncConfigManager.GetConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "42", actual.GetRevision())
+}
+
+type countingRegistry struct {
+	counters map[string]*countingCounter
+}
+
+type countingCounter struct {
+	value float64
+}
+
+func (c *countingCounter) Add(delta float64) {
+	c.value += delta
+}
+
+func newCountingRegistry() *countingRegistry {
+	return &countingRegistry{counters: map[string]*countingCounter{}}
+}
+
+func (r *countingRegistry) GetCounter(key string) metrics.Counter {
+	if _, ok := r.counters[key]; !ok {
+		r.counters[key] = &countingCounter{}
+	}
+	return r.counters[key]
+}
+
+func (r *countingRegistry) GetGauge(key string) metrics.Gauge {
+	return &metrics.NoopGauge{}
+}
+
+func (r *countingRegistry) count(key string) float64 {
+	if counter, ok := r.counters[key]; ok {
+		return counter.value
+	}
+	return 0
+}
+
+func TestSyncConfigWithETag(t *testing.T) {
+	mockDatafile := []byte(`{"revision":"42","version": "4"}`)
+	etag := `"5d8a7b1c"`
+	responseHeaders := http.Header{}
+	responseHeaders.Set(ETag, etag)
+
+	mockRequester := new(MockRequester)
+	mockRequester.On("Get", []utils.Header(nil)).Return(mockDatafile, responseHeaders, http.StatusOK, nil).Times(1)
+	mockRequester.On("Get", []utils.Header{{Name: IfNoneMatch, Value: etag}}).Return([]byte{}, responseHeaders, http.StatusNotModified, nil).Times(1)
+
+	sdkKey := "test_sdk_key"
+	configManager := NewAsyncPollingProjectConfigManager(sdkKey, WithRequester(mockRequester))
+	configManager.SyncConfig()
+	configManager.SyncConfig()
+	mockRequester.AssertExpectations(t)
+
+	actual, err := configManager.GetConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "42", actual.GetRevision())
+}
+
+func TestSyncConfigWithETagAndLastModified(t *testing.T) {
+	mockDatafile := []byte(`{"revision":"42","version": "4"}`)
+	etag := `W/"5d8a7b1c"`
+	modifiedDate := "Wed, 16 Oct 2019 20:16:45 GMT"
+	responseHeaders := http.Header{}
+	responseHeaders.Set(ETag, etag)
+	responseHeaders.Set(LastModified, modifiedDate)
+
+	mockRequester := new(MockRequester)
+	mockRequester.On("Get", []utils.Header(nil)).Return(mockDatafile, responseHeaders, http.StatusOK, nil).Times(1)
+	mockRequester.On("Get", []utils.Header{{Name: ModifiedSince, Value: modifiedDate}, {Name: IfNoneMatch, Value: etag}}).
+		Return([]byte{}, responseHeaders, http.StatusNotModified, nil).Times(1)
+
+	sdkKey := "test_sdk_key"
+	store := NewFileDatafileStore(t.TempDir())
+	configManager := NewAsyncPollingProjectConfigManager(sdkKey, WithRequester(mockRequester), WithDatafileStore(store))
+	configManager.SyncConfig()
+	configManager.SyncConfig()
+	mockRequester.AssertExpectations(t)
+
+	storedDatafile, err := store.Load(sdkKey)
+	assert.NoError(t, err)
+	assert.Equal(t, etag, storedDatafile.ETag)
+	assert.Equal(t, modifiedDate, storedDatafile.LastModified)
+}
+
+func TestSyncConfigIgnoresValidatorsOfUnparsableDatafile(t *testing.T) {
+	responseHeaders := http.Header{}
+	responseHeaders.Set(ETag, `"5d8a7b1c"`)
+
+	mockRequester := new(MockRequester)
+	mockRequester.On("Get", []utils.Header(nil)).Return([]byte("NOT-VALID"), responseHeaders, http.StatusOK, nil).Times(2)
+
+	configManager := NewAsyncPollingProjectConfigManager("test_sdk_key", WithRequester(mockRequester))
+	configManager.SyncConfig()
+	configManager.SyncConfig()
+	mockRequester.AssertExpectations(t)
+}
+
+func TestSyncConfigPollOutcomes(t *testing.T) {
+	mockDatafile1 := []byte(`{"revision":"42","version": "4"}`)
+	mockDatafile2 := []byte(`{"revision":"43","version": "4"}`)
+	registry := newCountingRegistry()
+	mockRequester := new(MockRequester)
+
+	sdkKey := "test_sdk_key_poll_outcomes"
+	configManager := NewAsyncPollingProjectConfigManager(sdkKey, WithRequester(mockRequester), WithMetricsRegistry(registry))
+
+	var outcomes []notification.ConfigPollNotification
+	id, err := configManager.OnConfigPoll(func(n notification.ConfigPollNotification) {
+		outcomes = append(outcomes, n)
+	})
+	assert.NoError(t, err)
+
+	mockRequester.On("Get", []utils.Header(nil)).Return(mockDatafile1, http.Header{}, http.StatusOK, nil).Times(2)
+	configManager.SyncConfig()
+	configManager.SyncConfig()
+
+	mockRequester.On("Get", []utils.Header(nil)).Return([]byte{}, http.Header{}, http.StatusInternalServerError, errors.New("500 Internal Server Error")).Times(1)
+	configManager.SyncConfig()
+
+	mockRequester.On("Get", []utils.Header(nil)).Return([]byte{}, http.Header{}, http.StatusForbidden, errors.New("403 Forbidden")).Times(1)
+	configManager.SyncConfig()
+
+	mockRequester.On("Get", []utils.Header(nil)).Return(mockDatafile2, http.Header{}, http.StatusOK, nil).Times(1)
+	configManager.SyncConfig()
+	mockRequester.AssertExpectations(t)
+
+	assert.Equal(t, float64(2), registry.count(metrics.ConfigPollNewRevision))
+	assert.Equal(t, float64(1), registry.count(metrics.ConfigPollSameRevision))
+	assert.Equal(t, float64(1), registry.count(metrics.ConfigPollError))
+	assert.Equal(t, float64(1), registry.count(metrics.ConfigPollForbidden))
+	assert.Equal(t, float64(0), registry.count(metrics.ConfigPollNotModified))
+
+	assert.Len(t, outcomes, 5)
+	assert.Equal(t, notification.PollNewRevision, outcomes[0].Outcome)
+	assert.Equal(t, "42", outcomes[0].Revision)
+	assert.False(t, outcomes[0].LastSuccessfulPoll.IsZero())
+	assert.Equal(t, notification.PollSameRevision, outcomes[1].Outcome)
+	assert.Equal(t, notification.PollError, outcomes[2].Outcome)
+	assert.Equal(t, http.StatusInternalServerError, outcomes[2].StatusCode)
+	assert.Error(t, outcomes[2].Err)
+	assert.Equal(t, outcomes[1].LastSuccessfulPoll, outcomes[2].LastSuccessfulPoll)
+	assert.Equal(t, "42", outcomes[2].Revision)
+	assert.Equal(t, notification.PollForbidden, outcomes[3].Outcome)
+	assert.Equal(t, Err403Forbidden, outcomes[3].Err)
+	assert.Equal(t, notification.PollNewRevision, outcomes[4].Outcome)
+	assert.Equal(t, "43", outcomes[4].Revision)
+
+	assert.NoError(t, configManager.RemoveOnConfigPoll(id))
+}
+
+func TestNextPollingIntervalBacksOffOnFailures(t *testing.T) {
+	configManager := NewAsyncPollingProjectConfigManager("test_sdk_key", WithPollingInterval(time.Minute), WithPollingBackoff(5*time.Minute))
+	failures := 0
+
+	configManager.lastPollOutcome = notification.PollError
+	interval, ok := configManager.nextPollingInterval(&failures)
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Minute, interval)
+	interval, _ = configManager.nextPollingInterval(&failures)
+	assert.Equal(t, 4*time.Minute, interval)
+	interval, _ = configManager.nextPollingInterval(&failures)
+	assert.Equal(t, 5*time.Minute, interval)
+	interval, _ = configManager.nextPollingInterval(&failures)
+	assert.Equal(t, 5*time.Minute, interval)
+
+	// A successful poll resets the backoff
+	configManager.lastPollOutcome = notification.PollNotModified
+	interval, _ = configManager.nextPollingInterval(&failures)
+	assert.Equal(t, time.Minute, interval)
+	assert.Equal(t, 0, failures)
+}
+
+func TestNextPollingIntervalWithoutBackoff(t *testing.T) {
+	configManager := NewAsyncPollingProjectConfigManager("test_sdk_key", WithPollingInterval(time.Minute))
+	failures := 0
+
+	configManager.lastPollOutcome = notification.PollError
+	interval, ok := configManager.nextPollingInterval(&failures)
+	assert.True(t, ok)
+	assert.Equal(t, time.Minute, interval)
+	configManager.lastPollOutcome = notification.PollForbidden
+	interval, ok = configManager.nextPollingInterval(&failures)
+	assert.True(t, ok)
+	assert.Equal(t, time.Minute, interval)
+}
+
+func TestNextPollingIntervalOnForbidden(t *testing.T) {
+	configManager := NewAsyncPollingProjectConfigManager("test_sdk_key", WithPollingInterval(time.Minute), WithForbiddenPollingInterval(time.Hour))
+	failures := 0
+
+	configManager.lastPollOutcome = notification.PollForbidden
+	interval, ok := configManager.nextPollingInterval(&failures)
+	assert.True(t, ok)
+	assert.Equal(t, time.Hour, interval)
+
+	configManager = NewAsyncPollingProjectConfigManager("test_sdk_key", WithStopPollingOnForbidden())
+	configManager.lastPollOutcome = notification.PollForbidden
+	_, ok = configManager.nextPollingInterval(&failures)
+	assert.False(t, ok)
+}
+
+func TestNextPollingIntervalWithJitter(t *testing.T) {
+	configManager := NewAsyncPollingProjectConfigManager("test_sdk_key", WithPollingInterval(time.Minute),
+		WithPollingBackoff(10*time.Minute), WithPollingJitter(10*time.Second))
+	failures := 0
+
+	configManager.lastPollOutcome = notification.PollError
+	for i := 0; i < 10; i++ {
+		failures = 0
+		interval, _ := configManager.nextPollingInterval(&failures)
+		assert.GreaterOrEqual(t, interval, 2*time.Minute)
+		assert.Less(t, interval, 2*time.Minute+10*time.Second)
+
+		jitter := configManager.jitter()
+		assert.GreaterOrEqual(t, jitter, time.Duration(0))
+		assert.Less(t, jitter, 10*time.Second)
+	}
+}
+
+func TestStartStopsPollingOnForbidden(t *testing.T) {
+	mockRequester := new(MockRequester)
+	mockRequester.On("Get", []utils.Header(nil)).Return([]byte{}, http.Header{}, http.StatusForbidden, errors.New("403 Forbidden")).Times(1)
+
+	configManager := NewAsyncPollingProjectConfigManager("test_sdk_key", WithRequester(mockRequester),
+		WithPollingInterval(10*time.Millisecond), WithStopPollingOnForbidden())
+
+	done := make(chan struct{})
+	go func() {
+		configManager.Start(context.Background())
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("polling did not stop after 403 Forbidden")
+	}
+	mockRequester.AssertExpectations(t)
+	_, err := configManager.GetConfig()
+	assert.Equal(t, Err403Forbidden, err)
+}
+
+func TestSyncConfigFailsOverBetweenDatafileSources(t *testing.T) {
+	mockDatafile1 := []byte(`{"revision":"42","version": "4"}`)
+	mockDatafile2 := []byte(`{"revision":"43","version": "4"}`)
+	modifiedDate := "Wed, 16 Oct 2019 20:16:45 GMT"
+	responseHeaders := http.Header{}
+	responseHeaders.Set(LastModified, modifiedDate)
+	mirrorDatafile := mockDatafile2
+	mirror := NewFetcherSource("mirror", func(sdkKey string) ([]byte, error) {
+		return mirrorDatafile, nil
+	})
+
+	mockRequester := new(MockRequester)
+	mockRequester.On("Get", []utils.Header(nil)).Return(mockDatafile1, responseHeaders, http.StatusOK, nil).Times(1)
+
+	sdkKey := "test_sdk_key_sources"
+	configManager := NewAsyncPollingProjectConfigManager(sdkKey, WithRequester(mockRequester),
+		WithDatafileSources(NewURLTemplateSource(DatafileURLTemplate), mirror))
+	var updates []notification.ProjectConfigUpdateNotification
+	id, _ := configManager.OnProjectConfigUpdate(func(n notification.ProjectConfigUpdateNotification) {
+		updates = append(updates, n)
+	})
+
+	// Primary source serves the first revision
+	configManager.SyncConfig()
+	actual, _ := configManager.GetConfig()
+	assert.Equal(t, "42", actual.GetRevision())
+
+	// Primary fails, the mirror serves the next revision
+	mockRequester.On("Get", []utils.Header{{Name: ModifiedSince, Value: modifiedDate}}).
+		Return([]byte{}, http.Header{}, http.StatusServiceUnavailable, errors.New("503 Service Unavailable")).Times(1)
+	configManager.SyncConfig()
+	actual, err := configManager.GetConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "43", actual.GetRevision())
+
+	// Validators of the mirror are not sent to the primary
+	mockRequester.On("Get", []utils.Header(nil)).Return(mockDatafile2, responseHeaders, http.StatusOK, nil).Times(1)
+	configManager.SyncConfig()
+	mockRequester.AssertExpectations(t)
+
+	assert.Len(t, updates, 2)
+	assert.Equal(t, DatafileURLTemplate, updates[0].Source)
+	assert.Equal(t, "mirror", updates[1].Source)
+	assert.Equal(t, DatafileURLTemplate, configManager.datafileSource)
+	assert.NoError(t, configManager.RemoveOnProjectConfigUpdate(id))
+}
+
+func TestSyncConfigAllDatafileSourcesFail(t *testing.T) {
+	failing := func(name string) DatafileSource {
+		return NewFetcherSource(name, func(sdkKey string) ([]byte, error) {
+			return nil, errors.New(name + " unavailable")
+		})
+	}
+	configManager := NewAsyncPollingProjectConfigManager("test_sdk_key", WithDatafileSources(failing("primary"), failing("secondary")))
+	configManager.SyncConfig()
+
+	_, err := configManager.GetConfig()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "secondary unavailable")
+}
+
+func TestPinIgnoresNewerRevisionsUntilUnpin(t *testing.T) {
+	mockDatafile1 := []byte(`{"revision":"42","version": "4"}`)
+	mockDatafile2 := []byte(`{"revision":"43","version": "4"}`)
+	mockRequester := new(MockRequester)
+	mockRequester.On("Get", []utils.Header(nil)).Return(mockDatafile2, http.Header{}, http.StatusOK, nil)
+
+	configManager := NewAsyncPollingProjectConfigManager("test_sdk_key_pin", WithRequester(mockRequester), WithInitialDatafile(mockDatafile1))
+	var notifications []notification.ProjectConfigUpdateNotification
+	id, _ := configManager.OnProjectConfigUpdate(func(n notification.ProjectConfigUpdateNotification) {
+		notifications = append(notifications, n)
+	})
+	var outcome notification.ConfigPollOutcome
+	pollID, _ := configManager.OnConfigPoll(func(n notification.ConfigPollNotification) {
+		outcome = n.Outcome
+	})
+
+	assert.NoError(t, configManager.Pin())
+	assert.True(t, configManager.IsPinned())
+	configManager.SyncConfig()
+	assert.Equal(t, notification.PollPinned, outcome)
+	actual, _ := configManager.GetConfig()
+	assert.Equal(t, "42", actual.GetRevision())
+	assert.Equal(t, []string{"42", "43"}, configManager.RetainedRevisions())
+
+	configManager.Unpin()
+	assert.False(t, configManager.IsPinned())
+	actual, _ = configManager.GetConfig()
+	assert.Equal(t, "43", actual.GetRevision())
+
+	assert.Equal(t, []notification.ProjectConfigUpdateNotification{
+		{Type: notification.ProjectConfigUpdate, Revision: "42", Pinned: true},
+		{Type: notification.ProjectConfigUpdate, Revision: "43", Source: DatafileURLTemplate, Pinned: false},
+	}, notifications)
+	assert.NoError(t, configManager.RemoveOnProjectConfigUpdate(id))
+	assert.NoError(t, configManager.RemoveOnConfigPoll(pollID))
+}
+
+func TestRollbackToRetainedRevision(t *testing.T) {
+	mockRequester := new(MockRequester)
+	mockRequester.On("Get", []utils.Header(nil)).Return([]byte(`{"revision":"43","version": "4"}`), http.Header{}, http.StatusOK, nil).Once()
+	mockRequester.On("Get", []utils.Header(nil)).Return([]byte(`{"revision":"44","version": "4"}`), http.Header{}, http.StatusOK, nil)
+
+	configManager := NewAsyncPollingProjectConfigManager("test_sdk_key_rollback", WithRequester(mockRequester),
+		WithInitialDatafile([]byte(`{"revision":"42","version": "4"}`)), WithRetainedRevisions(2))
+	configManager.SyncConfig()
+	configManager.SyncConfig()
+	assert.Equal(t, []string{"43", "44"}, configManager.RetainedRevisions())
+
+	err := configManager.Rollback("42")
+	assert.True(t, errors.Is(err, ErrRevisionNotRetained))
+
+	var notifications []notification.ProjectConfigUpdateNotification
+	id, _ := configManager.OnProjectConfigUpdate(func(n notification.ProjectConfigUpdateNotification) {
+		notifications = append(notifications, n)
+	})
+	assert.NoError(t, configManager.Rollback("43"))
+	assert.True(t, configManager.IsPinned())
+	actual, _ := configManager.GetConfig()
+	assert.Equal(t, "43", actual.GetRevision())
+	assert.Equal(t, []string{"43", "44"}, configManager.RetainedRevisions())
+
+	// polling does not move a rolled back config forward
+	configManager.SyncConfig()
+	actual, _ = configManager.GetConfig()
+	assert.Equal(t, "43", actual.GetRevision())
+
+	configManager.Unpin()
+	actual, _ = configManager.GetConfig()
+	assert.Equal(t, "44", actual.GetRevision())
+
+	assert.Len(t, notifications, 2)
+	assert.Equal(t, "43", notifications[0].Revision)
+	assert.True(t, notifications[0].Pinned)
+	assert.Equal(t, "44", notifications[1].Revision)
+	assert.False(t, notifications[1].Pinned)
+	assert.NoError(t, configManager.RemoveOnProjectConfigUpdate(id))
+}
+
+func TestPinRetainsServedRevision(t *testing.T) {
+	mockRequester := new(MockRequester)
+	for _, revision := range []string{"43", "44", "45"} {
+		datafile := []byte(`{"revision":"` + revision + `","version": "4"}`)
+		mockRequester.On("Get", []utils.Header(nil)).Return(datafile, http.Header{}, http.StatusOK, nil).Once()
+	}
+
+	configManager := NewAsyncPollingProjectConfigManager("test_sdk_key_pin_retain", WithRequester(mockRequester),
+		WithDatafileURLTemplate("https://primary/%s.json"), WithRetainedRevisions(2))
+	configManager.SyncConfig()
+	assert.NoError(t, configManager.Pin())
+
+	// the pinned revision outlives the revisions fetched after it
+	configManager.SyncConfig()
+	configManager.SyncConfig()
+	assert.Equal(t, []string{"43", "45"}, configManager.RetainedRevisions())
+
+	var notifications []notification.ProjectConfigUpdateNotification
+	id, _ := configManager.OnProjectConfigUpdate(func(n notification.ProjectConfigUpdateNotification) {
+		notifications = append(notifications, n)
+	})
+	assert.NoError(t, configManager.Rollback("43"))
+	configManager.Unpin()
+	actual, _ := configManager.GetConfig()
+	assert.Equal(t, "45", actual.GetRevision())
+	assert.Equal(t, []string{"43", "45"}, configManager.RetainedRevisions())
+
+	assert.Equal(t, []notification.ProjectConfigUpdateNotification{
+		{Type: notification.ProjectConfigUpdate, Revision: "43", Source: "https://primary/%s.json", Pinned: true},
+		{Type: notification.ProjectConfigUpdate, Revision: "45", Source: "https://primary/%s.json", Pinned: false},
+	}, notifications)
+	assert.NoError(t, configManager.RemoveOnProjectConfigUpdate(id))
+}
+
+func TestPinWithoutConfig(t *testing.T) {
+	configManager := NewAsyncPollingProjectConfigManager("test_sdk_key_pin_empty", WithRequester(new(MockRequester)))
+	assert.Error(t, configManager.Pin())
+	assert.False(t, configManager.IsPinned())
+}
@@ -0,0 +1,333 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package config //
+package config
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/optimizely/go-sdk/v2/pkg/logging"
+	"github.com/optimizely/go-sdk/v2/pkg/utils"
+)
+
+const (
+	// DefaultStreamReconnectDelay is the initial delay before reconnecting a dropped stream
+	DefaultStreamReconnectDelay = 1 * time.Second
+	// DefaultStreamMaxReconnectDelay caps the delay between stream reconnection attempts
+	DefaultStreamMaxReconnectDelay = 1 * time.Minute
+	// DefaultStreamIdleTimeout is how long a stream may stay silent (including heartbeats) before it is dropped
+	DefaultStreamIdleTimeout = 2 * time.Minute
+)
+
+const (
+	// StreamEventDatafile is the server-sent event carrying a complete datafile as its data
+	StreamEventDatafile = "datafile"
+	// StreamEventMessage is the default server-sent event type, treated as a datafile
+	StreamEventMessage = "message"
+	// StreamEventInvalidate is the server-sent event announcing a new revision that must be fetched
+	StreamEventInvalidate = "invalidate"
+	// StreamFieldSignature is the server-sent event field carrying the base64 encoded signature of a streamed datafile.
+	// It is handed to the datafile verifier as the DatafileSignatureHeader response header.
+	StreamFieldSignature = "signature"
+)
+
+// StreamingProjectConfigManager maintains a dynamic copy of the project config by listening to datafile updates
+// pushed over a server-sent events stream. While the stream is down it falls back to polling.
+// Streamed datafiles are verified like polled ones, so a verifier reading SignatureFromHeader(DatafileSignatureHeader)
+// requires the stream to send the signature field with every datafile event, other signature headers are not supported.
+type StreamingProjectConfigManager struct {
+	*PollingProjectConfigManager
+
+	streamURLTemplate    string
+	streamClient         *http.Client
+	streamHeaders        []utils.Header
+	reconnectDelay       time.Duration
+	maxReconnectDelay    time.Duration
+	idleTimeout          time.Duration
+	pollingManagerOption []OptionFunc
+	logger               logging.OptimizelyLogProducer
+
+	streamLock  sync.Mutex
+	connected   bool
+	lastEventID string
+}
+
+// StreamingOptionFunc is used to provide custom configuration to the StreamingProjectConfigManager.
+type StreamingOptionFunc func(*StreamingProjectConfigManager)
+
+// WithStreamURLTemplate sets the endpoint template, formatted with the sdk key, that serves the event stream
+func WithStreamURLTemplate(streamURLTemplate string) StreamingOptionFunc {
+	return func(s *StreamingProjectConfigManager) {
+		s.streamURLTemplate = streamURLTemplate
+	}
+}
+
+// WithStreamClient sets the http client used for the long-lived stream connection
+func WithStreamClient(client *http.Client) StreamingOptionFunc {
+	return func(s *StreamingProjectConfigManager) {
+		s.streamClient = client
+	}
+}
+
+// WithStreamReconnectDelay sets the initial and maximum delay between stream reconnection attempts
+func WithStreamReconnectDelay(reconnectDelay, maxReconnectDelay time.Duration) StreamingOptionFunc {
+	return func(s *StreamingProjectConfigManager) {
+		s.reconnectDelay = reconnectDelay
+		s.maxReconnectDelay = maxReconnectDelay
+	}
+}
+
+// WithStreamIdleTimeout sets how long the stream may stay silent before it is considered dropped
+func WithStreamIdleTimeout(idleTimeout time.Duration) StreamingOptionFunc {
+	return func(s *StreamingProjectConfigManager) {
+		s.idleTimeout = idleTimeout
+	}
+}
+
+// WithPollingOptions sets the options of the polling manager used for the initial fetch and as a fallback
+func WithPollingOptions(pollingMangerOptions ...OptionFunc) StreamingOptionFunc {
+	return func(s *StreamingProjectConfigManager) {
+		s.pollingManagerOption = append(s.pollingManagerOption, pollingMangerOptions...)
+	}
+}
+
+// NewStreamingProjectConfigManager returns an instance of the streaming config manager with the customized configuration
+func NewStreamingProjectConfigManager(sdkKey string, streamingManagerOptions ...StreamingOptionFunc) *StreamingProjectConfigManager {
+	streamingProjectConfigManager := StreamingProjectConfigManager{
+		streamClient:      &http.Client{},
+		reconnectDelay:    DefaultStreamReconnectDelay,
+		maxReconnectDelay: DefaultStreamMaxReconnectDelay,
+		idleTimeout:       DefaultStreamIdleTimeout,
+		logger:            logging.GetLogger(sdkKey, "StreamingProjectConfigManager"),
+	}
+
+	for _, opt := range streamingManagerOptions {
+		opt(&streamingProjectConfigManager)
+	}
+
+	if streamingProjectConfigManager.maxReconnectDelay < streamingProjectConfigManager.reconnectDelay {
+		streamingProjectConfigManager.maxReconnectDelay = streamingProjectConfigManager.reconnectDelay
+	}
+
+	streamingProjectConfigManager.PollingProjectConfigManager = NewPollingProjectConfigManager(sdkKey, streamingProjectConfigManager.pollingManagerOption...)
+	streamingProjectConfigManager.streamHeaders = []utils.Header{
+		{Name: utils.HeaderAccept, Value: "text/event-stream"},
+		{Name: "Cache-Control", Value: "no-cache"},
+	}
+	if token := streamingProjectConfigManager.datafileAccessToken; token != "" {
+		streamingProjectConfigManager.streamHeaders = append(streamingProjectConfigManager.streamHeaders,
+			utils.Header{Name: utils.HeaderAuthorization, Value: "Bearer " + token})
+	}
+	return &streamingProjectConfigManager
+}
+
+// IsStreaming returns whether the stream is currently connected
+func (cm *StreamingProjectConfigManager) IsStreaming() bool {
+	cm.streamLock.Lock()
+	defer cm.streamLock.Unlock()
+	return cm.connected
+}
+
+// Start keeps the stream connected until the given context is done, polling for updates whenever the stream is down
+func (cm *StreamingProjectConfigManager) Start(ctx context.Context) {
+	if cm.streamURLTemplate == "" {
+		cm.logger.Warning("No stream URL template configured, falling back to polling")
+		cm.PollingProjectConfigManager.Start(ctx)
+		return
+	}
+
+	cm.logger.Debug("Streaming Config Manager Initiated")
+	delay := cm.reconnectDelay
+	for {
+		received, err := cm.stream(ctx)
+		if ctx.Err() != nil {
+			cm.logger.Debug("Streaming Config Manager Stopped")
+			return
+		}
+		if received {
+			// the stream was healthy, so start over with the shortest delay
+			delay = cm.reconnectDelay
+		}
+		cm.logger.Warning(fmt.Sprintf("Datafile stream dropped, polling until it is re-established: %v", err))
+
+		// catch up on updates that may have been missed while the stream was down
+		cm.SyncConfig()
+		if !cm.waitForReconnect(ctx, delay) {
+			cm.logger.Debug("Streaming Config Manager Stopped")
+			return
+		}
+		if delay *= 2; delay > cm.maxReconnectDelay {
+			delay = cm.maxReconnectDelay
+		}
+	}
+}
+
+// waitForReconnect polls at the polling interval until it is time to reconnect, returns false once ctx is done
+func (cm *StreamingProjectConfigManager) waitForReconnect(ctx context.Context, delay time.Duration) bool {
+	reconnect := time.NewTimer(delay)
+	defer reconnect.Stop()
+
+	var pollingTick <-chan time.Time
+	if cm.pollingInterval > 0 {
+		pollingTicker := time.NewTicker(cm.pollingInterval)
+		defer pollingTicker.Stop()
+		pollingTick = pollingTicker.C
+	}
+
+	for {
+		select {
+		case <-reconnect.C:
+			return true
+		case <-pollingTick:
+			cm.SyncConfig()
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// stream holds a single stream connection until it drops, returns whether any event was received on it
+func (cm *StreamingProjectConfigManager) stream(ctx context.Context) (received bool, err error) {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(streamCtx, http.MethodGet, fmt.Sprintf(cm.streamURLTemplate, cm.sdkKey), http.NoBody)
+	if err != nil {
+		return false, err
+	}
+	for _, h := range cm.streamHeaders {
+		req.Header.Add(h.Name, h.Value)
+	}
+	cm.streamLock.Lock()
+	if cm.lastEventID != "" {
+		req.Header.Set("Last-Event-ID", cm.lastEventID)
+	}
+	cm.streamLock.Unlock()
+
+	resp, err := cm.streamClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if e := resp.Body.Close(); e != nil {
+			cm.logger.Debug(fmt.Sprintf("can't close stream body, %s", e))
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		if resp.StatusCode == http.StatusForbidden {
+			return false, Err403Forbidden
+		}
+		return false, fmt.Errorf("unexpected stream response: %s", resp.Status)
+	}
+
+	cm.setConnected(true)
+	defer cm.setConnected(false)
+	cm.logger.Debug("Datafile stream connected")
+	// an update may have been published between the last poll and the stream being connected
+	cm.SyncConfig()
+
+	idle := time.AfterFunc(cm.idleTimeout, cancel)
+	defer idle.Stop()
+
+	reader := bufio.NewReader(resp.Body)
+	var eventType, eventID, signature string
+	var data strings.Builder
+	for {
+		line, readErr := reader.ReadString('\n')
+		if readErr != nil {
+			if readErr == io.EOF {
+				readErr = errors.New("stream closed by server")
+			}
+			return received, readErr
+		}
+		idle.Reset(cm.idleTimeout)
+
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			// a blank line dispatches the buffered event
+			if data.Len() > 0 || eventType != "" {
+				received = true
+				cm.handleEvent(eventType, eventID, data.String(), signature)
+			}
+			eventType, eventID, signature = "", "", ""
+			data.Reset()
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			// comment, used by servers as a heartbeat
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+		case "id":
+			eventID = value
+		case StreamFieldSignature:
+			signature = value
+		}
+	}
+}
+
+func (cm *StreamingProjectConfigManager) handleEvent(eventType, eventID, data, signature string) {
+	if eventID != "" {
+		cm.streamLock.Lock()
+		cm.lastEventID = eventID
+		cm.streamLock.Unlock()
+	}
+
+	switch eventType {
+	case StreamEventDatafile, StreamEventMessage, "":
+		if data == "" {
+			return
+		}
+		respHeaders := http.Header{}
+		if signature != "" {
+			respHeaders.Set(DatafileSignatureHeader, signature)
+		}
+		outcome, err := cm.applyDatafile([]byte(data), respHeaders, cm.streamURLTemplate, true)
+		if err != nil {
+			cm.logger.Warning(fmt.Sprintf("Unable to apply streamed datafile: %s", err))
+		}
+		cm.pushCompleted(cm.streamURLTemplate, outcome, err)
+	case StreamEventInvalidate:
+		cm.SyncConfig()
+	default:
+		cm.logger.Debug(fmt.Sprintf("Ignoring stream event of type %s", eventType))
+	}
+}
+
+func (cm *StreamingProjectConfigManager) setConnected(connected bool) {
+	cm.streamLock.Lock()
+	defer cm.streamLock.Unlock()
+	cm.connected = connected
+}
@@ -0,0 +1,231 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package config
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/optimizely/go-sdk/v2/pkg/notification"
+	"github.com/optimizely/go-sdk/v2/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newStreamServer(events ...string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		flusher := w.(http.Flusher)
+		fmt.Fprint(w, ": heartbeat\n\n")
+		flusher.Flush()
+		for _, event := range events {
+			fmt.Fprint(w, event)
+			flusher.Flush()
+		}
+		<-r.Context().Done()
+	}))
+}
+
+func TestStreamingProjectConfigManagerAppliesStreamedDatafile(t *testing.T) {
+	mockDatafile1 := []byte(`{"revision":"42","version": "4"}`)
+	server := newStreamServer("id: 1\nevent: datafile\ndata: {\"revision\":\"43\",\ndata: \"version\": \"4\"}\n\n")
+	defer server.Close()
+
+	mockRequester := new(MockRequester)
+	mockRequester.On("Get", []utils.Header(nil)).Return(mockDatafile1, http.Header{}, http.StatusOK, nil)
+
+	sdkKey := "test_sdk_key_streaming"
+	configManager := NewStreamingProjectConfigManager(sdkKey,
+		WithStreamURLTemplate(server.URL+"/%s"),
+		WithPollingOptions(WithRequester(mockRequester)),
+	)
+	actual, err := configManager.GetConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "42", actual.GetRevision())
+
+	var numberOfCalls uint64
+	id, _ := configManager.OnProjectConfigUpdate(func(notification notification.ProjectConfigUpdateNotification) {
+		atomic.AddUint64(&numberOfCalls, 1)
+	})
+
+	eg := newExecGroup()
+	eg.Go(configManager.Start)
+	assertPeriodically(t, func() bool {
+		actual, _ := configManager.GetConfig()
+		return actual.GetRevision() == "43"
+	})
+	assert.True(t, configManager.IsStreaming())
+	assert.Equal(t, uint64(1), atomic.LoadUint64(&numberOfCalls))
+	eg.TerminateAndWait()
+
+	assert.False(t, configManager.IsStreaming())
+	assert.Equal(t, "1", configManager.lastEventID)
+	assert.NoError(t, configManager.RemoveOnProjectConfigUpdate(id))
+}
+
+func TestStreamingProjectConfigManagerVerifiesStreamedDatafile(t *testing.T) {
+	key := []byte("shared-secret")
+	sign := func(datafile []byte) string {
+		mac := hmac.New(sha256.New, key)
+		mac.Write(datafile)
+		return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	}
+	mockDatafile1 := []byte(`{"revision":"42","version": "4"}`)
+	mockDatafile2 := []byte(`{"revision":"43","version": "4"}`)
+	server := newStreamServer(
+		fmt.Sprintf("event: datafile\nsignature: %s\ndata: %s\n\n", sign(mockDatafile2), mockDatafile2),
+		"event: datafile\ndata: {\"revision\":\"44\",\"version\": \"4\"}\n\n",
+	)
+	defer server.Close()
+
+	respHeaders := http.Header{}
+	respHeaders.Set(DatafileSignatureHeader, sign(mockDatafile1))
+	respHeaders.Set(ETag, "etag-42")
+	mockRequester := new(MockRequester)
+	mockRequester.On("Get", []utils.Header(nil)).Return(mockDatafile1, respHeaders, http.StatusOK, nil).Times(1)
+	mockRequester.On("Get", []utils.Header{{Name: IfNoneMatch, Value: "etag-42"}}).Return([]byte{}, http.Header{}, http.StatusNotModified, nil)
+
+	configManager := NewStreamingProjectConfigManager("test_sdk_key_streaming_verifier",
+		WithStreamURLTemplate(server.URL+"/%s"),
+		WithPollingOptions(WithRequester(mockRequester), WithDatafileVerifier(NewHMACVerifier(key, SignatureFromHeader(DatafileSignatureHeader)))),
+	)
+	var lock sync.Mutex
+	var polls []notification.ConfigPollNotification
+	id, err := configManager.OnConfigPoll(func(n notification.ConfigPollNotification) {
+		lock.Lock()
+		defer lock.Unlock()
+		polls = append(polls, n)
+	})
+	assert.NoError(t, err)
+
+	eg := newExecGroup()
+	eg.Go(configManager.Start)
+	streamURL := server.URL + "/%s"
+	assertPeriodically(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(polls) == 3
+	})
+	eg.TerminateAndWait()
+
+	// the signed datafile is applied, the unsigned one is rejected
+	actual, _ := configManager.GetConfig()
+	assert.Equal(t, "43", actual.GetRevision())
+	assert.Equal(t, notification.PollNotModified, polls[0].Outcome)
+	assert.Equal(t, notification.PollNewRevision, polls[1].Outcome)
+	assert.Equal(t, streamURL, polls[1].Source)
+	assert.Equal(t, notification.PollRejected, polls[2].Outcome)
+	assert.Equal(t, streamURL, polls[2].Source)
+
+	// pushed datafiles leave the polling source and its validators untouched
+	assert.Equal(t, DatafileURLTemplate, configManager.datafileSource)
+	assert.Equal(t, "etag-42", configManager.etag)
+	mockRequester.AssertExpectations(t)
+	assert.NoError(t, configManager.RemoveOnConfigPoll(id))
+}
+
+func TestStreamingProjectConfigManagerInvalidateTriggersSync(t *testing.T) {
+	mockDatafile1 := []byte(`{"revision":"42","version": "4"}`)
+	mockDatafile2 := []byte(`{"revision":"43","version": "4"}`)
+	server := newStreamServer("event: invalidate\ndata: 43\n\n")
+	defer server.Close()
+
+	mockRequester := new(MockRequester)
+	mockRequester.On("Get", []utils.Header(nil)).Return(mockDatafile1, http.Header{}, http.StatusOK, nil).Times(2)
+	mockRequester.On("Get", []utils.Header(nil)).Return(mockDatafile2, http.Header{}, http.StatusOK, nil)
+
+	configManager := NewStreamingProjectConfigManager("test_sdk_key",
+		WithStreamURLTemplate(server.URL+"/%s"),
+		WithPollingOptions(WithRequester(mockRequester)),
+	)
+
+	eg := newExecGroup()
+	eg.Go(configManager.Start)
+	assertPeriodically(t, func() bool {
+		actual, _ := configManager.GetConfig()
+		return actual.GetRevision() == "43"
+	})
+	eg.TerminateAndWait()
+}
+
+func TestStreamingProjectConfigManagerFallsBackToPolling(t *testing.T) {
+	mockDatafile1 := []byte(`{"revision":"42","version": "4"}`)
+	mockDatafile2 := []byte(`{"revision":"43","version": "4"}`)
+	var connections int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&connections, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	mockRequester := new(MockRequester)
+	mockRequester.On("Get", []utils.Header(nil)).Return(mockDatafile1, http.Header{}, http.StatusOK, nil).Times(1)
+	mockRequester.On("Get", []utils.Header(nil)).Return(mockDatafile2, http.Header{}, http.StatusOK, nil)
+
+	configManager := NewStreamingProjectConfigManager("test_sdk_key",
+		WithStreamURLTemplate(server.URL+"/%s"),
+		WithStreamReconnectDelay(50*time.Millisecond, 100*time.Millisecond),
+		WithPollingOptions(WithRequester(mockRequester), WithPollingInterval(20*time.Millisecond)),
+	)
+
+	eg := newExecGroup()
+	eg.Go(configManager.Start)
+	assertPeriodically(t, func() bool {
+		actual, _ := configManager.GetConfig()
+		return actual.GetRevision() == "43" && atomic.LoadInt32(&connections) > 1
+	})
+	assert.False(t, configManager.IsStreaming())
+	eg.TerminateAndWait()
+}
+
+func TestStreamingProjectConfigManagerDropsIdleStream(t *testing.T) {
+	mockDatafile1 := []byte(`{"revision":"42","version": "4"}`)
+	var connections int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&connections, 1)
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	mockRequester := new(MockRequester)
+	mockRequester.On("Get", []utils.Header(nil)).Return(mockDatafile1, http.Header{}, http.StatusOK, nil)
+
+	configManager := NewStreamingProjectConfigManager("test_sdk_key",
+		WithStreamURLTemplate(server.URL+"/%s"),
+		WithStreamIdleTimeout(20*time.Millisecond),
+		WithStreamReconnectDelay(10*time.Millisecond, 10*time.Millisecond),
+		WithPollingOptions(WithRequester(mockRequester)),
+	)
+
+	eg := newExecGroup()
+	eg.Go(configManager.Start)
+	assertPeriodically(t, func() bool {
+		return atomic.LoadInt32(&connections) > 1
+	})
+	eg.TerminateAndWait()
+}
@@ -0,0 +1,285 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package config //
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/optimizely/go-sdk/v2/pkg/config/datafileprojectconfig"
+	"github.com/optimizely/go-sdk/v2/pkg/entities"
+	"github.com/optimizely/go-sdk/v2/pkg/logging"
+)
+
+// maxTrafficRange is the exclusive upper bound of bucketing values
+const maxTrafficRange = 10000
+
+// IssueSeverity tells whether a validation issue breaks decisions
+type IssueSeverity string
+
+const (
+	// SeverityWarning is used for issues that are tolerated at decision time
+	SeverityWarning IssueSeverity = "warning"
+	// SeverityFatal is used for issues that make decisions fail or behave unexpectedly
+	SeverityFatal IssueSeverity = "fatal"
+)
+
+// IssueType is the kind of problem found while validating a project config
+type IssueType string
+
+const (
+	// IssueMissingVariation is used when a traffic allocation references a variation the rule does not have
+	IssueMissingVariation IssueType = "missing_variation"
+	// IssueInvalidTrafficRange is used when traffic allocation ranges are out of bounds or not increasing
+	IssueInvalidTrafficRange IssueType = "invalid_traffic_range"
+	// IssueVariableTypeMismatch is used when a variable value cannot be converted to the variable type
+	IssueVariableTypeMismatch IssueType = "variable_type_mismatch"
+	// IssueUnknownVariable is used when a variation sets a variable its flag does not declare
+	IssueUnknownVariable IssueType = "unknown_variable"
+	// IssueMissingExperiment is used when a flag references an experiment that does not exist
+	IssueMissingExperiment IssueType = "missing_experiment"
+	// IssueMissingAudience is used when a rule references an audience that does not exist
+	IssueMissingAudience IssueType = "missing_audience"
+)
+
+// ValidationIssue is a single problem found while validating a project config
+type ValidationIssue struct {
+	Type      IssueType
+	Severity  IssueSeverity
+	EntityKey string // key of the flag, experiment, rule or holdout the issue was found in
+	Message   string
+}
+
+func (i ValidationIssue) String() string {
+	return fmt.Sprintf("[%s] %s %s: %s", i.Severity, i.Type, i.EntityKey, i.Message)
+}
+
+// DatafileValidationError is returned when a datafile is refused because of fatal validation issues
+type DatafileValidationError struct {
+	Issues []ValidationIssue
+}
+
+func (e *DatafileValidationError) Error() string {
+	messages := make([]string, 0, len(e.Issues))
+	for _, issue := range e.Issues {
+		messages = append(messages, issue.String())
+	}
+	return fmt.Sprintf("datafile has %d fatal issue(s): %s", len(e.Issues), strings.Join(messages, "; "))
+}
+
+// HasFatalIssues returns whether any of the given issues is fatal
+func HasFatalIssues(issues []ValidationIssue) bool {
+	for _, issue := range issues {
+		if issue.Severity == SeverityFatal {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateDatafile parses the given datafile and validates the resulting project config
+func ValidateDatafile(datafile []byte) ([]ValidationIssue, error) {
+	projectConfig, err := datafileprojectconfig.NewDatafileProjectConfig(datafile, logging.GetLogger("", "DatafileValidator"))
+	if err != nil {
+		return nil, err
+	}
+	return ValidateProjectConfig(projectConfig), nil
+}
+
+// ValidateProjectConfig checks a project config for problems that parsing accepts but that break decisions at runtime
+func ValidateProjectConfig(projectConfig ProjectConfig) []ValidationIssue {
+	v := validator{audienceMap: projectConfig.GetAudienceMap()}
+
+	experiments := projectConfig.GetExperimentList()
+	sort.Slice(experiments, func(i, j int) bool { return experiments[i].Key < experiments[j].Key })
+	for _, experiment := range experiments {
+		v.validateRule(experiment)
+	}
+
+	features := projectConfig.GetFeatureList()
+	sort.Slice(features, func(i, j int) bool { return features[i].Key < features[j].Key })
+	validatedHoldouts := map[string]bool{}
+	for _, holdout := range projectConfig.GetGlobalHoldouts() {
+		validatedHoldouts[holdout.ID] = true
+		v.validateHoldout(holdout)
+	}
+	for _, feature := range features {
+		v.validateFeature(projectConfig, feature)
+		for _, rule := range append(append([]entities.Experiment{}, feature.FeatureExperiments...), feature.Rollout.Experiments...) {
+			for _, holdout := range projectConfig.GetHoldoutsForRule(rule.ID) {
+				if !validatedHoldouts[holdout.ID] {
+					validatedHoldouts[holdout.ID] = true
+					v.validateHoldout(holdout)
+				}
+			}
+		}
+	}
+	return v.issues
+}
+
+type validator struct {
+	audienceMap map[string]entities.Audience
+	issues      []ValidationIssue
+}
+
+func (v *validator) add(issueType IssueType, severity IssueSeverity, entityKey, format string, args ...interface{}) {
+	v.issues = append(v.issues, ValidationIssue{
+		Type:      issueType,
+		Severity:  severity,
+		EntityKey: entityKey,
+		Message:   fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) validateFeature(projectConfig ProjectConfig, feature entities.Feature) {
+	for _, experimentID := range feature.ExperimentIDs {
+		if _, err := projectConfig.GetExperimentByID(experimentID); err != nil {
+			v.add(IssueMissingExperiment, SeverityWarning, feature.Key, "experiment %s does not exist", experimentID)
+		}
+	}
+
+	variableTypes := map[string]entities.Variable{}
+	variableKeys := make([]string, 0, len(feature.VariableMap))
+	for key := range feature.VariableMap {
+		variableKeys = append(variableKeys, key)
+	}
+	sort.Strings(variableKeys)
+	for _, key := range variableKeys {
+		variable := feature.VariableMap[key]
+		variableTypes[variable.ID] = variable
+		if !isValidVariableValue(variable.Type, variable.DefaultValue) {
+			v.add(IssueVariableTypeMismatch, SeverityFatal, feature.Key, "default value %q of variable %s is not a valid %s",
+				variable.DefaultValue, variable.Key, variable.Type)
+		}
+	}
+
+	for _, rule := range feature.Rollout.Experiments {
+		v.validateRule(rule)
+		v.validateVariationVariables(rule.Key, rule.Variations, variableTypes)
+	}
+	for _, experiment := range feature.FeatureExperiments {
+		v.validateVariationVariables(experiment.Key, experiment.Variations, variableTypes)
+	}
+}
+
+func (v *validator) validateRule(rule entities.Experiment) {
+	v.validateTrafficAllocation(rule.Key, rule.TrafficAllocation, rule.Variations)
+	v.validateAudiences(rule.Key, rule.AudienceIds, rule.AudienceConditionTree)
+}
+
+func (v *validator) validateHoldout(holdout entities.Holdout) {
+	v.validateTrafficAllocation(holdout.Key, holdout.TrafficAllocation, holdout.Variations)
+	v.validateAudiences(holdout.Key, holdout.AudienceIds, holdout.AudienceConditionTree)
+}
+
+func (v *validator) validateTrafficAllocation(entityKey string, trafficAllocation []entities.Range, variations map[string]entities.Variation) {
+	previousEnd := 0
+	for _, trafficRange := range trafficAllocation {
+		if trafficRange.EndOfRange < previousEnd || trafficRange.EndOfRange > maxTrafficRange {
+			v.add(IssueInvalidTrafficRange, SeverityFatal, entityKey, "end of range %d must be between %d and %d",
+				trafficRange.EndOfRange, previousEnd, maxTrafficRange)
+		} else {
+			previousEnd = trafficRange.EndOfRange
+		}
+		// an empty entity id is a valid holdback of traffic
+		if trafficRange.EntityID == "" {
+			continue
+		}
+		if _, ok := variations[trafficRange.EntityID]; !ok {
+			v.add(IssueMissingVariation, SeverityFatal, entityKey, "traffic allocation references unknown variation %s", trafficRange.EntityID)
+		}
+	}
+}
+
+func (v *validator) validateAudiences(entityKey string, audienceIDs []string, audienceConditionTree *entities.TreeNode) {
+	referenced := map[string]bool{}
+	for _, audienceID := range audienceIDs {
+		referenced[audienceID] = true
+	}
+	collectAudienceIDs(audienceConditionTree, referenced)
+
+	ids := make([]string, 0, len(referenced))
+	for id := range referenced {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if _, ok := v.audienceMap[id]; !ok {
+			v.add(IssueMissingAudience, SeverityWarning, entityKey, "audience %s does not exist", id)
+		}
+	}
+}
+
+func (v *validator) validateVariationVariables(entityKey string, variations map[string]entities.Variation, variables map[string]entities.Variable) {
+	variationIDs := make([]string, 0, len(variations))
+	for id := range variations {
+		variationIDs = append(variationIDs, id)
+	}
+	sort.Strings(variationIDs)
+
+	for _, variationID := range variationIDs {
+		variation := variations[variationID]
+		variableIDs := make([]string, 0, len(variation.Variables))
+		for id := range variation.Variables {
+			variableIDs = append(variableIDs, id)
+		}
+		sort.Strings(variableIDs)
+
+		for _, variableID := range variableIDs {
+			variable, ok := variables[variableID]
+			if !ok {
+				v.add(IssueUnknownVariable, SeverityWarning, entityKey, "variation %s sets undeclared variable %s", variation.Key, variableID)
+				continue
+			}
+			if value := variation.Variables[variableID].Value; !isValidVariableValue(variable.Type, value) {
+				v.add(IssueVariableTypeMismatch, SeverityFatal, entityKey, "value %q of variable %s in variation %s is not a valid %s",
+					value, variable.Key, variation.Key, variable.Type)
+			}
+		}
+	}
+}
+
+func collectAudienceIDs(node *entities.TreeNode, ids map[string]bool) {
+	if node == nil {
+		return
+	}
+	if id, ok := node.Item.(string); ok {
+		ids[id] = true
+	}
+	for _, child := range node.Nodes {
+		collectAudienceIDs(child, ids)
+	}
+}
+
+func isValidVariableValue(variableType entities.VariableType, value string) bool {
+	var err error
+	switch variableType {
+	case entities.Integer:
+		_, err = strconv.Atoi(value)
+	case entities.Double:
+		_, err = strconv.ParseFloat(value, 64)
+	case entities.Boolean:
+		_, err = strconv.ParseBool(value)
+	case entities.JSON:
+		return json.Valid([]byte(value))
+	}
+	return err == nil
+}
@@ -0,0 +1,120 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package config
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/optimizely/go-sdk/v2/pkg/notification"
+	"github.com/optimizely/go-sdk/v2/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const validatorDatafile = `{
+	"version": "4",
+	"revision": "REVISION",
+	"audiences": [{"id": "a1", "name": "audience", "conditions": "[\"or\", {\"name\": \"age\", \"type\": \"custom_attribute\", \"value\": 1}]"}],
+	"experiments": [{
+		"id": "e1", "key": "exp", "layerId": "l1", "status": "Running", "audienceIds": ["a1", "a2"],
+		"variations": [{"id": "v1", "key": "control", "featureEnabled": true, "variables": [{"id": "var1", "value": "NaN-int"}, {"id": "var9", "value": "x"}]}],
+		"trafficAllocation": [{"entityId": "v1", "endOfRange": 5000}, {"entityId": "v2", "endOfRange": 10000}]
+	}],
+	"rollouts": [{"id": "r1", "experiments": [{
+		"id": "rule1", "key": "rule", "layerId": "l2", "status": "Running", "audienceIds": [],
+		"variations": [{"id": "v3", "key": "on", "featureEnabled": true, "variables": [{"id": "var1", "value": "5"}]}],
+		"trafficAllocation": [{"entityId": "v3", "endOfRange": 8000}, {"entityId": "", "endOfRange": 6000}, {"entityId": "v3", "endOfRange": 12000}]
+	}]}],
+	"featureFlags": [{
+		"id": "f1", "key": "flag", "rolloutId": "r1", "experimentIds": ["e1", "e2"],
+		"variables": [{"id": "var1", "key": "count", "type": "integer", "defaultValue": "1"},
+			{"id": "var2", "key": "enabled", "type": "boolean", "defaultValue": "yes"}]
+	}]
+}`
+
+func sprintfRevision(datafile, revision string) string {
+	return strings.Replace(datafile, "REVISION", revision, 1)
+}
+
+func TestValidateDatafile(t *testing.T) {
+	issues, err := ValidateDatafile([]byte(sprintfRevision(validatorDatafile, "42")))
+	assert.NoError(t, err)
+	assert.True(t, HasFatalIssues(issues))
+
+	assert.Equal(t, []ValidationIssue{
+		{Type: IssueMissingVariation, Severity: SeverityFatal, EntityKey: "exp", Message: "traffic allocation references unknown variation v2"},
+		{Type: IssueMissingAudience, Severity: SeverityWarning, EntityKey: "exp", Message: "audience a2 does not exist"},
+		{Type: IssueMissingExperiment, Severity: SeverityWarning, EntityKey: "flag", Message: "experiment e2 does not exist"},
+		{Type: IssueVariableTypeMismatch, Severity: SeverityFatal, EntityKey: "flag", Message: `default value "yes" of variable enabled is not a valid boolean`},
+		{Type: IssueInvalidTrafficRange, Severity: SeverityFatal, EntityKey: "rule", Message: "end of range 6000 must be between 8000 and 10000"},
+		{Type: IssueInvalidTrafficRange, Severity: SeverityFatal, EntityKey: "rule", Message: "end of range 12000 must be between 8000 and 10000"},
+		{Type: IssueVariableTypeMismatch, Severity: SeverityFatal, EntityKey: "exp", Message: `value "NaN-int" of variable count in variation control is not a valid integer`},
+		{Type: IssueUnknownVariable, Severity: SeverityWarning, EntityKey: "exp", Message: "variation control sets undeclared variable var9"},
+	}, issues)
+}
+
+func TestValidateDatafileInvalidJSON(t *testing.T) {
+	_, err := ValidateDatafile([]byte("NOT-VALID"))
+	assert.Error(t, err)
+}
+
+func TestValidateDatafileWellFormedDatafiles(t *testing.T) {
+	for _, path := range []string{"testdata/optimizely_config_datafile.json", "testdata/typed_audience_datafile.json", "../../test-data/decide-test-datafile.json"} {
+		datafile, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		issues, err := ValidateDatafile(datafile)
+		assert.NoError(t, err)
+		assert.False(t, HasFatalIssues(issues), path)
+	}
+}
+
+func TestSyncConfigRefusesDatafileWithFatalIssues(t *testing.T) {
+	mockDatafile1 := []byte(`{"revision":"42","version": "4"}`)
+	mockDatafile2 := []byte(sprintfRevision(validatorDatafile, "43"))
+
+	mockRequester := new(MockRequester)
+	mockRequester.On("Get", []utils.Header(nil)).Return(mockDatafile2, http.Header{}, http.StatusOK, nil)
+
+	sdkKey := "test_sdk_key_validation"
+	configManager := NewAsyncPollingProjectConfigManager(sdkKey, WithRequester(mockRequester), WithInitialDatafile(mockDatafile1), WithDatafileValidation())
+	var rejections []notification.DatafileRejectedNotification
+	id, _ := configManager.OnDatafileRejected(func(n notification.DatafileRejectedNotification) {
+		rejections = append(rejections, n)
+	})
+
+	configManager.SyncConfig()
+	actual, err := configManager.GetConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "42", actual.GetRevision())
+
+	assert.Len(t, rejections, 1)
+	assert.Equal(t, "43", rejections[0].Revision)
+	var validationErr *DatafileValidationError
+	assert.True(t, errors.As(rejections[0].Reason, &validationErr))
+	assert.Len(t, validationErr.Issues, 5)
+	assert.NoError(t, configManager.RemoveOnDatafileRejected(id))
+
+	// Without validation the same revision is accepted
+	configManager = NewAsyncPollingProjectConfigManager(sdkKey, WithRequester(mockRequester), WithInitialDatafile(mockDatafile1))
+	configManager.SyncConfig()
+	actual, _ = configManager.GetConfig()
+	assert.Equal(t, "43", actual.GetRevision())
+}
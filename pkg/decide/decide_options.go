@@ -51,6 +51,8 @@ type Options struct {
 	IgnoreCMABCache          bool
 	ResetCMABCache           bool
 	InvalidateUserCMABCache  bool
+	// Trace when set records the steps of the decision, see DecisionTrace
+	Trace *DecisionTrace
 }
 
 // TranslateOptions converts string options array to array of OptimizelyDecideOptions
@@ -0,0 +1,107 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package decide //
+package decide
+
+// TraceStepKind is the kind of step recorded in a DecisionTrace
+type TraceStepKind string
+
+const (
+	// TraceForcedDecision is recorded when a forced decision is mapped to the flag or rule
+	TraceForcedDecision TraceStepKind = "forced_decision"
+	// TraceUserProfile is recorded when the user profile is looked up for a rule
+	TraceUserProfile TraceStepKind = "user_profile"
+	// TraceHoldout is recorded with the outcome of each holdout considered
+	TraceHoldout TraceStepKind = "holdout"
+	// TraceAudience is recorded with the result of an audience condition tree evaluation
+	TraceAudience TraceStepKind = "audience"
+	// TraceBucketing is recorded with the bucketing value computed for a rule or holdout
+	TraceBucketing TraceStepKind = "bucketing"
+	// TraceRule is recorded with the outcome of each rule considered
+	TraceRule TraceStepKind = "rule"
+)
+
+// DecisionTrace is a machine readable record of the steps taken to decide a flag for a user. It is filled in when set
+// on the Options of a decision, it must not be shared between concurrent decisions.
+type DecisionTrace struct {
+	FlagKey      string      `json:"flagKey"`
+	UserID       string      `json:"userId"`
+	RuleKey      string      `json:"ruleKey,omitempty"`
+	VariationKey string      `json:"variationKey,omitempty"`
+	Enabled      bool        `json:"enabled"`
+	Reasons      []string    `json:"reasons,omitempty"`
+	Steps        []TraceStep `json:"steps"`
+
+	pendingAudiences *NodeResult
+}
+
+// TraceStep is a single step of a decision
+type TraceStep struct {
+	Kind    TraceStepKind `json:"kind"`
+	Service string        `json:"service"`
+	// RuleKey is the key of the rule, experiment or holdout the step is about
+	RuleKey string `json:"ruleKey,omitempty"`
+	// Matched tells whether the step passed: a forced decision or saved variation was found, the audiences matched,
+	// a variation was bucketed into or the rule decided
+	Matched      bool   `json:"matched"`
+	VariationKey string `json:"variationKey,omitempty"`
+	Reason       string `json:"reason,omitempty"`
+	// Audiences is the result of every node of the audience condition tree of an audience step
+	Audiences *NodeResult      `json:"audiences,omitempty"`
+	Bucketing *BucketingResult `json:"bucketing,omitempty"`
+}
+
+// BucketingResult describes how the bucketing ID was bucketed into a traffic allocation
+type BucketingResult struct {
+	BucketingID string `json:"bucketingId"`
+	// BucketValue is expressed in buckets of the bucketer granularity, 10000 by default
+	BucketValue int `json:"bucketValue"`
+	// RangeStart and RangeEnd bound the traffic allocation range hit, both are zero when no range was hit
+	RangeStart int    `json:"rangeStart"`
+	RangeEnd   int    `json:"rangeEnd"`
+	EntityID   string `json:"entityId,omitempty"`
+	// GroupID is set when the user was first bucketed into an experiment of a mutually exclusive group
+	GroupID string `json:"groupId,omitempty"`
+}
+
+// GetTrace returns the decision trace of the options, nil when the options or the trace are nil
+func (o *Options) GetTrace() *DecisionTrace {
+	if o == nil {
+		return nil
+	}
+	return o.Trace
+}
+
+// AddStep records a step, an audience step is given the audience condition tree result recorded since the previous step
+func (t *DecisionTrace) AddStep(step TraceStep) {
+	if t == nil {
+		return
+	}
+	if step.Kind == TraceAudience && step.Audiences == nil {
+		step.Audiences = t.pendingAudiences
+	}
+	t.pendingAudiences = nil
+	t.Steps = append(t.Steps, step)
+}
+
+// SetAudienceResult records the result of an audience condition tree for the next audience step
+func (t *DecisionTrace) SetAudienceResult(result *NodeResult) {
+	if t == nil {
+		return
+	}
+	t.pendingAudiences = result
+}
@@ -0,0 +1,58 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package decide
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecisionTraceAttributesAudienceResultToAudienceStep(t *testing.T) {
+	trace := &DecisionTrace{}
+	matched := true
+	result := &NodeResult{Operator: "or", Result: &matched, Nodes: []*NodeResult{
+		{AudienceID: "a1", Result: &matched, Nodes: []*NodeResult{{Name: "country", Result: &matched}}},
+	}}
+
+	trace.SetAudienceResult(result)
+	trace.AddStep(TraceStep{Kind: TraceAudience, RuleKey: "rule"})
+	trace.AddStep(TraceStep{Kind: TraceRule, RuleKey: "rule"})
+
+	assert.Same(t, result, trace.Steps[0].Audiences)
+	assert.Nil(t, trace.Steps[1].Audiences)
+}
+
+func TestDecisionTraceDropsAudienceResultOfOtherSteps(t *testing.T) {
+	trace := &DecisionTrace{}
+	trace.SetAudienceResult(&NodeResult{Name: "stale"})
+	trace.AddStep(TraceStep{Kind: TraceBucketing})
+	trace.AddStep(TraceStep{Kind: TraceAudience})
+	assert.Nil(t, trace.Steps[1].Audiences)
+}
+
+func TestNilDecisionTrace(t *testing.T) {
+	var options *Options
+	assert.Nil(t, options.GetTrace())
+	assert.Nil(t, (&Options{}).GetTrace())
+
+	var trace *DecisionTrace
+	assert.NotPanics(t, func() {
+		trace.SetAudienceResult(&NodeResult{})
+		trace.AddStep(TraceStep{})
+	})
+}
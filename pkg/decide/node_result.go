@@ -0,0 +1,58 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package decide //
+package decide
+
+// NullReason tells why a node evaluated to null
+type NullReason string
+
+const (
+	// NullMissingAttribute is used when the user does not have the attribute of the condition
+	NullMissingAttribute NullReason = "missing_attribute"
+	// NullTypeMismatch is used when the attribute or the condition value has a type the matcher does not support
+	NullTypeMismatch NullReason = "type_mismatch"
+	// NullUnknownMatchType is used when no matcher is registered for the condition match type
+	NullUnknownMatchType NullReason = "unknown_match_type"
+	// NullUnknownConditionType is used when the condition type is not supported
+	NullUnknownConditionType NullReason = "unknown_condition_type"
+	// NullUnknownAudience is used when a referenced audience does not exist
+	NullUnknownAudience NullReason = "unknown_audience"
+	// NullChild is used when an and, or or not node evaluated to null because of a null child
+	NullChild NullReason = "null_child"
+)
+
+// NodeResult is the result of evaluating a node of an audience condition tree, it mirrors entities.TreeNode. Operator
+// nodes carry the results of their children, condition leaves the matcher used and the attribute value seen, and
+// audience leaves the result of the audience condition tree as their only child.
+type NodeResult struct {
+	Operator       string      `json:"operator,omitempty"`
+	AudienceID     string      `json:"audienceId,omitempty"`
+	Name           string      `json:"name,omitempty"`
+	Type           string      `json:"type,omitempty"`
+	Matcher        string      `json:"matcher,omitempty"`
+	Value          interface{} `json:"value,omitempty"`
+	AttributeValue interface{} `json:"attributeValue,omitempty"`
+	// Result is nil when the node evaluated to null
+	Result     *bool         `json:"result"`
+	NullReason NullReason    `json:"nullReason,omitempty"`
+	Nodes      []*NodeResult `json:"nodes,omitempty"`
+}
+
+// IsNull returns whether the node evaluated to null
+func (r NodeResult) IsNull() bool {
+	return r.Result == nil
+}
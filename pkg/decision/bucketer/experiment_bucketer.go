@@ -18,6 +18,8 @@
 package bucketer
 
 import (
+	"strconv"
+
 	"github.com/optimizely/go-sdk/v2/pkg/decision/reasons"
 	"github.com/optimizely/go-sdk/v2/pkg/entities"
 	"github.com/optimizely/go-sdk/v2/pkg/logging"
@@ -63,6 +65,13 @@ func NewMurmurhashExperimentBucketer(logger logging.OptimizelyLogProducer, hashS
 	}
 }
 
+// NewExperimentBucketer returns an experiment bucketer generating bucketing values with the given bucketer
+func NewExperimentBucketer(bucketer Bucketer) *MurmurhashExperimentBucketer {
+	return &MurmurhashExperimentBucketer{
+		bucketer: bucketer,
+	}
+}
+
 // Bucket buckets the user into the given experiment
 func (b MurmurhashExperimentBucketer) Bucket(bucketingID string, experiment entities.Experiment, group entities.Group) (*entities.Variation, reasons.Reason, error) {
 	if experiment.GroupID != "" && group.Policy == "random" {
@@ -87,3 +96,19 @@ func (b MurmurhashExperimentBucketer) Bucket(bucketingID string, experiment enti
 
 	return nil, reasons.BucketedVariationNotFound, nil
 }
+
+// LocateBucket returns the bucketing value of the bucket key and the index of the traffic allocation range it falls in,
+// -1 when it falls in none. The range is found by BucketToEntity so that it matches the bucketing of any Bucketer.
+func (b MurmurhashExperimentBucketer) LocateBucket(bucketKey string, trafficAllocation []entities.Range) (bucketValue, rangeIndex int) {
+	indexedAllocation := make([]entities.Range, len(trafficAllocation))
+	for index, trafficAllocationRange := range trafficAllocation {
+		indexedAllocation[index] = entities.Range{EntityID: strconv.Itoa(index), EndOfRange: trafficAllocationRange.EndOfRange}
+	}
+
+	bucketValue = b.bucketer.Generate(bucketKey)
+	rangeIndex, err := strconv.Atoi(b.bucketer.BucketToEntity(bucketKey, indexedAllocation))
+	if err != nil {
+		return bucketValue, -1
+	}
+	return bucketValue, rangeIndex
+}
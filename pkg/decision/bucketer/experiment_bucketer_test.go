@@ -58,3 +58,43 @@ func TestBucketExclusionGroups(t *testing.T) {
 	assert.Nil(t, bucketedVariation)
 	assert.Equal(t, reasons.NotBucketedIntoVariation, reason)
 }
+
+func TestLocateBucket(t *testing.T) {
+	trafficAllocation := []entities.Range{
+		{EntityID: "22222", EndOfRange: 4999},
+		{EntityID: "22223", EndOfRange: 10000},
+	}
+
+	bucketer := NewMurmurhashExperimentBucketer(logging.GetLogger("", "TestLocateBucket"), DefaultHashSeed)
+	// ppid2 + 1886780722 generates a bucket value of 2434
+	bucketValue, rangeIndex := bucketer.LocateBucket("ppid21886780722", trafficAllocation)
+	assert.Equal(t, 2434, bucketValue)
+	assert.Equal(t, 0, rangeIndex)
+
+	_, rangeIndex = bucketer.LocateBucket("ppid21886780722", trafficAllocation[:0])
+	assert.Equal(t, -1, rangeIndex)
+
+	fineBucketer := NewExperimentBucketer(NewHashBucketer(Murmur3Hash, DefaultHashSeed, 1000000))
+	bucketValue, rangeIndex = fineBucketer.LocateBucket("ppid21886780722", trafficAllocation)
+	assert.Equal(t, 2434, bucketValue/100)
+	assert.Equal(t, 0, rangeIndex)
+
+	// the range of a custom bucketer is the one it buckets into
+	customBucketer := NewExperimentBucketer(lastRangeBucketer{})
+	bucketValue, rangeIndex = customBucketer.LocateBucket("ppid21886780722", trafficAllocation)
+	assert.Equal(t, 42, bucketValue)
+	assert.Equal(t, 1, rangeIndex)
+}
+
+type lastRangeBucketer struct{}
+
+func (lastRangeBucketer) Generate(bucketingKey string) int {
+	return 42
+}
+
+func (lastRangeBucketer) BucketToEntity(bucketKey string, trafficAllocations []entities.Range) string {
+	if len(trafficAllocations) == 0 {
+		return ""
+	}
+	return trafficAllocations[len(trafficAllocations)-1].EntityID
+}
@@ -0,0 +1,134 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package bucketer //
+package bucketer
+
+import (
+	"encoding/binary"
+	"math/bits"
+
+	"github.com/optimizely/go-sdk/v2/pkg/entities"
+
+	"github.com/twmb/murmur3"
+)
+
+// DefaultBucketGranularity is the number of buckets traffic allocation ranges are expressed in
+const DefaultBucketGranularity = maxTrafficValue
+
+// HashFunc returns the 32 bit hash of a bucketing key for the given seed
+type HashFunc func(bucketingKey []byte, seed uint32) uint32
+
+// Murmur3Hash is the MurmurHash3 x86 32 bit hash, the algorithm used by every Optimizely SDK
+func Murmur3Hash(bucketingKey []byte, seed uint32) uint32 {
+	return murmur3.SeedSum32(seed, bucketingKey)
+}
+
+// HashBucketer generates bucketing values with a pluggable hash function and bucket granularity
+type HashBucketer struct {
+	hash        HashFunc
+	hashSeed    uint32
+	granularity int
+}
+
+// NewHashBucketer returns a bucketer hashing into the given number of buckets. Traffic allocation ranges are
+// expressed in DefaultBucketGranularity buckets and are scaled to the granularity, so a finer granularity only
+// changes how precisely a range boundary is honored. A granularity below 1 uses DefaultBucketGranularity.
+func NewHashBucketer(hash HashFunc, hashSeed uint32, granularity int) *HashBucketer {
+	if granularity < 1 {
+		granularity = DefaultBucketGranularity
+	}
+	return &HashBucketer{hash: hash, hashSeed: hashSeed, granularity: granularity}
+}
+
+// NewXXHashBucketer returns a bucketer using the xxHash32 algorithm with the default granularity
+func NewXXHashBucketer(hashSeed uint32) *HashBucketer {
+	return NewHashBucketer(XXHash32, hashSeed, DefaultBucketGranularity)
+}
+
+// Generate returns a bucketing value between 0 and the granularity for the bucketing key
+func (b HashBucketer) Generate(bucketingKey string) int {
+	hashCode := b.hash([]byte(bucketingKey), b.hashSeed)
+	return int(uint64(hashCode) * uint64(b.granularity) >> 32)
+}
+
+// BucketToEntity buckets into a traffic against given bucketKey
+func (b HashBucketer) BucketToEntity(bucketKey string, trafficAllocations []entities.Range) (entityID string) {
+	bucketValue := int64(b.Generate(bucketKey))
+
+	for _, trafficAllocationRange := range trafficAllocations {
+		// same as bucketValue < EndOfRange scaled to the granularity, without losing precision
+		if bucketValue*maxTrafficValue < int64(trafficAllocationRange.EndOfRange)*int64(b.granularity) {
+			return trafficAllocationRange.EntityID
+		}
+	}
+
+	return ""
+}
+
+const (
+	xxPrime32n1 uint32 = 2654435761
+	xxPrime32n2 uint32 = 2246822519
+	xxPrime32n3 uint32 = 3266489917
+	xxPrime32n4 uint32 = 668265263
+	xxPrime32n5 uint32 = 374761393
+)
+
+// XXHash32 is the xxHash 32 bit hash
+func XXHash32(input []byte, seed uint32) uint32 {
+	n := len(input)
+	var h uint32
+
+	if n >= 16 {
+		v1 := seed + xxPrime32n1 + xxPrime32n2
+		v2 := seed + xxPrime32n2
+		v3 := seed
+		v4 := seed - xxPrime32n1
+		for len(input) >= 16 {
+			v1 = xxRound32(v1, binary.LittleEndian.Uint32(input[0:4]))
+			v2 = xxRound32(v2, binary.LittleEndian.Uint32(input[4:8]))
+			v3 = xxRound32(v3, binary.LittleEndian.Uint32(input[8:12]))
+			v4 = xxRound32(v4, binary.LittleEndian.Uint32(input[12:16]))
+			input = input[16:]
+		}
+		h = bits.RotateLeft32(v1, 1) + bits.RotateLeft32(v2, 7) + bits.RotateLeft32(v3, 12) + bits.RotateLeft32(v4, 18)
+	} else {
+		h = seed + xxPrime32n5
+	}
+
+	h += uint32(n)
+	for ; len(input) >= 4; input = input[4:] {
+		h += binary.LittleEndian.Uint32(input[0:4]) * xxPrime32n3
+		h = bits.RotateLeft32(h, 17) * xxPrime32n4
+	}
+	for ; len(input) > 0; input = input[1:] {
+		h += uint32(input[0]) * xxPrime32n5
+		h = bits.RotateLeft32(h, 11) * xxPrime32n1
+	}
+
+	h ^= h >> 15
+	h *= xxPrime32n2
+	h ^= h >> 13
+	h *= xxPrime32n3
+	h ^= h >> 16
+	return h
+}
+
+func xxRound32(acc, input uint32) uint32 {
+	acc += input * xxPrime32n2
+	acc = bits.RotateLeft32(acc, 13)
+	return acc * xxPrime32n1
+}
@@ -0,0 +1,110 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package bucketer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/optimizely/go-sdk/v2/pkg/entities"
+	"github.com/optimizely/go-sdk/v2/pkg/logging"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestXXHash32(t *testing.T) {
+	// reference values of the xxHash specification implementation
+	assert.Equal(t, uint32(0x02cc5d05), XXHash32([]byte(""), 0))
+	assert.Equal(t, uint32(0x550d7456), XXHash32([]byte("a"), 0))
+	assert.Equal(t, uint32(0x32d153ff), XXHash32([]byte("abc"), 0))
+	assert.Equal(t, uint32(0x63a14d5f), XXHash32([]byte("abcdefghijklmnopqrstuvwxyz"), 0))
+}
+
+func TestHashBucketerMatchesMurmurhashBucketer(t *testing.T) {
+	murmurhashBucketer := NewMurmurhashBucketer(logging.GetLogger("", "TestHashBucketer"), DefaultHashSeed)
+	hashBucketer := NewHashBucketer(Murmur3Hash, DefaultHashSeed, DefaultBucketGranularity)
+
+	trafficAlloc := []entities.Range{{EntityID: "a", EndOfRange: 2500}, {EntityID: "b", EndOfRange: 5000}, {EntityID: "c", EndOfRange: 10000}}
+	for i := 0; i < 1000; i++ {
+		bucketingKey := fmt.Sprintf("user%d1886780721", i)
+		assert.Equal(t, murmurhashBucketer.Generate(bucketingKey), hashBucketer.Generate(bucketingKey))
+		assert.Equal(t, murmurhashBucketer.BucketToEntity(bucketingKey, trafficAlloc), hashBucketer.BucketToEntity(bucketingKey, trafficAlloc))
+	}
+}
+
+func TestHashBucketerGranularity(t *testing.T) {
+	coarse := NewHashBucketer(XXHash32, DefaultHashSeed, DefaultBucketGranularity)
+	fine := NewHashBucketer(XXHash32, DefaultHashSeed, 1000000)
+
+	trafficAlloc := []entities.Range{{EntityID: "a", EndOfRange: 5000}, {EntityID: "b", EndOfRange: 10000}}
+	for i := 0; i < 1000; i++ {
+		bucketingKey := fmt.Sprintf("user%d", i)
+		value := fine.Generate(bucketingKey)
+		assert.True(t, value >= 0 && value < 1000000)
+		assert.Equal(t, coarse.Generate(bucketingKey), value/100)
+		assert.Equal(t, coarse.BucketToEntity(bucketingKey, trafficAlloc), fine.BucketToEntity(bucketingKey, trafficAlloc))
+	}
+
+	assert.Equal(t, DefaultBucketGranularity, NewHashBucketer(XXHash32, 0, 0).granularity)
+}
+
+func TestXXHashBucketerDistribution(t *testing.T) {
+	bucketer := NewXXHashBucketer(DefaultHashSeed)
+	trafficAlloc := []entities.Range{{EntityID: "a", EndOfRange: 5000}, {EntityID: "b", EndOfRange: 10000}}
+
+	counts := map[string]int{}
+	for i := 0; i < 10000; i++ {
+		counts[bucketer.BucketToEntity(fmt.Sprintf("user%d", i), trafficAlloc)]++
+	}
+	assert.InDelta(t, 5000, counts["a"], 250)
+	assert.InDelta(t, 5000, counts["b"], 250)
+}
+
+func TestNewExperimentBucketer(t *testing.T) {
+	experimentBucketer := NewExperimentBucketer(NewXXHashBucketer(DefaultHashSeed))
+	experiment := entities.Experiment{
+		ID:                "1886780721",
+		Variations:        map[string]entities.Variation{"v1": {ID: "v1", Key: "control"}},
+		TrafficAllocation: []entities.Range{{EntityID: "v1", EndOfRange: 10000}},
+	}
+
+	variation, _, err := experimentBucketer.Bucket("ppid1", experiment, entities.Group{})
+	assert.NoError(t, err)
+	assert.Equal(t, "control", variation.Key)
+}
+
+func benchmarkBucketer(b *testing.B, bucketer Bucketer) {
+	bucketingKey := "user_" + strings.Repeat("x", 24) + "1886780721"
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bucketer.Generate(bucketingKey)
+	}
+}
+
+func BenchmarkMurmurhashBucketer(b *testing.B) {
+	benchmarkBucketer(b, NewMurmurhashBucketer(logging.GetLogger("", "BenchmarkMurmurhashBucketer"), DefaultHashSeed))
+}
+
+func BenchmarkHashBucketerMurmur3(b *testing.B) {
+	benchmarkBucketer(b, NewHashBucketer(Murmur3Hash, DefaultHashSeed, DefaultBucketGranularity))
+}
+
+func BenchmarkHashBucketerXXHash(b *testing.B) {
+	benchmarkBucketer(b, NewXXHashBucketer(DefaultHashSeed))
+}
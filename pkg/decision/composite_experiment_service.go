@@ -20,6 +20,9 @@ package decision
 import (
 	"github.com/optimizely/go-sdk/v2/pkg/cmab"
 	"github.com/optimizely/go-sdk/v2/pkg/decide"
+	"github.com/optimizely/go-sdk/v2/pkg/decision/bucketer"
+	"github.com/optimizely/go-sdk/v2/pkg/decision/evaluator"
+	"github.com/optimizely/go-sdk/v2/pkg/decision/evaluator/matchers"
 	"github.com/optimizely/go-sdk/v2/pkg/entities"
 	"github.com/optimizely/go-sdk/v2/pkg/logging"
 )
@@ -48,12 +51,45 @@ func WithCmabConfig(config *cmab.Config) CESOptionFunc {
 	}
 }
 
+// WithCmabService sets the service making CMAB decisions. It takes precedence over WithCmabConfig.
+func WithCmabService(cmabService cmab.Service) CESOptionFunc {
+	return func(f *CompositeExperimentService) {
+		f.cmabService = cmabService
+	}
+}
+
+// WithBucketer sets the bucketer used to bucket users into experiments, defaults to MurmurHash3
+func WithBucketer(experimentBucketer bucketer.Bucketer) CESOptionFunc {
+	return func(f *CompositeExperimentService) {
+		f.bucketer = experimentBucketer
+	}
+}
+
+// WithMatcherRegistry sets the registry of the matchers used to evaluate experiment audiences, defaults to the global registry
+func WithMatcherRegistry(registry *matchers.MatcherRegistry) CESOptionFunc {
+	return func(f *CompositeExperimentService) {
+		f.matcherRegistry = registry
+	}
+}
+
+// WithAudienceEvaluator sets the evaluator of experiment audiences, defaults to a CompiledTreeEvaluator. It takes
+// precedence over WithMatcherRegistry.
+func WithAudienceEvaluator(audienceEvaluator evaluator.TreeEvaluator) CESOptionFunc {
+	return func(f *CompositeExperimentService) {
+		f.audienceEvaluator = audienceEvaluator
+	}
+}
+
 // CompositeExperimentService bridges together the various experiment decision services that ship by default with the SDK
 type CompositeExperimentService struct {
 	experimentServices []ExperimentService
 	overrideStore      ExperimentOverrideStore
 	userProfileService UserProfileService
 	cmabConfig         *cmab.Config
+	cmabService        cmab.Service
+	bucketer           bucketer.Bucketer
+	matcherRegistry    *matchers.MatcherRegistry
+	audienceEvaluator  evaluator.TreeEvaluator
 	logger             logging.OptimizelyLogProducer
 }
 
@@ -89,9 +125,21 @@ func NewCompositeExperimentService(sdkKey string, options ...CESOptionFunc) *Com
 
 	// Create CMAB service with config
 	experimentCmabService := NewExperimentCmabService(sdkKey, compositeExperimentService.cmabConfig)
+	if compositeExperimentService.cmabService != nil {
+		experimentCmabService.cmabService = compositeExperimentService.cmabService
+	}
 	experimentServices = append(experimentServices, experimentCmabService)
 
 	experimentBucketerService := NewExperimentBucketerService(logging.GetLogger(sdkKey, "ExperimentBucketerService"))
+	if compositeExperimentService.bucketer != nil {
+		experimentCmabService.bucketer = bucketer.NewExperimentBucketer(compositeExperimentService.bucketer)
+		experimentBucketerService.bucketer = bucketer.NewExperimentBucketer(compositeExperimentService.bucketer)
+	}
+	if compositeExperimentService.audienceEvaluator == nil {
+		compositeExperimentService.audienceEvaluator = evaluator.NewCompiledTreeEvaluator(logging.GetLogger(sdkKey, "AudienceEvaluator"), compositeExperimentService.matcherRegistry)
+	}
+	experimentCmabService.audienceTreeEvaluator = compositeExperimentService.audienceEvaluator
+	experimentBucketerService.audienceTreeEvaluator = compositeExperimentService.audienceEvaluator
 	if compositeExperimentService.userProfileService != nil {
 		persistingExperimentService := NewPersistingExperimentService(compositeExperimentService.userProfileService, experimentBucketerService, logging.GetLogger(sdkKey, "PersistingExperimentService"))
 		experimentServices = append(experimentServices, persistingExperimentService)
@@ -26,6 +26,7 @@ import (
 
 	"github.com/optimizely/go-sdk/v2/pkg/cmab"
 	"github.com/optimizely/go-sdk/v2/pkg/decide"
+	"github.com/optimizely/go-sdk/v2/pkg/decision/bucketer"
 	"github.com/optimizely/go-sdk/v2/pkg/entities"
 	"github.com/optimizely/go-sdk/v2/pkg/logging"
 )
@@ -246,6 +247,15 @@ func (s *CompositeExperimentTestSuite) TestNewCompositeExperimentServiceWithCust
 	s.IsType(&PersistingExperimentService{}, compositeExperimentService.experimentServices[3])
 }
 
+func (s *CompositeExperimentTestSuite) TestNewCompositeExperimentServiceWithBucketer() {
+	xxHashBucketer := bucketer.NewXXHashBucketer(bucketer.DefaultHashSeed)
+	compositeExperimentService := NewCompositeExperimentService("", WithBucketer(xxHashBucketer))
+
+	expected := bucketer.NewExperimentBucketer(xxHashBucketer)
+	s.Equal(expected, compositeExperimentService.experimentServices[1].(*ExperimentCmabService).bucketer)
+	s.Equal(expected, compositeExperimentService.experimentServices[2].(*ExperimentBucketerService).bucketer)
+}
+
 func (s *CompositeExperimentTestSuite) TestNewCompositeExperimentServiceWithCmabConfig() {
 	// Test with custom CMAB config
 	cmabConfig := cmab.Config{
@@ -19,6 +19,8 @@ package decision
 
 import (
 	"github.com/optimizely/go-sdk/v2/pkg/decide"
+	"github.com/optimizely/go-sdk/v2/pkg/decision/bucketer"
+	"github.com/optimizely/go-sdk/v2/pkg/decision/evaluator"
 	"github.com/optimizely/go-sdk/v2/pkg/entities"
 	"github.com/optimizely/go-sdk/v2/pkg/logging"
 )
@@ -32,13 +34,29 @@ type CompositeFeatureService struct {
 
 // NewCompositeFeatureService returns a new instance of the CompositeFeatureService
 func NewCompositeFeatureService(sdkKey string, compositeExperimentService ExperimentService) *CompositeFeatureService {
-	holdoutService := NewHoldoutService(sdkKey)
+	return newCompositeFeatureService(sdkKey, compositeExperimentService, nil, nil)
+}
+
+// newCompositeFeatureService returns a CompositeFeatureService bucketing holdouts and rollouts with the given bucketer and
+// evaluating their audiences with the given evaluator, the default ones when nil
+func newCompositeFeatureService(sdkKey string, compositeExperimentService ExperimentService, featureBucketer bucketer.Bucketer, audienceEvaluator evaluator.TreeEvaluator) *CompositeFeatureService {
+	var holdoutOptions []HSOptionFunc
+	var rolloutOptions []RSOptionFunc
+	if featureBucketer != nil {
+		holdoutOptions = append(holdoutOptions, WithHoldoutBucketer(featureBucketer))
+		rolloutOptions = append(rolloutOptions, WithRolloutBucketer(featureBucketer))
+	}
+	if audienceEvaluator != nil {
+		holdoutOptions = append(holdoutOptions, WithHoldoutAudienceEvaluator(audienceEvaluator))
+		rolloutOptions = append(rolloutOptions, WithRolloutAudienceEvaluator(audienceEvaluator))
+	}
+	holdoutService := NewHoldoutService(sdkKey, holdoutOptions...)
 	return &CompositeFeatureService{
 		holdoutService: holdoutService,
 		logger:         logging.GetLogger(sdkKey, "CompositeFeatureService"),
 		featureServices: []FeatureService{
 			NewFeatureExperimentService(logging.GetLogger(sdkKey, "FeatureExperimentService"), compositeExperimentService, holdoutService),
-			NewRolloutService(sdkKey),
+			NewRolloutService(sdkKey, rolloutOptions...),
 		},
 	}
 }
@@ -21,6 +21,9 @@ import (
 	"fmt"
 
 	"github.com/optimizely/go-sdk/v2/pkg/decide"
+	"github.com/optimizely/go-sdk/v2/pkg/decision/bucketer"
+	"github.com/optimizely/go-sdk/v2/pkg/decision/evaluator"
+	"github.com/optimizely/go-sdk/v2/pkg/decision/evaluator/matchers"
 	"github.com/optimizely/go-sdk/v2/pkg/entities"
 	"github.com/optimizely/go-sdk/v2/pkg/logging"
 	"github.com/optimizely/go-sdk/v2/pkg/notification"
@@ -31,6 +34,9 @@ import (
 type CompositeService struct {
 	compositeExperimentService ExperimentService
 	compositeFeatureService    FeatureService
+	bucketer                   bucketer.Bucketer
+	matcherRegistry            *matchers.MatcherRegistry
+	audienceEvaluator          evaluator.TreeEvaluator
 	notificationCenter         notification.Center
 	logger                     logging.OptimizelyLogProducer
 }
@@ -45,6 +51,32 @@ func WithCompositeExperimentService(compositeExperimentService ExperimentService
 	}
 }
 
+// WithCompositeBucketer sets the bucketer used for experiments, rollouts and holdouts, defaults to MurmurHash3.
+// It does not apply to a composite experiment service provided with WithCompositeExperimentService.
+func WithCompositeBucketer(compositeBucketer bucketer.Bucketer) CSOptionFunc {
+	return func(f *CompositeService) {
+		f.bucketer = compositeBucketer
+	}
+}
+
+// WithCompositeMatcherRegistry sets the registry of the matchers used to evaluate the audiences of experiments, rollouts
+// and holdouts, defaults to the global registry. It does not apply to a composite experiment service provided with
+// WithCompositeExperimentService.
+func WithCompositeMatcherRegistry(registry *matchers.MatcherRegistry) CSOptionFunc {
+	return func(f *CompositeService) {
+		f.matcherRegistry = registry
+	}
+}
+
+// WithCompositeAudienceEvaluator sets the evaluator of the audiences of experiments, rollouts and holdouts, defaults to
+// a CompiledTreeEvaluator shared by all of them. It takes precedence over WithCompositeMatcherRegistry and does not
+// apply to a composite experiment service provided with WithCompositeExperimentService.
+func WithCompositeAudienceEvaluator(audienceEvaluator evaluator.TreeEvaluator) CSOptionFunc {
+	return func(f *CompositeService) {
+		f.audienceEvaluator = audienceEvaluator
+	}
+}
+
 // NewCompositeService returns a new instance of the CompositeService with the defaults
 func NewCompositeService(sdkKey string, options ...CSOptionFunc) *CompositeService {
 	compositeService := &CompositeService{
@@ -56,10 +88,20 @@ func NewCompositeService(sdkKey string, options ...CSOptionFunc) *CompositeServi
 		opts(compositeService)
 	}
 
+	if compositeService.audienceEvaluator == nil {
+		compositeService.audienceEvaluator = evaluator.NewCompiledTreeEvaluator(logging.GetLogger(sdkKey, "AudienceEvaluator"), compositeService.matcherRegistry)
+	}
 	if compositeService.compositeExperimentService == nil {
-		compositeService.compositeExperimentService = NewCompositeExperimentService(sdkKey)
+		experimentServiceOptions := []CESOptionFunc{WithAudienceEvaluator(compositeService.audienceEvaluator)}
+		if compositeService.bucketer != nil {
+			experimentServiceOptions = append(experimentServiceOptions, WithBucketer(compositeService.bucketer))
+		}
+		if compositeService.matcherRegistry != nil {
+			experimentServiceOptions = append(experimentServiceOptions, WithMatcherRegistry(compositeService.matcherRegistry))
+		}
+		compositeService.compositeExperimentService = NewCompositeExperimentService(sdkKey, experimentServiceOptions...)
 	}
-	compositeService.compositeFeatureService = NewCompositeFeatureService(sdkKey, compositeService.compositeExperimentService)
+	compositeService.compositeFeatureService = newCompositeFeatureService(sdkKey, compositeService.compositeExperimentService, compositeService.bucketer, compositeService.audienceEvaluator)
 
 	return compositeService
 }
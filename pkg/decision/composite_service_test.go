@@ -20,10 +20,14 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 
 	"github.com/optimizely/go-sdk/v2/pkg/decide"
+	"github.com/optimizely/go-sdk/v2/pkg/decision/bucketer"
+	"github.com/optimizely/go-sdk/v2/pkg/decision/evaluator"
+	"github.com/optimizely/go-sdk/v2/pkg/decision/evaluator/matchers"
 	"github.com/optimizely/go-sdk/v2/pkg/entities"
 	"github.com/optimizely/go-sdk/v2/pkg/logging"
 	"github.com/optimizely/go-sdk/v2/pkg/notification"
@@ -286,3 +290,49 @@ func TestCompositeServiceTestSuites(t *testing.T) {
 	suite.Run(t, new(CompositeServiceExperimentTestSuite))
 	suite.Run(t, new(CompositeServiceFeatureTestSuite))
 }
+
+func TestNewCompositeServiceWithBucketer(t *testing.T) {
+	xxHashBucketer := bucketer.NewXXHashBucketer(bucketer.DefaultHashSeed)
+	compositeService := NewCompositeService("sdk_key", WithCompositeBucketer(xxHashBucketer))
+	expected := bucketer.NewExperimentBucketer(xxHashBucketer)
+
+	compositeExperimentService := compositeService.compositeExperimentService.(*CompositeExperimentService)
+	assert.Equal(t, xxHashBucketer, compositeExperimentService.bucketer)
+
+	compositeFeatureService := compositeService.compositeFeatureService.(*CompositeFeatureService)
+	assert.Equal(t, expected, compositeFeatureService.holdoutService.bucketer)
+	rolloutService := compositeFeatureService.featureServices[1].(*RolloutService)
+	assert.Equal(t, expected, rolloutService.holdoutService.bucketer)
+	assert.Equal(t, expected, rolloutService.experimentBucketerService.(*ExperimentBucketerService).bucketer)
+}
+
+func TestNewCompositeServiceWithMatcherRegistry(t *testing.T) {
+	registry := matchers.NewMatcherRegistry()
+	registry.Register("scoped", func(entities.Condition, entities.UserContext, logging.OptimizelyLogProducer) (bool, error) {
+		return true, nil
+	})
+	compositeService := NewCompositeService("sdk_key", WithCompositeMatcherRegistry(registry))
+
+	// evaluating a condition with the scoped matcher is only valid when the registry was passed down
+	usesRegistry := func(audienceTreeEvaluator evaluator.TreeEvaluator) bool {
+		user := entities.UserContext{}
+		tree := &entities.TreeNode{Item: entities.Condition{Type: "custom_attribute", Match: "scoped", Name: "attr"}}
+		result, isValid, _ := audienceTreeEvaluator.Evaluate(tree, entities.NewTreeParameters(&user, nil), &decide.Options{})
+		return result && isValid
+	}
+
+	compositeExperimentService := compositeService.compositeExperimentService.(*CompositeExperimentService)
+	assert.Equal(t, registry, compositeExperimentService.matcherRegistry)
+	assert.True(t, usesRegistry(compositeExperimentService.experimentServices[1].(*ExperimentCmabService).audienceTreeEvaluator))
+	assert.True(t, usesRegistry(compositeExperimentService.experimentServices[2].(*ExperimentBucketerService).audienceTreeEvaluator))
+
+	compositeFeatureService := compositeService.compositeFeatureService.(*CompositeFeatureService)
+	assert.True(t, usesRegistry(compositeFeatureService.holdoutService.audienceTreeEvaluator))
+	rolloutService := compositeFeatureService.featureServices[1].(*RolloutService)
+	assert.True(t, usesRegistry(rolloutService.audienceTreeEvaluator))
+	assert.True(t, usesRegistry(rolloutService.holdoutService.audienceTreeEvaluator))
+	assert.True(t, usesRegistry(rolloutService.experimentBucketerService.(*ExperimentBucketerService).audienceTreeEvaluator))
+
+	assert.False(t, usesRegistry(NewHoldoutService("sdk_key").audienceTreeEvaluator))
+	assert.True(t, usesRegistry(NewHoldoutService("sdk_key", WithHoldoutMatcherRegistry(registry)).audienceTreeEvaluator))
+}
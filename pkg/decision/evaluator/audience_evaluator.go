@@ -26,6 +26,22 @@ import (
 	"github.com/optimizely/go-sdk/v2/pkg/logging"
 )
 
+// EvaluateTree returns whether the user satisfies the given condition tree, an invalid evaluation does not. When the
+// options carry a decision trace and the evaluator is a ResultTreeEvaluator, the result of every node is recorded for
+// the next audience step of the trace.
+func EvaluateTree(audienceEvaluator TreeEvaluator, node *entities.TreeNode, condTreeParams *entities.TreeParameters,
+	options *decide.Options) (bool, decide.DecisionReasons) {
+	if trace := options.GetTrace(); trace != nil {
+		if resultEvaluator, ok := audienceEvaluator.(ResultTreeEvaluator); ok {
+			result, reasons := resultEvaluator.EvaluateWithResults(node, condTreeParams, options)
+			trace.SetAudienceResult(result)
+			return !result.IsNull() && *result.Result, reasons
+		}
+	}
+	evalResult, _, reasons := audienceEvaluator.Evaluate(node, condTreeParams, options)
+	return evalResult, reasons
+}
+
 // CheckIfUserInAudience evaluates if user meets experiment audience conditions
 func CheckIfUserInAudience(experiment *entities.Experiment, userContext entities.UserContext, projectConfig config.ProjectConfig, audienceEvaluator TreeEvaluator, options *decide.Options, logger logging.OptimizelyLogProducer) (bool, decide.DecisionReasons) {
 	decisionReasons := decide.NewDecisionReasons(options)
@@ -40,7 +56,7 @@ func CheckIfUserInAudience(experiment *entities.Experiment, userContext entities
 		condTreeParams := entities.NewTreeParameters(&userContext, projectConfig.GetAudienceMap())
 		logger.Debug(fmt.Sprintf("Evaluating audiences for experiment %q.", experiment.Key))
 
-		evalResult, _, audienceReasons := audienceEvaluator.Evaluate(experiment.AudienceConditionTree, condTreeParams, options)
+		evalResult, audienceReasons := EvaluateTree(audienceEvaluator, experiment.AudienceConditionTree, condTreeParams, options)
 		decisionReasons.Append(audienceReasons)
 
 		logMessage := decisionReasons.AddInfo("Audiences for experiment %s collectively evaluated to %v.", experiment.Key, evalResult)
@@ -0,0 +1,262 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package evaluator //
+package evaluator
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"github.com/optimizely/go-sdk/v2/pkg/decide"
+	"github.com/optimizely/go-sdk/v2/pkg/decision/evaluator/matchers"
+	"github.com/optimizely/go-sdk/v2/pkg/entities"
+	"github.com/optimizely/go-sdk/v2/pkg/logging"
+)
+
+// compiledNode evaluates a compiled tree node, isValid is false when the node evaluated to null
+type compiledNode func(user *entities.UserContext) (evalResult, isValid bool)
+
+// CompiledTreeEvaluator evaluates condition trees compiled into closures. Conditions are compiled with the matcher
+// compilers of the registry and audience references are resolved once per audience map, that is once per
+// ProjectConfig revision, so that evaluating a tree does not allocate. Evaluations including reasons and the ones
+// returning the result of every node are handed to a MixedTreeEvaluator.
+type CompiledTreeEvaluator struct {
+	interpreted *MixedTreeEvaluator
+	logger      logging.OptimizelyLogProducer
+	registry    *matchers.MatcherRegistry
+	// current holds the *compiledAudiences of the audience map evaluated last
+	current atomic.Value
+}
+
+// NewCompiledTreeEvaluator creates a condition tree evaluator compiling conditions with the matchers of the given
+// registry, the default one when nil
+func NewCompiledTreeEvaluator(logger logging.OptimizelyLogProducer, registry *matchers.MatcherRegistry) *CompiledTreeEvaluator {
+	return &CompiledTreeEvaluator{
+		interpreted: NewMixedTreeEvaluatorWithRegistry(logger, registry),
+		logger:      logger,
+		registry:    registry,
+	}
+}
+
+// Evaluate returns whether the user satisfies the given condition tree and whether the evaluation is valid, like
+// MixedTreeEvaluator.Evaluate does
+func (c *CompiledTreeEvaluator) Evaluate(node *entities.TreeNode, condTreeParams *entities.TreeParameters, options *decide.Options) (evalResult, isValid bool, reasons decide.DecisionReasons) {
+	if condTreeParams.User == nil || (options != nil && options.IncludeReasons) {
+		return c.interpreted.Evaluate(node, condTreeParams, options)
+	}
+	evalResult, isValid = c.compiledFor(condTreeParams.AudienceMap).tree(node)(condTreeParams.User)
+	return evalResult, isValid, noReasons
+}
+
+// EvaluateWithResults returns the result of every node of the given condition tree, like
+// MixedTreeEvaluator.EvaluateWithResults does
+func (c *CompiledTreeEvaluator) EvaluateWithResults(node *entities.TreeNode, condTreeParams *entities.TreeParameters,
+	options *decide.Options) (*decide.NodeResult, decide.DecisionReasons) {
+	return c.interpreted.EvaluateWithResults(node, condTreeParams, options)
+}
+
+// compiledFor returns what was compiled for the audience map, a new audience map discards what was compiled before
+func (c *CompiledTreeEvaluator) compiledFor(audienceMap map[string]entities.Audience) *compiledAudiences {
+	if compiled, ok := c.current.Load().(*compiledAudiences); ok &&
+		reflect.ValueOf(compiled.audienceMap).Pointer() == reflect.ValueOf(audienceMap).Pointer() {
+		return compiled
+	}
+	compiled := &compiledAudiences{
+		audienceMap: audienceMap,
+		audiences:   make(map[string]compiledNode),
+		compiling:   make(map[string]bool),
+		logger:      c.logger,
+		registry:    c.registry,
+	}
+	c.current.Store(compiled)
+	return compiled
+}
+
+// compiledAudiences holds the audiences and trees compiled for an audience map, the map is kept so that its address
+// identifies it for as long as it is compiled
+type compiledAudiences struct {
+	audienceMap map[string]entities.Audience
+	trees       sync.Map // *entities.TreeNode -> compiledNode
+
+	lock      sync.Mutex
+	audiences map[string]compiledNode
+	compiling map[string]bool
+
+	logger   logging.OptimizelyLogProducer
+	registry *matchers.MatcherRegistry
+}
+
+// tree returns the compiled tree, compiling it on first use
+func (s *compiledAudiences) tree(node *entities.TreeNode) compiledNode {
+	if compiled, ok := s.trees.Load(node); ok {
+		return compiled.(compiledNode)
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	compiled := s.compileNode(node)
+	s.trees.Store(node, compiled)
+	return compiled
+}
+
+func (s *compiledAudiences) compileNode(node *entities.TreeNode) compiledNode {
+	if node == nil {
+		return nullNode
+	}
+	if node.Operator != "" {
+		children := make([]compiledNode, len(node.Nodes))
+		for i, child := range node.Nodes {
+			children[i] = s.compileNode(child)
+		}
+		switch node.Operator {
+		case andOperator:
+			return compileAnd(children)
+		case notOperator:
+			return compileNot(children)
+		default: // orOperator
+			return compileOr(children)
+		}
+	}
+
+	switch item := node.Item.(type) {
+	case entities.Condition:
+		return s.compileCondition(item)
+	case string:
+		return s.compileAudience(item)
+	default:
+		return nullNode
+	}
+}
+
+func (s *compiledAudiences) compileCondition(condition entities.Condition) compiledNode {
+	isValid := false
+	for _, validType := range validTypes {
+		if validType == condition.Type {
+			isValid = true
+			break
+		}
+	}
+	if !isValid {
+		return s.warningNode(fmt.Sprintf(logging.UnknownConditionType.String(), condition.StringRepresentation))
+	}
+
+	matchType := condition.Match
+	if matchType == "" {
+		matchType = matchers.ExactMatchType
+	}
+	matcher, ok := s.registry.Compile(matchType, condition, s.logger)
+	if !ok {
+		return s.warningNode(fmt.Sprintf(logging.UnknownMatchType.String(), condition.StringRepresentation))
+	}
+	return func(user *entities.UserContext) (bool, bool) {
+		result, err := matcher(user)
+		if err != nil {
+			return false, false
+		}
+		return result, true
+	}
+}
+
+// compileAudience resolves an audience reference, unknown audiences and cyclic references evaluate to null
+func (s *compiledAudiences) compileAudience(audienceID string) compiledNode {
+	if compiled, ok := s.audiences[audienceID]; ok {
+		return compiled
+	}
+	audience, ok := s.audienceMap[audienceID]
+	if !ok || s.compiling[audienceID] {
+		return nullNode
+	}
+	s.compiling[audienceID] = true
+	compiled := s.compileNode(audience.ConditionTree)
+	delete(s.compiling, audienceID)
+	s.audiences[audienceID] = compiled
+	return compiled
+}
+
+// warningNode evaluates to null and logs the message like the condition evaluators do for invalid conditions
+func (s *compiledAudiences) warningNode(message string) compiledNode {
+	return func(user *entities.UserContext) (bool, bool) {
+		s.logger.Warning(message)
+		return false, false
+	}
+}
+
+func compileAnd(children []compiledNode) compiledNode {
+	return func(user *entities.UserContext) (bool, bool) {
+		for _, child := range children {
+			result, isValid := child(user)
+			if !isValid {
+				return false, false
+			} else if !result {
+				return false, true
+			}
+		}
+		return true, true
+	}
+}
+
+func compileNot(children []compiledNode) compiledNode {
+	if len(children) == 0 {
+		return nullNode
+	}
+	child := children[0]
+	return func(user *entities.UserContext) (bool, bool) {
+		result, isValid := child(user)
+		if !isValid {
+			return false, false
+		}
+		return !result, true
+	}
+}
+
+func compileOr(children []compiledNode) compiledNode {
+	return func(user *entities.UserContext) (bool, bool) {
+		sawInvalid := false
+		for _, child := range children {
+			result, isValid := child(user)
+			if !isValid {
+				sawInvalid = true
+			} else if result {
+				return true, true
+			}
+		}
+		return false, !sawInvalid
+	}
+}
+
+func nullNode(*entities.UserContext) (bool, bool) {
+	return false, false
+}
+
+// noReasons is returned by compiled evaluations, which record no reasons
+var noReasons decide.DecisionReasons = discardedReasons{}
+
+// discardedReasons are decision reasons that are not reported
+type discardedReasons struct{}
+
+func (discardedReasons) AddError(format string, arguments ...interface{}) {}
+
+func (discardedReasons) AddInfo(format string, arguments ...interface{}) string {
+	return fmt.Sprintf(format, arguments...)
+}
+
+func (discardedReasons) Append(reasons decide.DecisionReasons) {}
+
+func (discardedReasons) ToReport() []string {
+	return nil
+}
@@ -0,0 +1,152 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package evaluator
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/optimizely/go-sdk/v2/pkg/decide"
+	e "github.com/optimizely/go-sdk/v2/pkg/entities"
+	"github.com/optimizely/go-sdk/v2/pkg/logging"
+)
+
+var compiledAudienceMap = map[string]e.Audience{
+	"11111": {ID: "11111", ConditionTree: &e.TreeNode{Operator: "or", Nodes: []*e.TreeNode{{Item: stringFooCondition}}}},
+	"22222": {ID: "22222", ConditionTree: &e.TreeNode{Operator: "and", Nodes: []*e.TreeNode{{Item: boolTrueCondition}, {Item: int42Condition}}}},
+	"33333": {ID: "33333", ConditionTree: &e.TreeNode{Operator: "or", Nodes: []*e.TreeNode{{Item: "11111"}, {Item: "22222"}}}},
+	// audiences referencing each other cannot be evaluated
+	"44444": {ID: "44444", ConditionTree: &e.TreeNode{Operator: "and", Nodes: []*e.TreeNode{{Item: "55555"}}}},
+	"55555": {ID: "55555", ConditionTree: &e.TreeNode{Operator: "and", Nodes: []*e.TreeNode{{Item: "44444"}}}},
+}
+
+func TestCompiledTreeEvaluatorEvaluatesLikeMixedTreeEvaluator(t *testing.T) {
+	unknownMatch := e.Condition{Type: "custom_attribute", Match: "unknown", Name: "string_foo", Value: "foo"}
+	unknownType := e.Condition{Type: "invalid", Name: "string_foo", Value: "foo"}
+	trees := []*e.TreeNode{
+		{Operator: "or", Nodes: []*e.TreeNode{{Item: stringFooCondition}}},
+		{Operator: "and", Nodes: []*e.TreeNode{{Item: boolTrueCondition}, {Item: int42Condition}}},
+		{Operator: "and", Nodes: []*e.TreeNode{{Item: int42Condition}, {Item: stringFooCondition}}},
+		{Operator: "or", Nodes: []*e.TreeNode{{Item: int42Condition}, {Item: boolTrueCondition}}},
+		{Operator: "not", Nodes: []*e.TreeNode{{Item: stringFooCondition}}},
+		{Operator: "not"},
+		{Operator: "or"},
+		{Operator: "and"},
+		{Operator: "unknown", Nodes: []*e.TreeNode{{Item: boolTrueCondition}}},
+		{Operator: "or", Nodes: []*e.TreeNode{{Item: unknownMatch}, {Item: stringFooCondition}}},
+		{Operator: "and", Nodes: []*e.TreeNode{{Item: unknownType}}},
+		{Operator: "or", Nodes: []*e.TreeNode{{Item: 42}}},
+		{Operator: "or", Nodes: []*e.TreeNode{{Item: "11111"}, {Item: "99999"}}},
+		{Operator: "and", Nodes: []*e.TreeNode{{Item: "33333"}, {Operator: "not", Nodes: []*e.TreeNode{{Item: "22222"}}}}},
+		{Item: "33333"},
+		{Item: stringFooCondition},
+	}
+	users := []e.UserContext{
+		{},
+		{Attributes: map[string]interface{}{"string_foo": "foo"}},
+		{Attributes: map[string]interface{}{"string_foo": "bar", "bool_true": true, "int_42": 42}},
+		{Attributes: map[string]interface{}{"string_foo": 42, "bool_true": true, "int_42": 42.0}},
+		{Attributes: map[string]interface{}{"bool_true": false, "int_42": "42"}},
+	}
+
+	logger := logging.GetLogger("", "CompiledTreeEvaluatorTest")
+	interpreted := NewMixedTreeEvaluator(logger)
+	compiled := NewCompiledTreeEvaluator(logger, nil)
+	for i, tree := range trees {
+		for j := range users {
+			params := e.NewTreeParameters(&users[j], compiledAudienceMap)
+			expectedResult, expectedValid, _ := interpreted.Evaluate(tree, params, &decide.Options{})
+			result, isValid, _ := compiled.Evaluate(tree, params, &decide.Options{})
+			message := fmt.Sprintf("tree %d and user %d", i, j)
+			assert.Equal(t, expectedValid, isValid, message)
+			assert.Equal(t, expectedResult, result, message)
+		}
+	}
+}
+
+func TestCompiledTreeEvaluatorCyclicAudiences(t *testing.T) {
+	user := e.UserContext{}
+	params := e.NewTreeParameters(&user, compiledAudienceMap)
+	result, isValid, _ := NewCompiledTreeEvaluator(logging.GetLogger("", "CompiledTreeEvaluatorTest"), nil).Evaluate(&e.TreeNode{Item: "44444"}, params, &decide.Options{})
+	assert.False(t, result)
+	assert.False(t, isValid)
+}
+
+func TestCompiledTreeEvaluatorRecompilesForNewAudienceMap(t *testing.T) {
+	compiled := NewCompiledTreeEvaluator(logging.GetLogger("", "CompiledTreeEvaluatorTest"), nil)
+	tree := &e.TreeNode{Item: "11111"}
+	user := e.UserContext{Attributes: map[string]interface{}{"string_foo": "foo"}}
+
+	result, _, _ := compiled.Evaluate(tree, e.NewTreeParameters(&user, compiledAudienceMap), &decide.Options{})
+	assert.True(t, result)
+
+	updated := map[string]e.Audience{
+		"11111": {ID: "11111", ConditionTree: &e.TreeNode{Operator: "not", Nodes: []*e.TreeNode{{Item: stringFooCondition}}}},
+	}
+	result, isValid, _ := compiled.Evaluate(tree, e.NewTreeParameters(&user, updated), &decide.Options{})
+	assert.False(t, result)
+	assert.True(t, isValid)
+}
+
+func TestCompiledTreeEvaluatorIncludesReasons(t *testing.T) {
+	user := e.UserContext{Attributes: map[string]interface{}{"string_foo": "foo"}}
+	tree := &e.TreeNode{Item: e.Condition{Type: "custom_attribute", Match: "unknown", Name: "string_foo", Value: "foo"}}
+	compiled := NewCompiledTreeEvaluator(logging.GetLogger("", "CompiledTreeEvaluatorTest"), nil)
+
+	_, _, reasons := compiled.Evaluate(tree, e.NewTreeParameters(&user, compiledAudienceMap), &decide.Options{IncludeReasons: true})
+	assert.Equal(t, []string{`invalid Condition matcher "unknown"`}, reasons.ToReport())
+
+	_, _, reasons = compiled.Evaluate(tree, e.NewTreeParameters(&user, compiledAudienceMap), &decide.Options{})
+	assert.Empty(t, reasons.ToReport())
+}
+
+func TestCompiledTreeEvaluatorDoesNotAllocate(t *testing.T) {
+	user := e.UserContext{Attributes: map[string]interface{}{"string_foo": "bar", "bool_true": true, "int_42": 42}}
+	params := e.NewTreeParameters(&user, compiledAudienceMap)
+	tree := &e.TreeNode{Operator: "and", Nodes: []*e.TreeNode{{Item: "33333"}, {Operator: "not", Nodes: []*e.TreeNode{{Item: "11111"}}}}}
+	options := &decide.Options{}
+	compiled := NewCompiledTreeEvaluator(logging.GetLogger("", "CompiledTreeEvaluatorTest"), nil)
+
+	result, isValid, _ := compiled.Evaluate(tree, params, options)
+	assert.True(t, result)
+	assert.True(t, isValid)
+	assert.Zero(t, testing.AllocsPerRun(100, func() {
+		compiled.Evaluate(tree, params, options)
+	}))
+}
+
+func BenchmarkTreeEvaluator(b *testing.B) {
+	user := e.UserContext{Attributes: map[string]interface{}{"string_foo": "bar", "bool_true": true, "int_42": 42}}
+	params := e.NewTreeParameters(&user, compiledAudienceMap)
+	tree := &e.TreeNode{Operator: "and", Nodes: []*e.TreeNode{{Item: "33333"}, {Operator: "not", Nodes: []*e.TreeNode{{Item: "11111"}}}}}
+	options := &decide.Options{}
+	logger := logging.GetLogger("", "CompiledTreeEvaluatorTest")
+
+	for name, treeEvaluator := range map[string]TreeEvaluator{
+		"interpreted": NewMixedTreeEvaluator(logger),
+		"compiled":    NewCompiledTreeEvaluator(logger, nil),
+	} {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				treeEvaluator.Evaluate(tree, params, options)
+			}
+		})
+	}
+}
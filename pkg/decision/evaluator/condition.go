@@ -34,14 +34,21 @@ type ItemEvaluator interface {
 
 // CustomAttributeConditionEvaluator evaluates conditions with custom attributes
 type CustomAttributeConditionEvaluator struct {
-	logger logging.OptimizelyLogProducer
+	logger   logging.OptimizelyLogProducer
+	registry *matchers.MatcherRegistry
 }
 
-// NewCustomAttributeConditionEvaluator creates a custom attribute condition
+// NewCustomAttributeConditionEvaluator creates a custom attribute condition evaluator using the default matcher registry
 func NewCustomAttributeConditionEvaluator(logger logging.OptimizelyLogProducer) *CustomAttributeConditionEvaluator {
 	return &CustomAttributeConditionEvaluator{logger: logger}
 }
 
+// NewCustomAttributeConditionEvaluatorWithRegistry creates a custom attribute condition evaluator looking up matchers
+// in the given registry, the default one when nil
+func NewCustomAttributeConditionEvaluatorWithRegistry(logger logging.OptimizelyLogProducer, registry *matchers.MatcherRegistry) *CustomAttributeConditionEvaluator {
+	return &CustomAttributeConditionEvaluator{logger: logger, registry: registry}
+}
+
 // Evaluate returns true if the given user's attributes match the condition
 func (c CustomAttributeConditionEvaluator) Evaluate(condition entities.Condition, condTreeParams *entities.TreeParameters, options *decide.Options) (bool, decide.DecisionReasons, error) {
 	// We should only be evaluating custom attributes
@@ -64,7 +71,7 @@ func (c CustomAttributeConditionEvaluator) Evaluate(condition entities.Condition
 		matchType = matchers.ExactMatchType
 	}
 
-	matcher, ok := matchers.Get(matchType)
+	matcher, ok := c.registry.Get(matchType)
 	if !ok {
 		c.logger.Warning(fmt.Sprintf(logging.UnknownMatchType.String(), condition.StringRepresentation))
 		errorMessage := reasons.AddInfo(`invalid Condition matcher "%s"`, condition.Match)
@@ -77,7 +84,8 @@ func (c CustomAttributeConditionEvaluator) Evaluate(condition entities.Condition
 
 // AudienceConditionEvaluator evaluates conditions with audience condition
 type AudienceConditionEvaluator struct {
-	logger logging.OptimizelyLogProducer
+	logger   logging.OptimizelyLogProducer
+	registry *matchers.MatcherRegistry
 }
 
 // NewAudienceConditionEvaluator creates a audience condition evaluator
@@ -91,7 +99,7 @@ func (c AudienceConditionEvaluator) Evaluate(audienceID string, condTreeParams *
 	if audience, ok := condTreeParams.AudienceMap[audienceID]; ok {
 		c.logger.Debug(fmt.Sprintf(logging.AudienceEvaluationStarted.String(), audienceID))
 		condTree := audience.ConditionTree
-		conditionTreeEvaluator := NewMixedTreeEvaluator(c.logger)
+		conditionTreeEvaluator := NewMixedTreeEvaluatorWithRegistry(c.logger, c.registry)
 		retValue, isValid, decisionReasons := conditionTreeEvaluator.Evaluate(condTree, condTreeParams, options)
 		reasons.Append(decisionReasons)
 		if !isValid {
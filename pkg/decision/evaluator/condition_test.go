@@ -17,6 +17,7 @@
 package evaluator
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -283,6 +284,57 @@ func (s *ConditionTestSuite) TestCustomAttributeConditionEvaluatorForGeSemverInv
 	s.NotNil(err)
 }
 
+func (s *ConditionTestSuite) TestCustomAttributeConditionEvaluatorWithDatafileConditions() {
+	user := entities.UserContext{
+		Attributes: map[string]interface{}{
+			"email":   "user-42@example.com",
+			"country": "ca",
+			"signup":  "2026-06-15T08:30:00Z",
+		},
+	}
+	condTreeParams := entities.NewTreeParameters(&user, map[string]entities.Audience{})
+
+	for _, conditionJSON := range []string{
+		`{"type": "custom_attribute", "match": "regex", "name": "email", "value": "^user-\\d+@"}`,
+		`{"type": "custom_attribute", "match": "ends_with", "name": "email", "value": "@example.com"}`,
+		`{"type": "custom_attribute", "match": "in", "name": "country", "value": ["us", "ca"]}`,
+		`{"type": "custom_attribute", "match": "between", "name": "signup", "value": ["2026-06-01", "2026-07-01"]}`,
+	} {
+		var condition entities.Condition
+		s.Require().NoError(json.Unmarshal([]byte(conditionJSON), &condition))
+		result, _, err := s.conditionEvaluator.Evaluate(condition, condTreeParams, &s.options)
+		s.NoError(err, conditionJSON)
+		s.True(result, conditionJSON)
+	}
+}
+
+func (s *ConditionTestSuite) TestCustomAttributeConditionEvaluatorWithRegistry() {
+	registry := matchers.NewMatcherRegistry()
+	registry.Register("scoped", func(condition entities.Condition, user entities.UserContext, logger logging.OptimizelyLogProducer) (bool, error) {
+		return true, nil
+	})
+	condition := entities.Condition{Match: "scoped", Name: "string_foo", Type: customAttributeType}
+	user := entities.UserContext{}
+	condTreeParams := entities.NewTreeParameters(&user, map[string]entities.Audience{
+		"11111": {ID: "11111", ConditionTree: &entities.TreeNode{Operator: "or", Nodes: []*entities.TreeNode{{Item: condition}}}},
+	})
+
+	result, _, err := NewCustomAttributeConditionEvaluatorWithRegistry(s.mockLogger, registry).Evaluate(condition, condTreeParams, &s.options)
+	s.NoError(err)
+	s.True(result)
+
+	// the matcher is resolved in nested audiences as well
+	tree := &entities.TreeNode{Operator: "and", Nodes: []*entities.TreeNode{{Item: "11111"}}}
+	result, isValid, _ := NewMixedTreeEvaluatorWithRegistry(logging.GetLogger("", "ConditionTest"), registry).Evaluate(tree, condTreeParams, &s.options)
+	s.True(isValid)
+	s.True(result)
+
+	// the matcher is not in the default registry
+	s.mockLogger.On("Warning", fmt.Sprintf(logging.UnknownMatchType.String(), ""))
+	_, _, err = s.conditionEvaluator.Evaluate(condition, condTreeParams, &s.options)
+	s.Error(err)
+}
+
 func TestConditionTestSuite(t *testing.T) {
 	suite.Run(t, new(ConditionTestSuite))
 }
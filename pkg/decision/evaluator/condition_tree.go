@@ -21,6 +21,7 @@ import (
 	"fmt"
 
 	"github.com/optimizely/go-sdk/v2/pkg/decide"
+	"github.com/optimizely/go-sdk/v2/pkg/decision/evaluator/matchers"
 	"github.com/optimizely/go-sdk/v2/pkg/entities"
 	"github.com/optimizely/go-sdk/v2/pkg/logging"
 )
@@ -50,7 +51,8 @@ type TreeEvaluator interface {
 
 // MixedTreeEvaluator evaluates a tree of mixed node types (condition node or audience nodes)
 type MixedTreeEvaluator struct {
-	logger logging.OptimizelyLogProducer
+	logger   logging.OptimizelyLogProducer
+	registry *matchers.MatcherRegistry
 }
 
 // NewMixedTreeEvaluator creates a condition tree evaluator with the out-of-the-box condition evaluators
@@ -58,6 +60,12 @@ func NewMixedTreeEvaluator(logger logging.OptimizelyLogProducer) *MixedTreeEvalu
 	return &MixedTreeEvaluator{logger: logger}
 }
 
+// NewMixedTreeEvaluatorWithRegistry creates a condition tree evaluator evaluating conditions with the matchers of the
+// given registry, the default one when nil
+func NewMixedTreeEvaluatorWithRegistry(logger logging.OptimizelyLogProducer, registry *matchers.MatcherRegistry) *MixedTreeEvaluator {
+	return &MixedTreeEvaluator{logger: logger, registry: registry}
+}
+
 // Evaluate returns whether the userAttributes satisfy the given condition tree and the evaluation of the condition is valid or not (to handle null bubbling)
 func (c MixedTreeEvaluator) Evaluate(node *entities.TreeNode, condTreeParams *entities.TreeParameters, options *decide.Options) (evalResult, isValid bool, reasons decide.DecisionReasons) {
 	reasons = decide.NewDecisionReasons(options)
@@ -77,12 +85,12 @@ func (c MixedTreeEvaluator) Evaluate(node *entities.TreeNode, condTreeParams *en
 	var err error
 	switch v := node.Item.(type) {
 	case entities.Condition:
-		evaluator := NewCustomAttributeConditionEvaluator(c.logger)
+		evaluator := NewCustomAttributeConditionEvaluatorWithRegistry(c.logger, c.registry)
 		var decisionReasons decide.DecisionReasons
 		result, decisionReasons, err = evaluator.Evaluate(node.Item.(entities.Condition), condTreeParams, options)
 		reasons.Append(decisionReasons)
 	case string:
-		evaluator := NewAudienceConditionEvaluator(c.logger)
+		evaluator := &AudienceConditionEvaluator{logger: c.logger, registry: c.registry}
 		var decisionReasons decide.DecisionReasons
 		result, decisionReasons, err = evaluator.Evaluate(node.Item.(string), condTreeParams, options)
 		reasons.Append(decisionReasons)
@@ -0,0 +1,111 @@
+/****************************************************************************
+ * Copyright 2019-2021, Optimizely, Inc. and contributors                   *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package matchers
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/optimizely/go-sdk/v2/pkg/entities"
+	"github.com/optimizely/go-sdk/v2/pkg/logging"
+)
+
+type AffixTestSuite struct {
+	suite.Suite
+	mockLogger *MockLogger
+}
+
+func (s *AffixTestSuite) SetupTest() {
+	s.mockLogger = new(MockLogger)
+}
+
+func (s *AffixTestSuite) TestStartsWithMatcher() {
+	matcher, _ := Get(StartsWithMatchType)
+	condition := entities.Condition{
+		Match: "starts_with",
+		Value: "foo",
+		Name:  "string_foo",
+	}
+
+	// Test match
+	user := entities.UserContext{
+		Attributes: map[string]interface{}{
+			"string_foo": "foobar",
+		},
+	}
+	result, err := matcher(condition, user, s.mockLogger)
+	s.NoError(err)
+	s.True(result)
+
+	// Test no match
+	user.Attributes["string_foo"] = "barfoo"
+	result, err = matcher(condition, user, s.mockLogger)
+	s.NoError(err)
+	s.False(result)
+
+	// Test attribute not found
+	s.mockLogger.On("Debug", fmt.Sprintf(logging.NullUserAttribute.String(), "", "string_foo"))
+	_, err = matcher(condition, entities.UserContext{}, s.mockLogger)
+	s.Error(err)
+
+	// Test attribute of different type
+	user.Attributes["string_foo"] = true
+	s.mockLogger.On("Warning", fmt.Sprintf(logging.InvalidAttributeValueType.String(), "", true, "string_foo"))
+	result, err = matcher(condition, user, s.mockLogger)
+	s.Error(err)
+	s.False(result)
+	s.mockLogger.AssertExpectations(s.T())
+}
+
+func (s *AffixTestSuite) TestEndsWithMatcher() {
+	matcher, _ := Get(EndsWithMatchType)
+	condition := entities.Condition{
+		Match: "ends_with",
+		Value: "foo",
+		Name:  "string_foo",
+	}
+
+	// Test match
+	user := entities.UserContext{
+		Attributes: map[string]interface{}{
+			"string_foo": "barfoo",
+		},
+	}
+	result, err := matcher(condition, user, s.mockLogger)
+	s.NoError(err)
+	s.True(result)
+
+	// Test no match
+	user.Attributes["string_foo"] = "foobar"
+	result, err = matcher(condition, user, s.mockLogger)
+	s.NoError(err)
+	s.False(result)
+
+	// Test unsupported condition value
+	condition.Value = false
+	s.mockLogger.On("Warning", fmt.Sprintf(logging.UnsupportedConditionValue.String(), ""))
+	result, err = matcher(condition, user, s.mockLogger)
+	s.Error(err)
+	s.False(result)
+	s.mockLogger.AssertExpectations(s.T())
+}
+
+func TestAffixTestSuite(t *testing.T) {
+	suite.Run(t, new(AffixTestSuite))
+}
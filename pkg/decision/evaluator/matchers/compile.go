@@ -0,0 +1,366 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package matchers //
+package matchers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/optimizely/go-sdk/v2/pkg/decision/evaluator/matchers/utils"
+	"github.com/optimizely/go-sdk/v2/pkg/entities"
+	"github.com/optimizely/go-sdk/v2/pkg/logging"
+)
+
+// CompiledMatcher evaluates a condition compiled by a Compiler for the given user
+type CompiledMatcher func(user *entities.UserContext) (bool, error)
+
+// Compiler compiles a condition once so that its value is not converted again at every evaluation. The CompiledMatcher
+// returned must evaluate and log like the Matcher it is registered with.
+type Compiler func(condition entities.Condition, logger logging.OptimizelyLogProducer) CompiledMatcher
+
+// builtInCompilers are the compilers of the built-in matchers
+var builtInCompilers = map[string]Compiler{
+	QualifiedMatchType: compileQualified,
+	ExactMatchType:     compileExact,
+	ExistsMatchType:    compileExists,
+	LtMatchType: numberCompiler(true, func(attributeValue, conditionValue float64) bool {
+		return attributeValue < conditionValue
+	}),
+	LeMatchType: numberCompiler(false, func(attributeValue, conditionValue float64) bool {
+		return attributeValue <= conditionValue
+	}),
+	GtMatchType: numberCompiler(true, func(attributeValue, conditionValue float64) bool {
+		return attributeValue > conditionValue
+	}),
+	GeMatchType: numberCompiler(false, func(attributeValue, conditionValue float64) bool {
+		return attributeValue >= conditionValue
+	}),
+	SubstringMatchType:  stringCompiler(strings.Contains),
+	StartsWithMatchType: stringCompiler(strings.HasPrefix),
+	EndsWithMatchType:   stringCompiler(strings.HasSuffix),
+	SemverEqMatchType:   semverCompiler(func(comparison int) bool { return comparison == 0 }),
+	SemverLtMatchType:   semverCompiler(func(comparison int) bool { return comparison < 0 }),
+	SemverLeMatchType:   semverCompiler(func(comparison int) bool { return comparison <= 0 }),
+	SemverGtMatchType:   semverCompiler(func(comparison int) bool { return comparison > 0 }),
+	SemverGeMatchType:   semverCompiler(func(comparison int) bool { return comparison >= 0 }),
+	RegexMatchType:      compileRegex,
+	InMatchType:         listCompiler(false),
+	NotInMatchType:      listCompiler(true),
+	BeforeMatchType: dateTimeCompiler(func(attributeValue, conditionValue time.Time) bool {
+		return attributeValue.Before(conditionValue)
+	}),
+	AfterMatchType: dateTimeCompiler(func(attributeValue, conditionValue time.Time) bool {
+		return attributeValue.After(conditionValue)
+	}),
+	BetweenMatchType: compileBetween,
+}
+
+func compileQualified(condition entities.Condition, logger logging.OptimizelyLogProducer) CompiledMatcher {
+	if stringValue, ok := condition.Value.(string); ok {
+		return func(user *entities.UserContext) (bool, error) {
+			return user.IsQualifiedFor(stringValue), nil
+		}
+	}
+	return func(user *entities.UserContext) (bool, error) {
+		logger.Warning(fmt.Sprintf(logging.UnsupportedConditionValue.String(), condition.StringRepresentation))
+		return false, unsupportedValueError(condition)
+	}
+}
+
+func compileExact(condition entities.Condition, logger logging.OptimizelyLogProducer) CompiledMatcher {
+	switch conditionValue := condition.Value.(type) {
+	case string:
+		return func(user *entities.UserContext) (bool, error) {
+			if !user.CheckAttributeExists(condition.Name) {
+				return false, missingAttribute(condition, logger)
+			}
+			attributeValue, ok := user.Attributes[condition.Name].(string)
+			if !ok {
+				return false, invalidAttribute(condition, user, logger, "string")
+			}
+			return attributeValue == conditionValue, nil
+		}
+	case bool:
+		return func(user *entities.UserContext) (bool, error) {
+			if !user.CheckAttributeExists(condition.Name) {
+				return false, missingAttribute(condition, logger)
+			}
+			attributeValue, ok := user.Attributes[condition.Name].(bool)
+			if !ok {
+				return false, invalidAttribute(condition, user, logger, "bool")
+			}
+			return attributeValue == conditionValue, nil
+		}
+	}
+
+	if floatValue, ok := utils.ToFloat(condition.Value); ok {
+		return func(user *entities.UserContext) (bool, error) {
+			if !user.CheckAttributeExists(condition.Name) {
+				return false, missingAttribute(condition, logger)
+			}
+			attributeValue, ok := floatAttribute(user, condition.Name)
+			if !ok {
+				return false, invalidAttribute(condition, user, logger, "float")
+			}
+			return attributeValue == floatValue, nil
+		}
+	}
+	return unsupportedValue(condition, logger, true)
+}
+
+func compileExists(condition entities.Condition, logger logging.OptimizelyLogProducer) CompiledMatcher {
+	return func(user *entities.UserContext) (bool, error) {
+		return user.CheckAttributeExists(condition.Name), nil
+	}
+}
+
+// numberCompiler compiles numeric comparisons, checked tells whether the matcher reports missing attributes and
+// invalid values in the logs like "lt" and "gt" do
+func numberCompiler(checked bool, compare func(attributeValue, conditionValue float64) bool) Compiler {
+	return func(condition entities.Condition, logger logging.OptimizelyLogProducer) CompiledMatcher {
+		floatValue, ok := utils.ToFloat(condition.Value)
+		if !ok {
+			return unsupportedValue(condition, logger, checked)
+		}
+		return func(user *entities.UserContext) (bool, error) {
+			if checked && !user.CheckAttributeExists(condition.Name) {
+				return false, missingAttribute(condition, logger)
+			}
+			attributeValue, ok := floatAttribute(user, condition.Name)
+			if !ok {
+				if !checked {
+					return false, fmt.Errorf(`no float attribute named "%s"`, condition.Name)
+				}
+				return false, invalidAttribute(condition, user, logger, "float")
+			}
+			return compare(attributeValue, floatValue), nil
+		}
+	}
+}
+
+func stringCompiler(match func(attributeValue, conditionValue string) bool) Compiler {
+	return func(condition entities.Condition, logger logging.OptimizelyLogProducer) CompiledMatcher {
+		stringValue, ok := condition.Value.(string)
+		if !ok {
+			return unsupportedValue(condition, logger, true)
+		}
+		return func(user *entities.UserContext) (bool, error) {
+			if !user.CheckAttributeExists(condition.Name) {
+				return false, missingAttribute(condition, logger)
+			}
+			attributeValue, ok := user.Attributes[condition.Name].(string)
+			if !ok {
+				return false, invalidAttribute(condition, user, logger, "string")
+			}
+			return match(attributeValue, stringValue), nil
+		}
+	}
+}
+
+// semverCompiler splits the condition version once, the attribute version is still split at every evaluation
+func semverCompiler(accept func(comparison int) bool) Compiler {
+	return func(condition entities.Condition, logger logging.OptimizelyLogProducer) CompiledMatcher {
+		stringValue, ok := condition.Value.(string)
+		if !ok {
+			return unsupportedValue(condition, logger, false)
+		}
+		semVer := SemanticVersion{stringValue}
+		targetedVersionParts, splitErr := semVer.splitSemanticVersion(stringValue)
+		return func(user *entities.UserContext) (bool, error) {
+			attributeValue, ok := user.Attributes[condition.Name].(string)
+			if !ok {
+				return false, fmt.Errorf(`no string attribute named "%s"`, condition.Name)
+			}
+			if splitErr != nil {
+				return false, splitErr
+			}
+			comparison, err := semVer.compareVersionParts(targetedVersionParts, attributeValue)
+			if err != nil {
+				return false, err
+			}
+			return accept(comparison), nil
+		}
+	}
+}
+
+func compileRegex(condition entities.Condition, logger logging.OptimizelyLogProducer) CompiledMatcher {
+	pattern, ok := condition.Value.(string)
+	if !ok {
+		return unsupportedValue(condition, logger, true)
+	}
+	regex, regexErr := defaultRegexCache.get(pattern)
+	return func(user *entities.UserContext) (bool, error) {
+		if !user.CheckAttributeExists(condition.Name) {
+			return false, missingAttribute(condition, logger)
+		}
+		if regexErr != nil {
+			logger.Warning(fmt.Sprintf(logging.UnsupportedConditionValue.String(), condition.StringRepresentation))
+			return false, fmt.Errorf("audience condition %s evaluated to NULL because the regex pattern is invalid: %w", condition.Name, regexErr)
+		}
+		attributeValue, ok := user.Attributes[condition.Name].(string)
+		if !ok {
+			return false, invalidAttribute(condition, user, logger, "string")
+		}
+		if len(attributeValue) > MaxRegexInputLength {
+			return false, fmt.Errorf("audience condition %s evaluated to NULL because the attribute value is longer than %d characters", condition.Name, MaxRegexInputLength)
+		}
+		return regex.MatchString(attributeValue), nil
+	}
+}
+
+// listCompiler compiles "in" and "not_in" conditions into sets of the strings, booleans and numbers listed
+func listCompiler(negate bool) Compiler {
+	return func(condition entities.Condition, logger logging.OptimizelyLogProducer) CompiledMatcher {
+		values, ok := toList(condition.Value)
+		if !ok {
+			return unsupportedValue(condition, logger, true)
+		}
+		stringSet := make(map[string]struct{})
+		floatSet := make(map[float64]struct{})
+		var boolSet [2]bool
+		for _, value := range values {
+			switch typedValue := value.(type) {
+			case string:
+				stringSet[typedValue] = struct{}{}
+			case bool:
+				boolSet[boolIndex(typedValue)] = true
+			default:
+				floatValue, _ := utils.ToFloat(typedValue)
+				floatSet[floatValue] = struct{}{}
+			}
+		}
+
+		return func(user *entities.UserContext) (bool, error) {
+			if !user.CheckAttributeExists(condition.Name) {
+				return false, missingAttribute(condition, logger)
+			}
+			var found bool
+			switch attributeValue := user.Attributes[condition.Name].(type) {
+			case string:
+				_, found = stringSet[attributeValue]
+			case bool:
+				found = boolSet[boolIndex(attributeValue)]
+			default:
+				floatValue, ok := floatAttribute(user, condition.Name)
+				if !ok {
+					logger.Warning(fmt.Sprintf(logging.InvalidAttributeValueType.String(), condition.StringRepresentation, attributeValue, condition.Name))
+					return false, fmt.Errorf(`no string, number or bool attribute named "%s"`, condition.Name)
+				}
+				_, found = floatSet[floatValue]
+			}
+			return found != negate, nil
+		}
+	}
+}
+
+func dateTimeCompiler(compare func(attributeValue, conditionValue time.Time) bool) Compiler {
+	return func(condition entities.Condition, logger logging.OptimizelyLogProducer) CompiledMatcher {
+		conditionValue, ok := parseDateTime(condition.Value)
+		if !ok {
+			return unsupportedValue(condition, logger, true)
+		}
+		return func(user *entities.UserContext) (bool, error) {
+			if !user.CheckAttributeExists(condition.Name) {
+				return false, missingAttribute(condition, logger)
+			}
+			attributeValue, err := getDateTimeAttribute(condition, *user, logger)
+			if err != nil {
+				return false, err
+			}
+			return compare(attributeValue, conditionValue), nil
+		}
+	}
+}
+
+func compileBetween(condition entities.Condition, logger logging.OptimizelyLogProducer) CompiledMatcher {
+	bounds, ok := toList(condition.Value)
+	if !ok || len(bounds) != 2 {
+		return unsupportedValue(condition, logger, true)
+	}
+	start, startOk := parseDateTime(bounds[0])
+	end, endOk := parseDateTime(bounds[1])
+	if !startOk || !endOk {
+		return unsupportedValue(condition, logger, true)
+	}
+	return func(user *entities.UserContext) (bool, error) {
+		if !user.CheckAttributeExists(condition.Name) {
+			return false, missingAttribute(condition, logger)
+		}
+		attributeValue, err := getDateTimeAttribute(condition, *user, logger)
+		if err != nil {
+			return false, err
+		}
+		return !attributeValue.Before(start) && !attributeValue.After(end), nil
+	}
+}
+
+// floatAttribute returns the attribute as a float64 like GetFloatAttribute, without reflection for the common types
+func floatAttribute(user *entities.UserContext, name string) (float64, bool) {
+	switch value := user.Attributes[name].(type) {
+	case float64:
+		return value, true
+	case int:
+		return float64(value), true
+	case int64:
+		return float64(value), true
+	case int32:
+		return float64(value), true
+	case float32:
+		return float64(value), true
+	case nil, string, bool:
+		return 0, false
+	}
+	value, err := user.GetFloatAttribute(name)
+	return value, err == nil
+}
+
+func boolIndex(value bool) int {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+func missingAttribute(condition entities.Condition, logger logging.OptimizelyLogProducer) error {
+	logger.Debug(fmt.Sprintf(logging.NullUserAttribute.String(), condition.StringRepresentation, condition.Name))
+	return fmt.Errorf(`no attribute named "%s"`, condition.Name)
+}
+
+func invalidAttribute(condition entities.Condition, user *entities.UserContext, logger logging.OptimizelyLogProducer, kind string) error {
+	logger.Warning(fmt.Sprintf(logging.InvalidAttributeValueType.String(), condition.StringRepresentation, user.Attributes[condition.Name], condition.Name))
+	return fmt.Errorf(`no %s attribute named "%s"`, kind, condition.Name)
+}
+
+// unsupportedValue compiles a condition whose value the matcher does not support, checked tells whether the matcher
+// reports missing attributes and the unsupported value in the logs
+func unsupportedValue(condition entities.Condition, logger logging.OptimizelyLogProducer, checked bool) CompiledMatcher {
+	return func(user *entities.UserContext) (bool, error) {
+		if checked {
+			if !user.CheckAttributeExists(condition.Name) {
+				return false, missingAttribute(condition, logger)
+			}
+			logger.Warning(fmt.Sprintf(logging.UnsupportedConditionValue.String(), condition.StringRepresentation))
+		}
+		return false, unsupportedValueError(condition)
+	}
+}
+
+func unsupportedValueError(condition entities.Condition) error {
+	return fmt.Errorf("audience condition %s evaluated to NULL because the condition value type is not supported", condition.Name)
+}
@@ -0,0 +1,173 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package matchers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/optimizely/go-sdk/v2/pkg/entities"
+	"github.com/optimizely/go-sdk/v2/pkg/logging"
+)
+
+// recordingLogger keeps the messages logged so that compiled and interpreted evaluations can be compared
+type recordingLogger struct {
+	messages []string
+}
+
+func (l *recordingLogger) Debug(message string) { l.messages = append(l.messages, "debug: "+message) }
+func (l *recordingLogger) Info(message string)  { l.messages = append(l.messages, "info: "+message) }
+func (l *recordingLogger) Warning(message string) {
+	l.messages = append(l.messages, "warning: "+message)
+}
+func (l *recordingLogger) Error(message string, err interface{}) {
+	l.messages = append(l.messages, fmt.Sprintf("error: %s %v", message, err))
+}
+
+func TestCompiledMatchersEvaluateLikeMatchers(t *testing.T) {
+	conditionValues := map[string][]interface{}{
+		ExactMatchType:      {"foo", true, 42, 4.2, float32(1.5), int64(7), nil, []interface{}{"foo"}},
+		ExistsMatchType:     {nil},
+		LtMatchType:         {42, 4.2, "foo", nil},
+		LeMatchType:         {42, 4.2, "foo"},
+		GtMatchType:         {42, 4.2, "foo"},
+		GeMatchType:         {42, 4.2, "foo"},
+		SubstringMatchType:  {"fo", "", 42},
+		StartsWithMatchType: {"fo", "oo", true},
+		EndsWithMatchType:   {"oo", "fo", 1.5},
+		QualifiedMatchType:  {"segment", 42},
+		SemverEqMatchType:   {"1.2.3", "1.2", "1.2.3-beta", "1.2.3+build", "bad version", 42},
+		SemverLtMatchType:   {"1.2.3", "2", "1.2.3-beta"},
+		SemverLeMatchType:   {"1.2.3", "1.2.4"},
+		SemverGtMatchType:   {"1.2.3", "1.2.3-alpha"},
+		SemverGeMatchType:   {"1.2.3", "1.2.3-beta"},
+		RegexMatchType:      {"^f.o$", "[", 42},
+		InMatchType:         {[]interface{}{"foo", 42, true}, []interface{}{4.2, false}, []string{"foo", "bar"}, []interface{}{}, "foo", []interface{}{map[string]interface{}{}}},
+		NotInMatchType:      {[]interface{}{"foo", 42, true}, []interface{}{"bar"}, 42},
+		BeforeMatchType:     {"2026-01-01T00:00:00Z", "2026-01-01", "not a date", 42},
+		AfterMatchType:      {"2026-01-01T00:00:00Z", time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)},
+		BetweenMatchType:    {[]interface{}{"2025-01-01", "2026-12-31T23:59"}, []interface{}{"2025-01-01"}, []interface{}{"2025-01-01", 42}, "2025-01-01"},
+	}
+	attributeValues := []interface{}{
+		"foo", "afoo", "", true, false, 42, 42.0, 4.2, float32(42), int64(41), uint8(43), nil,
+		"1.2.3", "1.2.3-beta", "1.2.4", "2.0.0+build", "1 .2",
+		"2025-06-01T12:00:00Z", "2026-01-01", "2027-03-04T05:06", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		[]interface{}{"foo"}, map[string]interface{}{},
+	}
+
+	users := []entities.UserContext{{Attributes: map[string]interface{}{}}}
+	for _, attributeValue := range attributeValues {
+		users = append(users, entities.UserContext{
+			Attributes:        map[string]interface{}{"attr": attributeValue},
+			QualifiedSegments: []string{"segment"},
+		})
+	}
+
+	for matchType, values := range conditionValues {
+		matcher := assertMatcher(t, matchType)
+		for _, value := range values {
+			condition := entities.Condition{Type: "custom_attribute", Match: matchType, Name: "attr", Value: value,
+				StringRepresentation: fmt.Sprintf("%s %v", matchType, value)}
+			compiledLogger := &recordingLogger{}
+			compiled, ok := NewMatcherRegistry().Compile(matchType, condition, compiledLogger)
+			require.True(t, ok)
+
+			for _, user := range users {
+				logger := &recordingLogger{}
+				expected, expectedErr := matcher(condition, user, logger)
+				compiledLogger.messages = nil
+				actual, err := compiled(&user)
+
+				message := fmt.Sprintf("%s %#v against %#v", matchType, value, user.Attributes)
+				assert.Equal(t, expected, actual, message)
+				if expectedErr == nil {
+					assert.NoError(t, err, message)
+				} else {
+					assert.EqualError(t, err, expectedErr.Error(), message)
+				}
+				assert.Equal(t, logger.messages, compiledLogger.messages, message)
+			}
+		}
+	}
+}
+
+func TestCompileWithoutCompiler(t *testing.T) {
+	registry := NewMatcherRegistry()
+	var seen entities.Condition
+	registry.Register(ExactMatchType, func(condition entities.Condition, user entities.UserContext, logger logging.OptimizelyLogProducer) (bool, error) {
+		seen = condition
+		return user.ID == "matching", nil
+	})
+
+	condition := entities.Condition{Name: "attr", Value: "foo"}
+	compiled, ok := registry.Compile(ExactMatchType, condition, &recordingLogger{})
+	require.True(t, ok)
+	result, err := compiled(&entities.UserContext{ID: "matching"})
+	assert.True(t, result)
+	assert.NoError(t, err)
+	assert.Equal(t, condition, seen)
+
+	_, ok = registry.Compile("unknown", condition, &recordingLogger{})
+	assert.False(t, ok)
+}
+
+func TestRegisterCompiled(t *testing.T) {
+	registry := NewMatcherRegistry()
+	compilations := 0
+	registry.RegisterCompiled("custom", ExistsMatcher, func(condition entities.Condition, logger logging.OptimizelyLogProducer) CompiledMatcher {
+		compilations++
+		return func(user *entities.UserContext) (bool, error) {
+			return true, nil
+		}
+	})
+
+	compiled, ok := registry.Compile("custom", entities.Condition{Name: "attr"}, &recordingLogger{})
+	require.True(t, ok)
+	result, err := compiled(&entities.UserContext{})
+	assert.True(t, result)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, compilations)
+
+	matcher, ok := registry.Get("custom")
+	require.True(t, ok)
+	result, _ = matcher(entities.Condition{Name: "attr"}, entities.UserContext{}, &recordingLogger{})
+	assert.False(t, result)
+}
+
+func BenchmarkCompiledExactMatcher(b *testing.B) {
+	condition := entities.Condition{Type: "custom_attribute", Match: ExactMatchType, Name: "attr", Value: 42.0}
+	user := entities.UserContext{Attributes: map[string]interface{}{"attr": 42}}
+	logger := &recordingLogger{}
+
+	b.Run("interpreted", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = ExactMatcher(condition, user, logger)
+		}
+	})
+	b.Run("compiled", func(b *testing.B) {
+		compiled, _ := NewMatcherRegistry().Compile(ExactMatchType, condition, logger)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = compiled(&user)
+		}
+	})
+}
@@ -0,0 +1,119 @@
+/****************************************************************************
+ * Copyright 2019-2021, Optimizely, Inc. and contributors                   *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package matchers //
+package matchers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/optimizely/go-sdk/v2/pkg/entities"
+	"github.com/optimizely/go-sdk/v2/pkg/logging"
+)
+
+// dateTimeLayouts are the ISO-8601 layouts accepted for date-time values, a value without an offset is taken as UTC
+var dateTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// BeforeMatcher matches against the "before" match type, the user's date-time attribute must be earlier than the
+// condition value
+func BeforeMatcher(condition entities.Condition, user entities.UserContext, logger logging.OptimizelyLogProducer) (bool, error) {
+	return compareDateTime(condition, user, logger, func(attributeValue, conditionValue time.Time) bool {
+		return attributeValue.Before(conditionValue)
+	})
+}
+
+// AfterMatcher matches against the "after" match type, the user's date-time attribute must be later than the
+// condition value
+func AfterMatcher(condition entities.Condition, user entities.UserContext, logger logging.OptimizelyLogProducer) (bool, error) {
+	return compareDateTime(condition, user, logger, func(attributeValue, conditionValue time.Time) bool {
+		return attributeValue.After(conditionValue)
+	})
+}
+
+// BetweenMatcher matches against the "between" match type, the condition value is a list of two date-times and the
+// user's date-time attribute must be within them, bounds included
+func BetweenMatcher(condition entities.Condition, user entities.UserContext, logger logging.OptimizelyLogProducer) (bool, error) {
+	if !user.CheckAttributeExists(condition.Name) {
+		logger.Debug(fmt.Sprintf(logging.NullUserAttribute.String(), condition.StringRepresentation, condition.Name))
+		return false, fmt.Errorf(`no attribute named "%s"`, condition.Name)
+	}
+
+	bounds, ok := toList(condition.Value)
+	if !ok || len(bounds) != 2 {
+		logger.Warning(fmt.Sprintf(logging.UnsupportedConditionValue.String(), condition.StringRepresentation))
+		return false, fmt.Errorf("audience condition %s evaluated to NULL because the condition value type is not supported", condition.Name)
+	}
+	start, startOk := parseDateTime(bounds[0])
+	end, endOk := parseDateTime(bounds[1])
+	if !startOk || !endOk {
+		logger.Warning(fmt.Sprintf(logging.UnsupportedConditionValue.String(), condition.StringRepresentation))
+		return false, fmt.Errorf("audience condition %s evaluated to NULL because the condition value type is not supported", condition.Name)
+	}
+
+	attributeValue, err := getDateTimeAttribute(condition, user, logger)
+	if err != nil {
+		return false, err
+	}
+	return !attributeValue.Before(start) && !attributeValue.After(end), nil
+}
+
+func compareDateTime(condition entities.Condition, user entities.UserContext, logger logging.OptimizelyLogProducer, compare func(attributeValue, conditionValue time.Time) bool) (bool, error) {
+	if !user.CheckAttributeExists(condition.Name) {
+		logger.Debug(fmt.Sprintf(logging.NullUserAttribute.String(), condition.StringRepresentation, condition.Name))
+		return false, fmt.Errorf(`no attribute named "%s"`, condition.Name)
+	}
+
+	if conditionValue, ok := parseDateTime(condition.Value); ok {
+		attributeValue, err := getDateTimeAttribute(condition, user, logger)
+		if err != nil {
+			return false, err
+		}
+		return compare(attributeValue, conditionValue), nil
+	}
+
+	logger.Warning(fmt.Sprintf(logging.UnsupportedConditionValue.String(), condition.StringRepresentation))
+	return false, fmt.Errorf("audience condition %s evaluated to NULL because the condition value type is not supported", condition.Name)
+}
+
+func getDateTimeAttribute(condition entities.Condition, user entities.UserContext, logger logging.OptimizelyLogProducer) (time.Time, error) {
+	val, _ := user.GetAttribute(condition.Name)
+	if attributeValue, ok := parseDateTime(val); ok {
+		return attributeValue, nil
+	}
+	logger.Warning(fmt.Sprintf(logging.InvalidAttributeValueType.String(), condition.StringRepresentation, val, condition.Name))
+	return time.Time{}, fmt.Errorf(`no date-time attribute named "%s"`, condition.Name)
+}
+
+// parseDateTime accepts a time.Time or a string in one of the dateTimeLayouts
+func parseDateTime(value interface{}) (time.Time, bool) {
+	switch typedValue := value.(type) {
+	case time.Time:
+		return typedValue, true
+	case string:
+		for _, layout := range dateTimeLayouts {
+			if parsed, err := time.Parse(layout, typedValue); err == nil {
+				return parsed, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
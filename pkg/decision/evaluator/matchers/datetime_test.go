@@ -0,0 +1,147 @@
+/****************************************************************************
+ * Copyright 2019-2021, Optimizely, Inc. and contributors                   *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package matchers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/optimizely/go-sdk/v2/pkg/entities"
+	"github.com/optimizely/go-sdk/v2/pkg/logging"
+)
+
+type DateTimeTestSuite struct {
+	suite.Suite
+	mockLogger *MockLogger
+}
+
+func (s *DateTimeTestSuite) SetupTest() {
+	s.mockLogger = new(MockLogger)
+}
+
+func (s *DateTimeTestSuite) TestBeforeAndAfterMatchers() {
+	before, _ := Get(BeforeMatchType)
+	after, _ := Get(AfterMatchType)
+	condition := entities.Condition{
+		Value: "2026-06-01T12:00:00Z",
+		Name:  "signup",
+	}
+
+	scenarios := []struct {
+		value  interface{}
+		before bool
+		after  bool
+	}{
+		{"2026-06-01T11:59:59Z", true, false},
+		{"2026-06-01T12:00:00Z", false, false},
+		{"2026-06-01T14:00:00+02:00", false, false},
+		{"2026-06-01T12:00:00.5", false, true},
+		{"2026-06-01T12:01", false, true},
+		{"2026-05-31", true, false},
+		{time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC), false, true},
+	}
+	for _, scenario := range scenarios {
+		user := entities.UserContext{
+			Attributes: map[string]interface{}{
+				"signup": scenario.value,
+			},
+		}
+		result, err := before(condition, user, s.mockLogger)
+		s.NoError(err)
+		s.Equal(scenario.before, result, scenario.value)
+
+		result, err = after(condition, user, s.mockLogger)
+		s.NoError(err)
+		s.Equal(scenario.after, result, scenario.value)
+	}
+
+	// Test attribute not found
+	s.mockLogger.On("Debug", fmt.Sprintf(logging.NullUserAttribute.String(), "", "signup"))
+	_, err := before(condition, entities.UserContext{}, s.mockLogger)
+	s.Error(err)
+
+	// Test attribute not a date-time
+	user := entities.UserContext{
+		Attributes: map[string]interface{}{
+			"signup": "yesterday",
+		},
+	}
+	s.mockLogger.On("Warning", fmt.Sprintf(logging.InvalidAttributeValueType.String(), "", "yesterday", "signup"))
+	result, err := after(condition, user, s.mockLogger)
+	s.Error(err)
+	s.False(result)
+
+	// Test unsupported condition value
+	condition.Value = 1780315200
+	s.mockLogger.On("Warning", fmt.Sprintf(logging.UnsupportedConditionValue.String(), ""))
+	result, err = before(condition, user, s.mockLogger)
+	s.Error(err)
+	s.False(result)
+	s.mockLogger.AssertExpectations(s.T())
+}
+
+func (s *DateTimeTestSuite) TestBetweenMatcher() {
+	matcher, _ := Get(BetweenMatchType)
+	condition := entities.Condition{
+		Match: "between",
+		Value: []interface{}{"2026-06-01", "2026-06-30T23:59:59Z"},
+		Name:  "signup",
+	}
+
+	scenarios := []struct {
+		value    interface{}
+		expected bool
+	}{
+		{"2026-05-31T23:59:59Z", false},
+		{"2026-06-01", true},
+		{"2026-06-15T08:30:00-07:00", true},
+		{"2026-06-30T23:59:59Z", true},
+		{"2026-07-01", false},
+	}
+	for _, scenario := range scenarios {
+		user := entities.UserContext{
+			Attributes: map[string]interface{}{
+				"signup": scenario.value,
+			},
+		}
+		result, err := matcher(condition, user, s.mockLogger)
+		s.NoError(err)
+		s.Equal(scenario.expected, result, scenario.value)
+	}
+
+	// Test unsupported condition values
+	user := entities.UserContext{
+		Attributes: map[string]interface{}{
+			"signup": "2026-06-15",
+		},
+	}
+	s.mockLogger.On("Warning", fmt.Sprintf(logging.UnsupportedConditionValue.String(), ""))
+	for _, value := range []interface{}{"2026-06-01", []interface{}{"2026-06-01"}, []interface{}{"2026-06-01", "later"}} {
+		condition.Value = value
+		result, err := matcher(condition, user, s.mockLogger)
+		s.Error(err)
+		s.False(result)
+	}
+	s.mockLogger.AssertExpectations(s.T())
+}
+
+func TestDateTimeTestSuite(t *testing.T) {
+	suite.Run(t, new(DateTimeTestSuite))
+}
@@ -0,0 +1,129 @@
+/****************************************************************************
+ * Copyright 2019-2021, Optimizely, Inc. and contributors                   *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package geo //
+package geo
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+)
+
+// CIDREntry maps a network to a location
+type CIDREntry struct {
+	CIDR     string
+	Location Location
+}
+
+// cidrNode is a node of a binary trie over the bits of 16 byte IP addresses
+type cidrNode struct {
+	children [2]*cidrNode
+	location *Location
+}
+
+// CIDRLookup is a Lookup resolving IP addresses from a list of networks, the most specific network containing the
+// address wins
+type CIDRLookup struct {
+	root cidrNode
+}
+
+// NewCIDRLookup creates a CIDRLookup from the given entries
+func NewCIDRLookup(entries []CIDREntry) (*CIDRLookup, error) {
+	lookup := &CIDRLookup{}
+	for _, entry := range entries {
+		_, network, err := net.ParseCIDR(entry.CIDR)
+		if err != nil {
+			return nil, err
+		}
+		lookup.insert(network, entry.Location)
+	}
+	return lookup, nil
+}
+
+// ParseCIDRList creates a CIDRLookup from a list with one "network,country code[,region code]" entry per line, blank
+// lines and lines starting with # are skipped
+func ParseCIDRList(reader io.Reader) (*CIDRLookup, error) {
+	var entries []CIDREntry
+	scanner := bufio.NewScanner(reader)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, ",")
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("line %d: expected network,country code[,region code]", lineNumber)
+		}
+		entry := CIDREntry{CIDR: strings.TrimSpace(fields[0]), Location: Location{CountryCode: strings.TrimSpace(fields[1])}}
+		if len(fields) == 3 {
+			entry.Location.RegionCode = strings.TrimSpace(fields[2])
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return NewCIDRLookup(entries)
+}
+
+func (l *CIDRLookup) insert(network *net.IPNet, location Location) {
+	ones, bits := network.Mask.Size()
+	// IPv4 networks are stored in the IPv4-mapped IPv6 range
+	prefixLength := ones + (8*net.IPv6len - bits)
+	ip := network.IP.To16()
+
+	node := &l.root
+	for i := 0; i < prefixLength; i++ {
+		bit := ipBit(ip, i)
+		if node.children[bit] == nil {
+			node.children[bit] = &cidrNode{}
+		}
+		node = node.children[bit]
+	}
+	node.location = &location
+}
+
+// Locate returns the location of the most specific network containing the IP address
+func (l *CIDRLookup) Locate(ip net.IP) (Location, bool, error) {
+	ip = ip.To16()
+	if ip == nil {
+		return Location{}, false, fmt.Errorf("invalid IP address")
+	}
+
+	var location *Location
+	node := &l.root
+	for i := 0; node != nil; i++ {
+		if node.location != nil {
+			location = node.location
+		}
+		if i == 8*net.IPv6len {
+			break
+		}
+		node = node.children[ipBit(ip, i)]
+	}
+	if location == nil {
+		return Location{}, false, nil
+	}
+	return *location, true, nil
+}
+
+// ipBit returns the bit of the IP address at the given index, starting from the most significant bit
+func ipBit(ip net.IP, index int) int {
+	return int(ip[index/8]>>(7-uint(index%8))) & 1
+}
@@ -0,0 +1,75 @@
+/****************************************************************************
+ * Copyright 2019-2021, Optimizely, Inc. and contributors                   *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package geo
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCIDRLookupLocate(t *testing.T) {
+	lookup, err := ParseCIDRList(strings.NewReader(`
+# network,country code[,region code]
+10.0.0.0/8,US
+10.1.0.0/16, US, CA
+10.1.2.0/24,CA,QC
+2001:db8::/32,FR
+0.0.0.0/0,ZZ
+`))
+	require.NoError(t, err)
+
+	scenarios := []struct {
+		ip       string
+		location Location
+	}{
+		{"10.200.0.1", Location{CountryCode: "US"}},
+		{"10.1.200.1", Location{CountryCode: "US", RegionCode: "CA"}},
+		{"10.1.2.3", Location{CountryCode: "CA", RegionCode: "QC"}},
+		{"::ffff:10.1.2.3", Location{CountryCode: "CA", RegionCode: "QC"}},
+		{"11.0.0.1", Location{CountryCode: "ZZ"}},
+		{"2001:db8::1", Location{CountryCode: "FR"}},
+	}
+	for _, scenario := range scenarios {
+		location, found, err := lookup.Locate(net.ParseIP(scenario.ip))
+		assert.NoError(t, err)
+		assert.True(t, found, scenario.ip)
+		assert.Equal(t, scenario.location, location, scenario.ip)
+	}
+
+	// IPv4 networks do not contain IPv6 addresses
+	_, found, err := lookup.Locate(net.ParseIP("2001:db9::1"))
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	_, _, err = lookup.Locate(net.IP{1, 2})
+	assert.Error(t, err)
+}
+
+func TestCIDRLookupInvalidEntries(t *testing.T) {
+	_, err := NewCIDRLookup([]CIDREntry{{CIDR: "10.0.0.0", Location: Location{CountryCode: "US"}}})
+	assert.Error(t, err)
+
+	_, err = ParseCIDRList(strings.NewReader("10.0.0.0/8"))
+	assert.Error(t, err)
+
+	_, err = ParseCIDRList(strings.NewReader("10.0.0.0/8,US,CA,extra"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,36 @@
+/****************************************************************************
+ * Copyright 2019-2021, Optimizely, Inc. and contributors                   *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package geo provides audience matchers targeting the location of the user's IP address, resolved with a local
+// geo lookup. The matchers are not registered by default, see Register.
+package geo
+
+import "net"
+
+// Location is the location of an IP address
+type Location struct {
+	// CountryCode is the ISO 3166-1 alpha-2 code of the country, e.g. "US"
+	CountryCode string
+	// RegionCode is the ISO 3166-2 subdivision code of the region without the country prefix, e.g. "CA", it is empty
+	// when the lookup does not resolve regions
+	RegionCode string
+}
+
+// Lookup resolves the location of IP addresses, implementations must be safe for concurrent use
+type Lookup interface {
+	// Locate returns the location of the IP address, false when the address is not known to the lookup
+	Locate(ip net.IP) (Location, bool, error)
+}
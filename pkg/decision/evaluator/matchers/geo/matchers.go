@@ -0,0 +1,204 @@
+/****************************************************************************
+ * Copyright 2019-2021, Optimizely, Inc. and contributors                   *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package geo //
+package geo
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/optimizely/go-sdk/v2/pkg/decision/evaluator/matchers"
+	"github.com/optimizely/go-sdk/v2/pkg/entities"
+	"github.com/optimizely/go-sdk/v2/pkg/logging"
+)
+
+const (
+	// IPAttribute is the conventional name of the user attribute holding the IP address. The matchers read the IP
+	// address from the attribute named by the condition, any name can be used. Note that attributes prefixed with
+	// "$opt_" are sent with the events even when not defined in the datafile.
+	IPAttribute = "$opt_ip"
+
+	// CountryMatchType name for the "geo_country" matcher
+	CountryMatchType = "geo_country"
+	// RegionMatchType name for the "geo_region" matcher
+	RegionMatchType = "geo_region"
+	// IPInCIDRMatchType name for the "ip_in_cidr" matcher
+	IPInCIDRMatchType = "ip_in_cidr"
+)
+
+// Register registers the geo matchers in the default registry, resolving locations with the given lookup
+func Register(lookup Lookup) {
+	RegisterIn(matchers.DefaultRegistry(), lookup)
+}
+
+// RegisterIn registers the geo matchers in the given registry, resolving locations with the given lookup
+func RegisterIn(registry *matchers.MatcherRegistry, lookup Lookup) {
+	registry.Register(CountryMatchType, NewCountryMatcher(lookup))
+	registry.Register(RegionMatchType, NewRegionMatcher(lookup))
+	registry.RegisterCompiled(IPInCIDRMatchType, IPInCIDRMatcher, compileIPInCIDR)
+}
+
+// NewCountryMatcher returns a matcher for the "geo_country" match type. The condition value is an ISO 3166-1 alpha-2
+// country code or a list of them, the user's IP address must be located in one of the countries.
+func NewCountryMatcher(lookup Lookup) matchers.Matcher {
+	return newLocationMatcher(lookup, func(location Location) string {
+		return location.CountryCode
+	})
+}
+
+// NewRegionMatcher returns a matcher for the "geo_region" match type. The condition value is an ISO 3166-2 region
+// code, e.g. "US-CA", or a list of them, the user's IP address must be located in one of the regions.
+func NewRegionMatcher(lookup Lookup) matchers.Matcher {
+	return newLocationMatcher(lookup, func(location Location) string {
+		if location.CountryCode == "" || location.RegionCode == "" {
+			return ""
+		}
+		return location.CountryCode + "-" + location.RegionCode
+	})
+}
+
+// IPInCIDRMatcher matches against the "ip_in_cidr" match type. The condition value is a network in CIDR notation or a
+// list of them, the user's IP address must be in one of the networks.
+func IPInCIDRMatcher(condition entities.Condition, user entities.UserContext, logger logging.OptimizelyLogProducer) (bool, error) {
+	ip, err := getIPAttribute(condition, user, logger)
+	if err != nil {
+		return false, err
+	}
+
+	networks, err := parseNetworks(condition)
+	if err != nil {
+		logger.Warning(fmt.Sprintf(logging.UnsupportedConditionValue.String(), condition.StringRepresentation))
+		return false, err
+	}
+	return containsIP(networks, ip), nil
+}
+
+// compileIPInCIDR parses the networks of an "ip_in_cidr" condition once
+func compileIPInCIDR(condition entities.Condition, logger logging.OptimizelyLogProducer) matchers.CompiledMatcher {
+	networks, parseErr := parseNetworks(condition)
+	return func(user *entities.UserContext) (bool, error) {
+		ip, err := getIPAttribute(condition, *user, logger)
+		if err != nil {
+			return false, err
+		}
+		if parseErr != nil {
+			logger.Warning(fmt.Sprintf(logging.UnsupportedConditionValue.String(), condition.StringRepresentation))
+			return false, parseErr
+		}
+		return containsIP(networks, ip), nil
+	}
+}
+
+// parseNetworks returns the networks listed by the condition value
+func parseNetworks(condition entities.Condition) ([]*net.IPNet, error) {
+	values, ok := toStringList(condition.Value)
+	if !ok {
+		return nil, fmt.Errorf("audience condition %s evaluated to NULL because the condition value type is not supported", condition.Name)
+	}
+	networks := make([]*net.IPNet, 0, len(values))
+	for _, value := range values {
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("audience condition %s evaluated to NULL because the condition value is not a network: %w", condition.Name, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+func containsIP(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func newLocationMatcher(lookup Lookup, code func(Location) string) matchers.Matcher {
+	return func(condition entities.Condition, user entities.UserContext, logger logging.OptimizelyLogProducer) (bool, error) {
+		ip, err := getIPAttribute(condition, user, logger)
+		if err != nil {
+			return false, err
+		}
+
+		values, ok := toStringList(condition.Value)
+		if !ok {
+			logger.Warning(fmt.Sprintf(logging.UnsupportedConditionValue.String(), condition.StringRepresentation))
+			return false, fmt.Errorf("audience condition %s evaluated to NULL because the condition value type is not supported", condition.Name)
+		}
+
+		location, found, err := lookup.Locate(ip)
+		if err != nil {
+			return false, fmt.Errorf("audience condition %s evaluated to NULL because the IP address could not be located: %w", condition.Name, err)
+		}
+		if !found {
+			return false, nil
+		}
+		userCode := code(location)
+		for _, value := range values {
+			if userCode != "" && strings.EqualFold(value, userCode) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+}
+
+// getIPAttribute returns the IP address held by the condition attribute, as a string or a net.IP
+func getIPAttribute(condition entities.Condition, user entities.UserContext, logger logging.OptimizelyLogProducer) (net.IP, error) {
+	if !user.CheckAttributeExists(condition.Name) {
+		logger.Debug(fmt.Sprintf(logging.NullUserAttribute.String(), condition.StringRepresentation, condition.Name))
+		return nil, fmt.Errorf(`no attribute named "%s"`, condition.Name)
+	}
+
+	val, _ := user.GetAttribute(condition.Name)
+	var ip net.IP
+	switch typedValue := val.(type) {
+	case string:
+		ip = net.ParseIP(strings.TrimSpace(typedValue))
+	case net.IP:
+		ip = typedValue
+	}
+	if ip == nil || ip.To16() == nil {
+		logger.Warning(fmt.Sprintf(logging.InvalidAttributeValueType.String(), condition.StringRepresentation, val, condition.Name))
+		return nil, fmt.Errorf(`no IP address attribute named "%s"`, condition.Name)
+	}
+	return ip, nil
+}
+
+// toStringList returns the condition value as a list of strings, a single string being a list of one
+func toStringList(value interface{}) ([]string, bool) {
+	switch typedValue := value.(type) {
+	case string:
+		return []string{typedValue}, true
+	case []string:
+		return typedValue, true
+	case []interface{}:
+		values := make([]string, 0, len(typedValue))
+		for _, item := range typedValue {
+			stringItem, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			values = append(values, stringItem)
+		}
+		return values, true
+	}
+	return nil, false
+}
@@ -0,0 +1,160 @@
+/****************************************************************************
+ * Copyright 2019-2021, Optimizely, Inc. and contributors                   *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package geo
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/optimizely/go-sdk/v2/pkg/decision/evaluator/matchers"
+	"github.com/optimizely/go-sdk/v2/pkg/entities"
+	"github.com/optimizely/go-sdk/v2/pkg/logging"
+)
+
+type failingLookup struct{}
+
+func (failingLookup) Locate(net.IP) (Location, bool, error) {
+	return Location{}, false, errors.New("lookup failed")
+}
+
+func newTestLookup(t *testing.T) Lookup {
+	lookup, err := NewCIDRLookup([]CIDREntry{
+		{CIDR: "10.1.0.0/16", Location: Location{CountryCode: "US", RegionCode: "CA"}},
+		{CIDR: "10.2.0.0/16", Location: Location{CountryCode: "FR"}},
+	})
+	require.NoError(t, err)
+	return lookup
+}
+
+func ipUser(ip interface{}) entities.UserContext {
+	return entities.UserContext{Attributes: map[string]interface{}{IPAttribute: ip}}
+}
+
+func TestCountryMatcher(t *testing.T) {
+	logger := logging.GetLogger("", "GeoTest")
+	matcher := NewCountryMatcher(newTestLookup(t))
+	condition := entities.Condition{Match: CountryMatchType, Name: IPAttribute, Value: []interface{}{"us", "CA"}}
+
+	scenarios := []struct {
+		ip       interface{}
+		expected bool
+	}{
+		{"10.1.2.3", true},
+		{net.ParseIP("10.1.2.3"), true},
+		{"10.2.2.3", false},
+		{"192.168.0.1", false},
+	}
+	for _, scenario := range scenarios {
+		result, err := matcher(condition, ipUser(scenario.ip), logger)
+		assert.NoError(t, err)
+		assert.Equal(t, scenario.expected, result, scenario.ip)
+	}
+
+	condition.Value = "FR"
+	result, err := matcher(condition, ipUser("10.2.2.3"), logger)
+	assert.NoError(t, err)
+	assert.True(t, result)
+
+	// Test invalid IP address, missing attribute and unsupported condition value
+	_, err = matcher(condition, ipUser("10.2.2"), logger)
+	assert.Error(t, err)
+	_, err = matcher(condition, entities.UserContext{}, logger)
+	assert.Error(t, err)
+	condition.Value = 42
+	_, err = matcher(condition, ipUser("10.2.2.3"), logger)
+	assert.Error(t, err)
+
+	// Test lookup failure
+	condition.Value = "FR"
+	_, err = NewCountryMatcher(failingLookup{})(condition, ipUser("10.2.2.3"), logger)
+	assert.Error(t, err)
+}
+
+func TestRegionMatcher(t *testing.T) {
+	logger := logging.GetLogger("", "GeoTest")
+	matcher := NewRegionMatcher(newTestLookup(t))
+	condition := entities.Condition{Match: RegionMatchType, Name: IPAttribute, Value: "US-CA"}
+
+	result, err := matcher(condition, ipUser("10.1.2.3"), logger)
+	assert.NoError(t, err)
+	assert.True(t, result)
+
+	// the region is not known
+	condition.Value = []string{"FR-", "FR"}
+	result, err = matcher(condition, ipUser("10.2.2.3"), logger)
+	assert.NoError(t, err)
+	assert.False(t, result)
+}
+
+func TestIPInCIDRMatcher(t *testing.T) {
+	logger := logging.GetLogger("", "GeoTest")
+	condition := entities.Condition{Match: IPInCIDRMatchType, Name: "ip", Value: []interface{}{"10.0.0.0/8", "2001:db8::/32"}}
+	user := func(ip string) entities.UserContext {
+		return entities.UserContext{Attributes: map[string]interface{}{"ip": ip}}
+	}
+
+	for ip, expected := range map[string]bool{"10.1.2.3": true, "::ffff:10.1.2.3": true, "2001:db8::1": true, "11.1.2.3": false} {
+		result, err := IPInCIDRMatcher(condition, user(ip), logger)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result, ip)
+	}
+
+	condition.Value = "10.0.0.0"
+	_, err := IPInCIDRMatcher(condition, user("10.1.2.3"), logger)
+	assert.Error(t, err)
+}
+
+func TestCompileIPInCIDR(t *testing.T) {
+	logger := logging.GetLogger("", "GeoTest")
+	condition := entities.Condition{Match: IPInCIDRMatchType, Name: IPAttribute, Value: []interface{}{"10.0.0.0/8", "2001:db8::/32"}}
+	compiled := compileIPInCIDR(condition, logger)
+
+	for ip, expected := range map[string]bool{"10.1.2.3": true, "2001:db8::1": true, "11.1.2.3": false} {
+		user := ipUser(ip)
+		result, err := compiled(&user)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result, ip)
+	}
+
+	user := entities.UserContext{}
+	_, err := compiled(&user)
+	assert.Error(t, err)
+
+	condition.Value = []interface{}{"10.0.0.0/8", "10.0.0.0"}
+	user = ipUser("10.1.2.3")
+	_, err = compileIPInCIDR(condition, logger)(&user)
+	assert.Error(t, err)
+}
+
+func TestRegisterIn(t *testing.T) {
+	registry := matchers.NewMatcherRegistry()
+	RegisterIn(registry, newTestLookup(t))
+	for _, matchType := range []string{CountryMatchType, RegionMatchType, IPInCIDRMatchType} {
+		_, ok := registry.Get(matchType)
+		assert.True(t, ok, matchType)
+	}
+
+	matcher, _ := registry.Get(CountryMatchType)
+	condition := entities.Condition{Match: CountryMatchType, Name: IPAttribute, Value: "US"}
+	result, err := matcher(condition, ipUser("10.1.2.3"), logging.GetLogger("", "GeoTest"))
+	assert.NoError(t, err)
+	assert.True(t, result)
+}
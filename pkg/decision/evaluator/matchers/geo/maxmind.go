@@ -0,0 +1,396 @@
+/****************************************************************************
+ * Copyright 2019-2021, Optimizely, Inc. and contributors                   *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package geo //
+package geo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"os"
+)
+
+// metadataStartMarker precedes the metadata section at the end of a MaxMind DB file
+var metadataStartMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+const (
+	// metadataMaxSize bounds the search for the metadata section from the end of the file
+	metadataMaxSize = 128 * 1024
+	// dataSectionSeparatorSize is the size of the zeroed separator between the search tree and the data section
+	dataSectionSeparatorSize = 16
+	// maxDecodeDepth bounds the nesting of decoded values so that a corrupted file cannot loop through pointers
+	maxDecodeDepth = 32
+)
+
+// MaxMind DB data field types
+const (
+	typeExtended  = 0
+	typePointer   = 1
+	typeString    = 2
+	typeDouble    = 3
+	typeBytes     = 4
+	typeUint16    = 5
+	typeUint32    = 6
+	typeMap       = 7
+	typeInt32     = 8
+	typeUint64    = 9
+	typeUint128   = 10
+	typeArray     = 11
+	typeContainer = 12
+	typeEndMarker = 13
+	typeBool      = 14
+	typeFloat     = 15
+)
+
+// MaxMindMetadata is the metadata of a MaxMind DB file
+type MaxMindMetadata struct {
+	DatabaseType string
+	IPVersion    uint
+	NodeCount    uint
+	RecordSize   uint
+	BuildEpoch   uint
+}
+
+// MaxMindReader is a Lookup reading a database in the MaxMind DB format, such as the GeoIP2 and GeoLite2 country and
+// city databases. The country is read from the "country" record, falling back to "registered_country", and the region
+// from the first of the "subdivisions".
+type MaxMindReader struct {
+	Metadata MaxMindMetadata
+
+	tree      []byte
+	data      decoder
+	ipv4Start uint
+}
+
+// OpenMaxMindReader reads the database file at the given path
+func OpenMaxMindReader(path string) (*MaxMindReader, error) {
+	buffer, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewMaxMindReader(buffer)
+}
+
+// NewMaxMindReader creates a reader over a database in the MaxMind DB format
+func NewMaxMindReader(buffer []byte) (*MaxMindReader, error) {
+	searchStart := 0
+	if len(buffer) > metadataMaxSize {
+		searchStart = len(buffer) - metadataMaxSize
+	}
+	markerIndex := bytes.LastIndex(buffer[searchStart:], metadataStartMarker)
+	if markerIndex < 0 {
+		return nil, errors.New("invalid MaxMind DB file: metadata section not found")
+	}
+	metadataStart := searchStart + markerIndex + len(metadataStartMarker)
+
+	value, _, err := decoder{buffer: buffer[metadataStart:]}.decode(0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MaxMind DB metadata: %w", err)
+	}
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("invalid MaxMind DB metadata: not a map")
+	}
+	metadata := MaxMindMetadata{
+		IPVersion:  toUint(fields["ip_version"]),
+		NodeCount:  toUint(fields["node_count"]),
+		RecordSize: toUint(fields["record_size"]),
+		BuildEpoch: toUint(fields["build_epoch"]),
+	}
+	metadata.DatabaseType, _ = fields["database_type"].(string)
+
+	if metadata.RecordSize != 24 && metadata.RecordSize != 28 && metadata.RecordSize != 32 {
+		return nil, fmt.Errorf("invalid MaxMind DB metadata: unsupported record size %d", metadata.RecordSize)
+	}
+	if metadata.IPVersion != 4 && metadata.IPVersion != 6 {
+		return nil, fmt.Errorf("invalid MaxMind DB metadata: unsupported IP version %d", metadata.IPVersion)
+	}
+	treeSize := metadata.NodeCount * metadata.RecordSize / 4
+	dataStart := treeSize + dataSectionSeparatorSize
+	dataEnd := uint(searchStart + markerIndex)
+	if dataStart > dataEnd {
+		return nil, errors.New("invalid MaxMind DB file: search tree larger than the file")
+	}
+
+	reader := &MaxMindReader{
+		Metadata: metadata,
+		tree:     buffer[:treeSize],
+		data:     decoder{buffer: buffer[dataStart:dataEnd]},
+	}
+	if metadata.IPVersion == 6 {
+		// IPv4 addresses are looked up in the ::/96 subtree
+		node := uint(0)
+		for i := 0; i < 96 && node < metadata.NodeCount; i++ {
+			if node, err = reader.readNode(node, 0); err != nil {
+				return nil, err
+			}
+		}
+		reader.ipv4Start = node
+	}
+	return reader, nil
+}
+
+// Locate returns the location of the IP address
+func (r *MaxMindReader) Locate(ip net.IP) (Location, bool, error) {
+	record, found, err := r.Lookup(ip)
+	if err != nil || !found {
+		return Location{}, found, err
+	}
+
+	fields, _ := record.(map[string]interface{})
+	location := Location{CountryCode: isoCode(fields["country"])}
+	if location.CountryCode == "" {
+		location.CountryCode = isoCode(fields["registered_country"])
+	}
+	if subdivisions, ok := fields["subdivisions"].([]interface{}); ok && len(subdivisions) > 0 {
+		location.RegionCode = isoCode(subdivisions[0])
+	}
+	return location, true, nil
+}
+
+// Lookup returns the decoded data record of the IP address, maps are decoded as map[string]interface{}, arrays as
+// []interface{}, unsigned integers as uint64, int32 as int64 and uint128 as *big.Int
+func (r *MaxMindReader) Lookup(ip net.IP) (record interface{}, found bool, err error) {
+	node := uint(0)
+	if ipv4 := ip.To4(); ipv4 != nil {
+		ip = ipv4
+		node = r.ipv4Start
+	} else if ip = ip.To16(); ip == nil {
+		return nil, false, errors.New("invalid IP address")
+	} else if r.Metadata.IPVersion == 4 {
+		return nil, false, nil
+	}
+
+	for i := 0; i < 8*len(ip) && node < r.Metadata.NodeCount; i++ {
+		if node, err = r.readNode(node, ipBit(ip, i)); err != nil {
+			return nil, false, err
+		}
+	}
+	switch {
+	case node == r.Metadata.NodeCount:
+		return nil, false, nil
+	case node < r.Metadata.NodeCount:
+		return nil, false, errors.New("invalid MaxMind DB file: search tree deeper than the address")
+	}
+
+	offset := node - r.Metadata.NodeCount - dataSectionSeparatorSize
+	record, _, err = r.data.decode(offset, 0)
+	if err != nil {
+		return nil, false, err
+	}
+	return record, true, nil
+}
+
+// readNode returns the left (bit 0) or right (bit 1) record of the node
+func (r *MaxMindReader) readNode(node uint, bit int) (uint, error) {
+	recordSize := r.Metadata.RecordSize
+	start := node * recordSize / 4
+	if start+recordSize/4 > uint(len(r.tree)) {
+		return 0, errors.New("invalid MaxMind DB file: node outside of the search tree")
+	}
+	b := r.tree[start : start+recordSize/4]
+	switch recordSize {
+	case 24:
+		b = b[3*bit:]
+		return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2]), nil
+	case 28:
+		if bit == 0 {
+			return uint(b[3]&0xF0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2]), nil
+		}
+		return uint(b[3]&0x0F)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6]), nil
+	default:
+		return uint(binary.BigEndian.Uint32(b[4*bit:])), nil
+	}
+}
+
+// decoder decodes the MaxMind DB data format, pointers are offsets in its buffer
+type decoder struct {
+	buffer []byte
+}
+
+func (d decoder) bytes(offset, size uint) ([]byte, error) {
+	if offset+size > uint(len(d.buffer)) || offset+size < offset {
+		return nil, errors.New("invalid MaxMind DB file: data outside of the data section")
+	}
+	return d.buffer[offset : offset+size], nil
+}
+
+// decode returns the value at offset and the offset following it
+func (d decoder) decode(offset uint, depth int) (interface{}, uint, error) {
+	if depth > maxDecodeDepth {
+		return nil, 0, errors.New("invalid MaxMind DB file: data nested too deeply")
+	}
+	control, err := d.bytes(offset, 1)
+	if err != nil {
+		return nil, 0, err
+	}
+	offset++
+	fieldType := uint(control[0] >> 5)
+
+	if fieldType == typePointer {
+		pointerSize := uint(control[0]>>3)&0x3 + 1
+		b, err := d.bytes(offset, pointerSize)
+		if err != nil {
+			return nil, 0, err
+		}
+		var pointer uint
+		switch pointerSize {
+		case 1:
+			pointer = uint(control[0]&0x7)<<8 | uint(b[0])
+		case 2:
+			pointer = (uint(control[0]&0x7)<<16 | uint(b[0])<<8 | uint(b[1])) + 2048
+		case 3:
+			pointer = (uint(control[0]&0x7)<<24 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])) + 526336
+		default:
+			pointer = uint(binary.BigEndian.Uint32(b))
+		}
+		value, _, err := d.decode(pointer, depth+1)
+		return value, offset + pointerSize, err
+	}
+
+	if fieldType == typeExtended {
+		b, err := d.bytes(offset, 1)
+		if err != nil {
+			return nil, 0, err
+		}
+		offset++
+		fieldType = 7 + uint(b[0])
+	}
+
+	size := uint(control[0] & 0x1f)
+	if size >= 29 {
+		extraBytes := size - 28
+		b, err := d.bytes(offset, extraBytes)
+		if err != nil {
+			return nil, 0, err
+		}
+		offset += extraBytes
+		switch extraBytes {
+		case 1:
+			size = 29 + uint(b[0])
+		case 2:
+			size = 285 + (uint(b[0])<<8 | uint(b[1]))
+		default:
+			size = 65821 + (uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2]))
+		}
+	}
+
+	switch fieldType {
+	case typeMap:
+		fields := make(map[string]interface{}, size)
+		for i := uint(0); i < size; i++ {
+			key, next, err := d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			stringKey, ok := key.(string)
+			if !ok {
+				return nil, 0, errors.New("invalid MaxMind DB file: map key is not a string")
+			}
+			value, next, err := d.decode(next, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			fields[stringKey] = value
+			offset = next
+		}
+		return fields, offset, nil
+	case typeArray:
+		values := make([]interface{}, 0, size)
+		for i := uint(0); i < size; i++ {
+			value, next, err := d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			values = append(values, value)
+			offset = next
+		}
+		return values, offset, nil
+	case typeBool:
+		return size != 0, offset, nil
+	}
+
+	b, err := d.bytes(offset, size)
+	if err != nil {
+		return nil, 0, err
+	}
+	offset += size
+	switch fieldType {
+	case typeString:
+		return string(b), offset, nil
+	case typeBytes:
+		return append([]byte(nil), b...), offset, nil
+	case typeDouble:
+		if size != 8 {
+			return nil, 0, errors.New("invalid MaxMind DB file: double of invalid size")
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), offset, nil
+	case typeFloat:
+		if size != 4 {
+			return nil, 0, errors.New("invalid MaxMind DB file: float of invalid size")
+		}
+		return math.Float32frombits(binary.BigEndian.Uint32(b)), offset, nil
+	case typeUint16, typeUint32, typeUint64:
+		maxSize := uint(2)
+		if fieldType == typeUint32 {
+			maxSize = 4
+		} else if fieldType == typeUint64 {
+			maxSize = 8
+		}
+		if size > maxSize {
+			return nil, 0, errors.New("invalid MaxMind DB file: unsigned integer of invalid size")
+		}
+		var value uint64
+		for _, byteValue := range b {
+			value = value<<8 | uint64(byteValue)
+		}
+		return value, offset, nil
+	case typeInt32:
+		if size > 4 {
+			return nil, 0, errors.New("invalid MaxMind DB file: int32 of invalid size")
+		}
+		var value uint32
+		for _, byteValue := range b {
+			value = value<<8 | uint32(byteValue)
+		}
+		return int64(int32(value)), offset, nil
+	case typeUint128:
+		if size > 16 {
+			return nil, 0, errors.New("invalid MaxMind DB file: uint128 of invalid size")
+		}
+		return new(big.Int).SetBytes(b), offset, nil
+	default:
+		return nil, 0, fmt.Errorf("invalid MaxMind DB file: unsupported data type %d", fieldType)
+	}
+}
+
+func toUint(value interface{}) uint {
+	if number, ok := value.(uint64); ok {
+		return uint(number)
+	}
+	return 0
+}
+
+func isoCode(value interface{}) string {
+	fields, _ := value.(map[string]interface{})
+	code, _ := fields["iso_code"].(string)
+	return code
+}
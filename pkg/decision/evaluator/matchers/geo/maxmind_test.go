@@ -0,0 +1,341 @@
+/****************************************************************************
+ * Copyright 2019-2021, Optimizely, Inc. and contributors                   *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package geo
+
+import (
+	"encoding/binary"
+	"math"
+	"math/big"
+	"net"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mmdbPointer encodes a pointer to the given offset of the data section
+type mmdbPointer uint
+
+// encodeControl encodes the control byte of a field and its size
+func encodeControl(fieldType int, size int) []byte {
+	var sizeBits byte
+	var extra []byte
+	switch {
+	case size < 29:
+		sizeBits = byte(size)
+	case size < 285:
+		sizeBits, extra = 29, []byte{byte(size - 29)}
+	case size < 65821:
+		sizeBits, extra = 30, []byte{byte((size - 285) >> 8), byte(size - 285)}
+	default:
+		size -= 65821
+		sizeBits, extra = 31, []byte{byte(size >> 16), byte(size >> 8), byte(size)}
+	}
+	var encoded []byte
+	if fieldType <= typeMap {
+		encoded = []byte{byte(fieldType<<5) | sizeBits}
+	} else {
+		encoded = []byte{sizeBits, byte(fieldType - 7)}
+	}
+	return append(encoded, extra...)
+}
+
+func encodeUint(fieldType int, value uint64) []byte {
+	var b []byte
+	for ; value > 0; value >>= 8 {
+		b = append([]byte{byte(value)}, b...)
+	}
+	return append(encodeControl(fieldType, len(b)), b...)
+}
+
+// encodeValue encodes a value in the MaxMind DB data format
+func encodeValue(value interface{}) []byte {
+	switch typedValue := value.(type) {
+	case string:
+		return append(encodeControl(typeString, len(typedValue)), typedValue...)
+	case uint16:
+		return encodeUint(typeUint16, uint64(typedValue))
+	case uint32:
+		return encodeUint(typeUint32, uint64(typedValue))
+	case uint64:
+		return encodeUint(typeUint64, typedValue)
+	case int32:
+		b := make([]byte, 4)
+		binary.BigEndian.PutUint32(b, uint32(typedValue))
+		return append(encodeControl(typeInt32, 4), b...)
+	case float64:
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, math.Float64bits(typedValue))
+		return append(encodeControl(typeDouble, 8), b...)
+	case bool:
+		if typedValue {
+			return encodeControl(typeBool, 1)
+		}
+		return encodeControl(typeBool, 0)
+	case mmdbPointer:
+		if typedValue < 2048 {
+			return []byte{byte(typePointer<<5) | byte(typedValue>>8), byte(typedValue)}
+		}
+		typedValue -= 2048
+		return []byte{byte(typePointer<<5) | 1<<3 | byte(typedValue>>16), byte(typedValue >> 8), byte(typedValue)}
+	case []interface{}:
+		encoded := encodeControl(typeArray, len(typedValue))
+		for _, item := range typedValue {
+			encoded = append(encoded, encodeValue(item)...)
+		}
+		return encoded
+	case map[string]interface{}:
+		keys := make([]string, 0, len(typedValue))
+		for key := range typedValue {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		encoded := encodeControl(typeMap, len(typedValue))
+		for _, key := range keys {
+			encoded = append(encoded, encodeValue(key)...)
+			encoded = append(encoded, encodeValue(typedValue[key])...)
+		}
+		return encoded
+	}
+	panic("unsupported value")
+}
+
+type testNode struct {
+	children [2]*testNode
+	record   interface{}
+}
+
+// writeTestDatabase writes a database in the MaxMind DB format, the records are prefixed with the data given
+func writeTestDatabase(ipVersion, recordSize int, prefix []interface{}, records map[string]interface{}) []byte {
+	// networks are inserted from the least specific, the records of a network are pushed down to the more specific
+	// networks it contains as records can only be leaves of the search tree
+	networks := make([]*net.IPNet, 0, len(records))
+	for cidr := range records {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	sort.Slice(networks, func(i, j int) bool {
+		iOnes, _ := networks[i].Mask.Size()
+		jOnes, _ := networks[j].Mask.Size()
+		return iOnes < jOnes
+	})
+
+	root := &testNode{}
+	for _, network := range networks {
+		ones, bits := network.Mask.Size()
+		ip := network.IP
+		if ipVersion == 6 && bits == 32 {
+			ip, ones = append(make(net.IP, 12), ip.To4()...), ones+96
+		} else if ipVersion == 4 && bits == 128 {
+			continue
+		}
+		node := root
+		for i := 0; i < ones; i++ {
+			if node.record != nil {
+				node.children = [2]*testNode{{record: node.record}, {record: node.record}}
+				node.record = nil
+			}
+			bit := ipBit(ip, i)
+			if node.children[bit] == nil {
+				node.children[bit] = &testNode{}
+			}
+			node = node.children[bit]
+		}
+		node.record = records[network.String()]
+	}
+
+	var data []byte
+	for _, value := range prefix {
+		data = append(data, encodeValue(value)...)
+	}
+	var nodes []*testNode
+	offsets := map[*testNode]int{}
+	var index func(node *testNode)
+	index = func(node *testNode) {
+		if node.record != nil {
+			offsets[node] = len(data)
+			data = append(data, encodeValue(node.record)...)
+			return
+		}
+		offsets[node] = len(nodes)
+		nodes = append(nodes, node)
+		for _, child := range node.children {
+			if child != nil {
+				index(child)
+			}
+		}
+	}
+	index(root)
+
+	var tree []byte
+	for _, node := range nodes {
+		var values [2]uint32
+		for bit, child := range node.children {
+			switch {
+			case child == nil:
+				values[bit] = uint32(len(nodes))
+			case child.record != nil:
+				values[bit] = uint32(len(nodes) + dataSectionSeparatorSize + offsets[child])
+			default:
+				values[bit] = uint32(offsets[child])
+			}
+		}
+		switch recordSize {
+		case 24:
+			tree = append(tree, byte(values[0]>>16), byte(values[0]>>8), byte(values[0]),
+				byte(values[1]>>16), byte(values[1]>>8), byte(values[1]))
+		case 28:
+			tree = append(tree, byte(values[0]>>16), byte(values[0]>>8), byte(values[0]),
+				byte(values[0]>>20&0xF0|values[1]>>24&0x0F), byte(values[1]>>16), byte(values[1]>>8), byte(values[1]))
+		default:
+			tree = binary.BigEndian.AppendUint32(tree, values[0])
+			tree = binary.BigEndian.AppendUint32(tree, values[1])
+		}
+	}
+
+	database := append(tree, make([]byte, dataSectionSeparatorSize)...)
+	database = append(database, data...)
+	database = append(database, metadataStartMarker...)
+	return append(database, encodeValue(map[string]interface{}{
+		"binary_format_major_version": uint16(2),
+		"build_epoch":                 uint64(1780000000),
+		"database_type":               "Test-Country",
+		"ip_version":                  uint16(ipVersion),
+		"node_count":                  uint32(len(nodes)),
+		"record_size":                 uint16(recordSize),
+	})...)
+}
+
+func testCountry(code string) map[string]interface{} {
+	return map[string]interface{}{"iso_code": code, "names": map[string]interface{}{"en": code}}
+}
+
+func TestMaxMindReaderLocate(t *testing.T) {
+	records := map[string]interface{}{
+		"1.2.0.0/16": map[string]interface{}{"country": testCountry("US"), "subdivisions": []interface{}{testCountry("CA")}},
+		"1.2.3.0/24": map[string]interface{}{"country": testCountry("CA")},
+		// the country is shared through a pointer and falls back to the registered country
+		"5.6.0.0/16":     map[string]interface{}{"registered_country": mmdbPointer(0)},
+		"2001:db8::/32":  map[string]interface{}{"country": testCountry("FR")},
+		"2001:db8::/126": map[string]interface{}{"country": testCountry("DE")},
+	}
+	prefix := []interface{}{testCountry("GB")}
+
+	for _, recordSize := range []int{24, 28, 32} {
+		for _, ipVersion := range []int{4, 6} {
+			reader, err := NewMaxMindReader(writeTestDatabase(ipVersion, recordSize, prefix, records))
+			require.NoError(t, err)
+			assert.Equal(t, "Test-Country", reader.Metadata.DatabaseType)
+			assert.Equal(t, uint(ipVersion), reader.Metadata.IPVersion)
+			assert.Equal(t, uint(1780000000), reader.Metadata.BuildEpoch)
+
+			scenarios := []struct {
+				ip       string
+				location Location
+				found    bool
+			}{
+				{"1.2.200.1", Location{CountryCode: "US", RegionCode: "CA"}, true},
+				{"1.2.3.4", Location{CountryCode: "CA"}, true},
+				{"::ffff:1.2.3.4", Location{CountryCode: "CA"}, true},
+				{"5.6.7.8", Location{CountryCode: "GB"}, true},
+				{"9.9.9.9", Location{}, false},
+				{"2001:db8::1", Location{CountryCode: "DE"}, ipVersion == 6},
+				{"2001:db8:1::1", Location{CountryCode: "FR"}, ipVersion == 6},
+				{"2001:db9::1", Location{}, false},
+			}
+			for _, scenario := range scenarios {
+				location, found, err := reader.Locate(net.ParseIP(scenario.ip))
+				assert.NoError(t, err)
+				assert.Equal(t, scenario.found, found, scenario.ip)
+				if scenario.found {
+					assert.Equal(t, scenario.location, location, scenario.ip)
+				}
+			}
+		}
+	}
+}
+
+func TestMaxMindReaderInvalidDatabase(t *testing.T) {
+	_, err := NewMaxMindReader([]byte("not a database"))
+	assert.Error(t, err)
+
+	database := writeTestDatabase(4, 24, nil, map[string]interface{}{"1.2.0.0/16": "US"})
+	_, err = NewMaxMindReader(database[len(database)-20:])
+	assert.Error(t, err)
+
+	// the root node points outside of the data section
+
+	// a record pointing outside of the data section
+	copy(database, []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF})
+	reader, err := NewMaxMindReader(database)
+	require.NoError(t, err)
+	_, _, err = reader.Lookup(net.ParseIP("1.2.3.4"))
+	assert.Error(t, err)
+
+	_, err = OpenMaxMindReader("testdata/missing.mmdb")
+	assert.Error(t, err)
+}
+
+func TestDecoderDataTypes(t *testing.T) {
+	longString := string(make([]byte, 300))
+	value := map[string]interface{}{
+		"array":  []interface{}{uint16(1), uint32(70000), uint64(1 << 40)},
+		"bool":   true,
+		"double": 1.5,
+		"int32":  int32(-7),
+		"long":   longString,
+	}
+	decoded, next, err := decoder{buffer: encodeValue(value)}.decode(0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint(len(encodeValue(value))), next)
+	assert.Equal(t, map[string]interface{}{
+		"array":  []interface{}{uint64(1), uint64(70000), uint64(1 << 40)},
+		"bool":   true,
+		"double": 1.5,
+		"int32":  int64(-7),
+		"long":   longString,
+	}, decoded)
+
+	// uint128 and float
+	buffer := append(encodeControl(typeUint128, 3), 1, 0, 0)
+	buffer = append(buffer, append(encodeControl(typeFloat, 4), 0x3F, 0xC0, 0, 0)...)
+	decoded, next, err = decoder{buffer: buffer}.decode(0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(1<<16), decoded)
+	decoded, _, err = decoder{buffer: buffer}.decode(next, 0)
+	require.NoError(t, err)
+	assert.Equal(t, float32(1.5), decoded)
+
+	// pointers beyond the first 2048 bytes
+	buffer = append(make([]byte, 0, 3000), encodeValue(mmdbPointer(2500))...)
+	for len(buffer) < 2500 {
+		buffer = append(buffer, 0)
+	}
+	buffer = append(buffer, encodeValue("far")...)
+	decoded, next, err = decoder{buffer: buffer}.decode(0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "far", decoded)
+	assert.Equal(t, uint(3), next)
+
+	// a pointer to itself
+	_, _, err = decoder{buffer: encodeValue(mmdbPointer(0))}.decode(0, 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,112 @@
+/****************************************************************************
+ * Copyright 2019-2021, Optimizely, Inc. and contributors                   *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package matchers //
+package matchers
+
+import (
+	"fmt"
+
+	"github.com/optimizely/go-sdk/v2/pkg/decision/evaluator/matchers/utils"
+	"github.com/optimizely/go-sdk/v2/pkg/entities"
+	"github.com/optimizely/go-sdk/v2/pkg/logging"
+)
+
+// InMatcher matches against the "in" match type, the condition value is a list of strings, numbers or booleans
+func InMatcher(condition entities.Condition, user entities.UserContext, logger logging.OptimizelyLogProducer) (bool, error) {
+	return isInList(condition, user, logger)
+}
+
+// NotInMatcher matches against the "not_in" match type, it evaluates to NULL whenever "in" does
+func NotInMatcher(condition entities.Condition, user entities.UserContext, logger logging.OptimizelyLogProducer) (bool, error) {
+	found, err := isInList(condition, user, logger)
+	if err != nil {
+		return false, err
+	}
+	return !found, nil
+}
+
+func isInList(condition entities.Condition, user entities.UserContext, logger logging.OptimizelyLogProducer) (bool, error) {
+	if !user.CheckAttributeExists(condition.Name) {
+		logger.Debug(fmt.Sprintf(logging.NullUserAttribute.String(), condition.StringRepresentation, condition.Name))
+		return false, fmt.Errorf(`no attribute named "%s"`, condition.Name)
+	}
+
+	values, ok := toList(condition.Value)
+	if !ok {
+		logger.Warning(fmt.Sprintf(logging.UnsupportedConditionValue.String(), condition.StringRepresentation))
+		return false, fmt.Errorf("audience condition %s evaluated to NULL because the condition value type is not supported", condition.Name)
+	}
+
+	attributeValue, _ := user.GetAttribute(condition.Name)
+	var isEqual func(value interface{}) bool
+	switch typedAttribute := attributeValue.(type) {
+	case string:
+		isEqual = func(value interface{}) bool {
+			stringValue, ok := value.(string)
+			return ok && stringValue == typedAttribute
+		}
+	case bool:
+		isEqual = func(value interface{}) bool {
+			boolValue, ok := value.(bool)
+			return ok && boolValue == typedAttribute
+		}
+	default:
+		floatAttribute, isFloat := utils.ToFloat(attributeValue)
+		if !isFloat {
+			logger.Warning(fmt.Sprintf(logging.InvalidAttributeValueType.String(), condition.StringRepresentation, attributeValue, condition.Name))
+			return false, fmt.Errorf(`no string, number or bool attribute named "%s"`, condition.Name)
+		}
+		isEqual = func(value interface{}) bool {
+			floatValue, ok := utils.ToFloat(value)
+			return ok && floatValue == floatAttribute
+		}
+	}
+
+	for _, value := range values {
+		if isEqual(value) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// toList returns the condition value as a list when it only holds strings, numbers and booleans
+func toList(value interface{}) ([]interface{}, bool) {
+	var values []interface{}
+	switch typedValue := value.(type) {
+	case []interface{}:
+		values = typedValue
+	case []string:
+		for _, item := range typedValue {
+			values = append(values, item)
+		}
+		return values, true
+	default:
+		return nil, false
+	}
+
+	for _, item := range values {
+		switch item.(type) {
+		case string, bool:
+		default:
+			if _, ok := utils.ToFloat(item); !ok {
+				return nil, false
+			}
+		}
+	}
+	return values, true
+}
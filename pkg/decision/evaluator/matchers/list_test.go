@@ -0,0 +1,146 @@
+/****************************************************************************
+ * Copyright 2019-2021, Optimizely, Inc. and contributors                   *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package matchers
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/optimizely/go-sdk/v2/pkg/entities"
+	"github.com/optimizely/go-sdk/v2/pkg/logging"
+)
+
+type ListTestSuite struct {
+	suite.Suite
+	mockLogger *MockLogger
+}
+
+func (s *ListTestSuite) SetupTest() {
+	s.mockLogger = new(MockLogger)
+}
+
+func (s *ListTestSuite) TestInMatcher() {
+	matcher, _ := Get(InMatchType)
+	condition := entities.Condition{
+		Match: "in",
+		Value: []interface{}{"us", float64(42), true},
+		Name:  "attr",
+	}
+
+	scenarios := []struct {
+		value    interface{}
+		expected bool
+	}{
+		{"us", true},
+		{"ca", false},
+		{42, true},
+		{int64(42), true},
+		{42.5, false},
+		{true, true},
+		{false, false},
+		{"true", false},
+	}
+	for _, scenario := range scenarios {
+		user := entities.UserContext{
+			Attributes: map[string]interface{}{
+				"attr": scenario.value,
+			},
+		}
+		result, err := matcher(condition, user, s.mockLogger)
+		s.NoError(err)
+		s.Equal(scenario.expected, result, scenario.value)
+	}
+
+	// Test string list
+	condition.Value = []string{"us", "ca"}
+	user := entities.UserContext{
+		Attributes: map[string]interface{}{
+			"attr": "ca",
+		},
+	}
+	result, err := matcher(condition, user, s.mockLogger)
+	s.NoError(err)
+	s.True(result)
+
+	// Test attribute not found
+	s.mockLogger.On("Debug", fmt.Sprintf(logging.NullUserAttribute.String(), "", "attr"))
+	_, err = matcher(condition, entities.UserContext{}, s.mockLogger)
+	s.Error(err)
+
+	// Test attribute of unsupported type
+	user.Attributes["attr"] = []string{"us"}
+	s.mockLogger.On("Warning", fmt.Sprintf(logging.InvalidAttributeValueType.String(), "", []string{"us"}, "attr"))
+	result, err = matcher(condition, user, s.mockLogger)
+	s.Error(err)
+	s.False(result)
+	s.mockLogger.AssertExpectations(s.T())
+}
+
+func (s *ListTestSuite) TestNotInMatcher() {
+	matcher, _ := Get(NotInMatchType)
+	condition := entities.Condition{
+		Match: "not_in",
+		Value: []interface{}{"us", "ca"},
+		Name:  "country",
+	}
+
+	// Test match
+	user := entities.UserContext{
+		Attributes: map[string]interface{}{
+			"country": "fr",
+		},
+	}
+	result, err := matcher(condition, user, s.mockLogger)
+	s.NoError(err)
+	s.True(result)
+
+	// Test no match
+	user.Attributes["country"] = "us"
+	result, err = matcher(condition, user, s.mockLogger)
+	s.NoError(err)
+	s.False(result)
+
+	// Test attribute not found evaluates to NULL rather than true
+	s.mockLogger.On("Debug", fmt.Sprintf(logging.NullUserAttribute.String(), "", "country"))
+	result, err = matcher(condition, entities.UserContext{}, s.mockLogger)
+	s.Error(err)
+	s.False(result)
+	s.mockLogger.AssertExpectations(s.T())
+}
+
+func (s *ListTestSuite) TestListMatcherUnsupportedConditionValue() {
+	matcher, _ := Get(InMatchType)
+	user := entities.UserContext{
+		Attributes: map[string]interface{}{
+			"country": "us",
+		},
+	}
+	s.mockLogger.On("Warning", fmt.Sprintf(logging.UnsupportedConditionValue.String(), ""))
+	for _, value := range []interface{}{"us", []interface{}{"us", []interface{}{"ca"}}, nil} {
+		condition := entities.Condition{Match: "in", Value: value, Name: "country"}
+		result, err := matcher(condition, user, s.mockLogger)
+		s.Error(err)
+		s.False(result)
+	}
+	s.mockLogger.AssertExpectations(s.T())
+}
+
+func TestListTestSuite(t *testing.T) {
+	suite.Run(t, new(ListTestSuite))
+}
@@ -0,0 +1,113 @@
+/****************************************************************************
+ * Copyright 2019-2021, Optimizely, Inc. and contributors                   *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package matchers //
+package matchers
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/optimizely/go-sdk/v2/pkg/entities"
+	"github.com/optimizely/go-sdk/v2/pkg/logging"
+)
+
+const (
+	// MaxRegexPatternLength is the longest regex pattern the "regex" matcher compiles
+	MaxRegexPatternLength = 1024
+	// MaxRegexInputLength is the longest attribute value the "regex" matcher evaluates
+	MaxRegexInputLength = 4096
+	// regexCacheSize bounds the number of compiled patterns kept
+	regexCacheSize = 512
+)
+
+// compiledRegex is a cached compilation result, invalid patterns are cached with their error
+type compiledRegex struct {
+	regex *regexp.Regexp
+	err   error
+}
+
+// regexCache keeps the patterns compiled by the "regex" matcher since audiences are evaluated for every decision
+type regexCache struct {
+	lock     sync.RWMutex
+	compiled map[string]compiledRegex
+	size     int
+}
+
+var defaultRegexCache = newRegexCache(regexCacheSize)
+
+func newRegexCache(size int) *regexCache {
+	return &regexCache{compiled: make(map[string]compiledRegex), size: size}
+}
+
+func (c *regexCache) get(pattern string) (*regexp.Regexp, error) {
+	c.lock.RLock()
+	entry, ok := c.compiled[pattern]
+	c.lock.RUnlock()
+	if ok {
+		return entry.regex, entry.err
+	}
+
+	if len(pattern) > MaxRegexPatternLength {
+		entry.err = fmt.Errorf("regex pattern is longer than %d characters", MaxRegexPatternLength)
+	} else {
+		entry.regex, entry.err = regexp.Compile(pattern)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if len(c.compiled) >= c.size {
+		// Evict an arbitrary pattern, the patterns in use are recompiled at most once per decision
+		for key := range c.compiled {
+			delete(c.compiled, key)
+			break
+		}
+	}
+	c.compiled[pattern] = entry
+	return entry.regex, entry.err
+}
+
+// RegexMatcher matches against the "regex" match type. Patterns use the RE2 syntax of the regexp package, which
+// evaluates in time linear to the input so a pattern cannot stall a decision, and both the pattern and the attribute
+// value are bounded in length.
+func RegexMatcher(condition entities.Condition, user entities.UserContext, logger logging.OptimizelyLogProducer) (bool, error) {
+	if !user.CheckAttributeExists(condition.Name) {
+		logger.Debug(fmt.Sprintf(logging.NullUserAttribute.String(), condition.StringRepresentation, condition.Name))
+		return false, fmt.Errorf(`no attribute named "%s"`, condition.Name)
+	}
+
+	if pattern, ok := condition.Value.(string); ok {
+		regex, err := defaultRegexCache.get(pattern)
+		if err != nil {
+			logger.Warning(fmt.Sprintf(logging.UnsupportedConditionValue.String(), condition.StringRepresentation))
+			return false, fmt.Errorf("audience condition %s evaluated to NULL because the regex pattern is invalid: %w", condition.Name, err)
+		}
+		attributeValue, err := user.GetStringAttribute(condition.Name)
+		if err != nil {
+			val, _ := user.GetAttribute(condition.Name)
+			logger.Warning(fmt.Sprintf(logging.InvalidAttributeValueType.String(), condition.StringRepresentation, val, condition.Name))
+			return false, err
+		}
+		if len(attributeValue) > MaxRegexInputLength {
+			return false, fmt.Errorf("audience condition %s evaluated to NULL because the attribute value is longer than %d characters", condition.Name, MaxRegexInputLength)
+		}
+		return regex.MatchString(attributeValue), nil
+	}
+
+	logger.Warning(fmt.Sprintf(logging.UnsupportedConditionValue.String(), condition.StringRepresentation))
+	return false, fmt.Errorf("audience condition %s evaluated to NULL because the condition value type is not supported", condition.Name)
+}
@@ -0,0 +1,145 @@
+/****************************************************************************
+ * Copyright 2019-2021, Optimizely, Inc. and contributors                   *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package matchers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/optimizely/go-sdk/v2/pkg/entities"
+	"github.com/optimizely/go-sdk/v2/pkg/logging"
+)
+
+type RegexTestSuite struct {
+	suite.Suite
+	mockLogger *MockLogger
+	matcher    Matcher
+}
+
+func (s *RegexTestSuite) SetupTest() {
+	s.mockLogger = new(MockLogger)
+	s.matcher, _ = Get(RegexMatchType)
+}
+
+func (s *RegexTestSuite) TestRegexMatcher() {
+	condition := entities.Condition{
+		Match: "regex",
+		Value: `^user-\d+@example\.com$`,
+		Name:  "email",
+	}
+
+	// Test match
+	user := entities.UserContext{
+		Attributes: map[string]interface{}{
+			"email": "user-42@example.com",
+		},
+	}
+	result, err := s.matcher(condition, user, s.mockLogger)
+	s.NoError(err)
+	s.True(result)
+
+	// Test no match
+	user.Attributes["email"] = "admin@example.com"
+	result, err = s.matcher(condition, user, s.mockLogger)
+	s.NoError(err)
+	s.False(result)
+
+	// Test attribute not found
+	s.mockLogger.On("Debug", fmt.Sprintf(logging.NullUserAttribute.String(), "", "email"))
+	_, err = s.matcher(condition, entities.UserContext{}, s.mockLogger)
+	s.Error(err)
+
+	// Test attribute of different type
+	user.Attributes["email"] = 42
+	s.mockLogger.On("Warning", fmt.Sprintf(logging.InvalidAttributeValueType.String(), "", 42, "email"))
+	result, err = s.matcher(condition, user, s.mockLogger)
+	s.Error(err)
+	s.False(result)
+	s.mockLogger.AssertExpectations(s.T())
+}
+
+func (s *RegexTestSuite) TestRegexMatcherLimits() {
+	// Test input too long to be evaluated
+	condition := entities.Condition{Match: "regex", Value: `(a+)+$`, Name: "input"}
+	user := entities.UserContext{
+		Attributes: map[string]interface{}{
+			"input": strings.Repeat("a", MaxRegexInputLength+1),
+		},
+	}
+	result, err := s.matcher(condition, user, s.mockLogger)
+	s.Error(err)
+	s.False(result)
+
+	// Test pathological pattern evaluated in linear time
+	user.Attributes["input"] = strings.Repeat("a", MaxRegexInputLength-1) + "!"
+	result, err = s.matcher(condition, user, s.mockLogger)
+	s.NoError(err)
+	s.False(result)
+
+	// Test pattern too long to be compiled
+	condition.Value = strings.Repeat("a", MaxRegexPatternLength+1)
+	s.mockLogger.On("Warning", fmt.Sprintf(logging.UnsupportedConditionValue.String(), ""))
+	result, err = s.matcher(condition, user, s.mockLogger)
+	s.Error(err)
+	s.False(result)
+	s.mockLogger.AssertExpectations(s.T())
+}
+
+func (s *RegexTestSuite) TestRegexMatcherInvalidPattern() {
+	condition := entities.Condition{Match: "regex", Value: `(unclosed`, Name: "input"}
+	user := entities.UserContext{
+		Attributes: map[string]interface{}{
+			"input": "unclosed",
+		},
+	}
+	s.mockLogger.On("Warning", fmt.Sprintf(logging.UnsupportedConditionValue.String(), ""))
+	result, err := s.matcher(condition, user, s.mockLogger)
+	s.Error(err)
+	s.False(result)
+
+	// Test unsupported condition value
+	condition.Value = 42
+	result, err = s.matcher(condition, user, s.mockLogger)
+	s.Error(err)
+	s.False(result)
+	s.mockLogger.AssertExpectations(s.T())
+}
+
+func (s *RegexTestSuite) TestRegexCache() {
+	cache := newRegexCache(2)
+	first, err := cache.get("a")
+	s.NoError(err)
+	cached, _ := cache.get("a")
+	s.Same(first, cached)
+
+	_, err = cache.get("(")
+	s.Error(err)
+	_, err = cache.get("(")
+	s.Error(err)
+
+	// Test the cache stays bounded
+	_, err = cache.get("b")
+	s.NoError(err)
+	s.Len(cache.compiled, 2)
+}
+
+func TestRegexTestSuite(t *testing.T) {
+	suite.Run(t, new(RegexTestSuite))
+}
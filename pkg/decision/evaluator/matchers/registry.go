@@ -54,39 +54,135 @@ const (
 	SemverGtMatchType = "semver_gt"
 	// SemverGeMatchType name for the semver_eq matcher
 	SemverGeMatchType = "semver_ge"
+	// RegexMatchType name for the "regex" matcher
+	RegexMatchType = "regex"
+	// InMatchType name for the "in" matcher
+	InMatchType = "in"
+	// NotInMatchType name for the "not_in" matcher
+	NotInMatchType = "not_in"
+	// StartsWithMatchType name for the "starts_with" matcher
+	StartsWithMatchType = "starts_with"
+	// EndsWithMatchType name for the "ends_with" matcher
+	EndsWithMatchType = "ends_with"
+	// BeforeMatchType name for the "before" matcher
+	BeforeMatchType = "before"
+	// AfterMatchType name for the "after" matcher
+	AfterMatchType = "after"
+	// BetweenMatchType name for the "between" matcher
+	BetweenMatchType = "between"
 )
 
-var registry = map[string]Matcher{
-	QualifiedMatchType: QualifiedMatcher,
-	ExactMatchType:     ExactMatcher,
-	ExistsMatchType:    ExistsMatcher,
-	LtMatchType:        LtMatcher,
-	LeMatchType:        LeMatcher,
-	GtMatchType:        GtMatcher,
-	GeMatchType:        GeMatcher,
-	SubstringMatchType: SubstringMatcher,
-	SemverEqMatchType:  SemverEqMatcher,
-	SemverLtMatchType:  SemverLtMatcher,
-	SemverLeMatchType:  SemverLeMatcher,
-	SemverGtMatchType:  SemverGtMatcher,
-	SemverGeMatchType:  SemverGeMatcher,
+// builtInMatchers are the matchers every registry starts with
+var builtInMatchers = map[string]Matcher{
+	QualifiedMatchType:  QualifiedMatcher,
+	ExactMatchType:      ExactMatcher,
+	ExistsMatchType:     ExistsMatcher,
+	LtMatchType:         LtMatcher,
+	LeMatchType:         LeMatcher,
+	GtMatchType:         GtMatcher,
+	GeMatchType:         GeMatcher,
+	SubstringMatchType:  SubstringMatcher,
+	SemverEqMatchType:   SemverEqMatcher,
+	SemverLtMatchType:   SemverLtMatcher,
+	SemverLeMatchType:   SemverLeMatcher,
+	SemverGtMatchType:   SemverGtMatcher,
+	SemverGeMatchType:   SemverGeMatcher,
+	RegexMatchType:      RegexMatcher,
+	InMatchType:         InMatcher,
+	NotInMatchType:      NotInMatcher,
+	StartsWithMatchType: StartsWithMatcher,
+	EndsWithMatchType:   EndsWithMatcher,
+	BeforeMatchType:     BeforeMatcher,
+	AfterMatchType:      AfterMatcher,
+	BetweenMatchType:    BetweenMatcher,
 }
 
-var lock = sync.RWMutex{}
+// MatcherRegistry holds the Matcher implementations available to audience conditions by match type. A nil
+// *MatcherRegistry stands for the default registry shared by the process.
+type MatcherRegistry struct {
+	lock     sync.RWMutex
+	matchers map[string]registration
+}
 
-// Register new matchers by providing a name and a Matcher implementation
-func Register(name string, matcher Matcher) {
-	lock.Lock()
-	defer lock.Unlock()
+// registration is a registered matcher with the compiler of its conditions, nil when conditions are not compiled
+type registration struct {
+	matcher  Matcher
+	compiler Compiler
+}
 
-	registry[name] = matcher
+var defaultRegistry = NewMatcherRegistry()
+
+// NewMatcherRegistry returns a registry holding the built-in matchers only, matchers registered in the default
+// registry are not included
+func NewMatcherRegistry() *MatcherRegistry {
+	registry := &MatcherRegistry{matchers: make(map[string]registration, len(builtInMatchers))}
+	for name, matcher := range builtInMatchers {
+		registry.matchers[name] = registration{matcher: matcher, compiler: builtInCompilers[name]}
+	}
+	return registry
 }
 
-// Get an implementation of a Matcher function by its registered name
-func Get(name string) (Matcher, bool) {
-	lock.RLock()
-	defer lock.RUnlock()
+// DefaultRegistry returns the registry used when none is provided, the one Register and Get operate on
+func DefaultRegistry() *MatcherRegistry {
+	return defaultRegistry
+}
+
+// Register a new matcher in the registry by providing a name and a Matcher implementation. Conditions using the
+// matcher are compiled into a call of the matcher.
+func (r *MatcherRegistry) Register(name string, matcher Matcher) {
+	r.RegisterCompiled(name, matcher, nil)
+}
+
+// RegisterCompiled registers a new matcher in the registry along with the compiler of its conditions, see Compiler
+func (r *MatcherRegistry) RegisterCompiled(name string, matcher Matcher, compiler Compiler) {
+	if r == nil {
+		r = defaultRegistry
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.matchers[name] = registration{matcher: matcher, compiler: compiler}
+}
+
+// Get an implementation of a Matcher function from the registry by its registered name
+func (r *MatcherRegistry) Get(name string) (Matcher, bool) {
+	if r == nil {
+		r = defaultRegistry
+	}
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	registered, ok := r.matchers[name]
+	return registered.matcher, ok
+}
+
+// Compile compiles the condition with the compiler of the matcher registered by the given name, or into a call of the
+// matcher when it was registered without a compiler. It returns false when no matcher is registered by that name.
+func (r *MatcherRegistry) Compile(name string, condition entities.Condition, logger logging.OptimizelyLogProducer) (CompiledMatcher, bool) {
+	if r == nil {
+		r = defaultRegistry
+	}
+	r.lock.RLock()
+	registered, ok := r.matchers[name]
+	r.lock.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	if registered.compiler != nil {
+		return registered.compiler(condition, logger), true
+	}
+	matcher := registered.matcher
+	return func(user *entities.UserContext) (bool, error) {
+		return matcher(condition, *user, logger)
+	}, true
+}
 
-	matcher, ok := registry[name]
-	return matcher, ok
+// Register new matchers in the default registry by providing a name and a Matcher implementation
+func Register(name string, matcher Matcher) {
+	defaultRegistry.Register(name, matcher)
+}
+
+// Get an implementation of a Matcher function from the default registry by its registered name
+func Get(name string) (Matcher, bool) {
+	return defaultRegistry.Get(name)
 }
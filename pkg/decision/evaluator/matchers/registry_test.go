@@ -43,6 +43,14 @@ func TestInit(t *testing.T) {
 	assertMatcher(t, LtMatchType)
 	assertMatcher(t, GtMatchType)
 	assertMatcher(t, SubstringMatchType)
+	assertMatcher(t, RegexMatchType)
+	assertMatcher(t, InMatchType)
+	assertMatcher(t, NotInMatchType)
+	assertMatcher(t, StartsWithMatchType)
+	assertMatcher(t, EndsWithMatchType)
+	assertMatcher(t, BeforeMatchType)
+	assertMatcher(t, AfterMatchType)
+	assertMatcher(t, BetweenMatchType)
 }
 
 func assertMatcher(t *testing.T, name string) Matcher {
@@ -51,3 +59,47 @@ func assertMatcher(t *testing.T, name string) Matcher {
 	assert.NotNil(t, actual)
 	return actual
 }
+
+func TestMatcherRegistry(t *testing.T) {
+	scoped := NewMatcherRegistry()
+	other := NewMatcherRegistry()
+	expected := func(entities.Condition, entities.UserContext, logging.OptimizelyLogProducer) (bool, error) {
+		return true, nil
+	}
+	scoped.Register("scoped", expected)
+
+	actual, ok := scoped.Get("scoped")
+	assert.True(t, ok)
+	matches, err := actual(entities.Condition{}, entities.UserContext{}, nil)
+	assert.True(t, matches)
+	assert.NoError(t, err)
+
+	// registrations do not leak to other registries
+	_, ok = other.Get("scoped")
+	assert.False(t, ok)
+	_, ok = Get("scoped")
+	assert.False(t, ok)
+
+	// matchers registered in the default registry are not in new registries
+	Register("default_only", expected)
+	_, ok = scoped.Get("default_only")
+	assert.False(t, ok)
+
+	for name := range builtInMatchers {
+		_, ok = scoped.Get(name)
+		assert.True(t, ok, name)
+	}
+}
+
+func TestNilMatcherRegistryIsDefault(t *testing.T) {
+	var registry *MatcherRegistry
+	expected := func(entities.Condition, entities.UserContext, logging.OptimizelyLogProducer) (bool, error) {
+		return true, nil
+	}
+	registry.Register("nil_registry", expected)
+
+	_, ok := DefaultRegistry().Get("nil_registry")
+	assert.True(t, ok)
+	_, ok = registry.Get(ExactMatchType)
+	assert.True(t, ok)
+}
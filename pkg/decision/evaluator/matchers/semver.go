@@ -50,6 +50,11 @@ func (sv SemanticVersion) compareVersion(attribute string) (int, error) {
 	if err != nil {
 		return 0, err
 	}
+	return sv.compareVersionParts(targetedVersionParts, attribute)
+}
+
+// compareVersionParts compares the attribute with the condition already split by splitSemanticVersion
+func (sv SemanticVersion) compareVersionParts(targetedVersionParts []string, attribute string) (int, error) {
 	versionParts, e := sv.splitSemanticVersion(attribute)
 	if e != nil {
 		return 0, e
@@ -0,0 +1,148 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package evaluator //
+package evaluator
+
+import (
+	"github.com/optimizely/go-sdk/v2/pkg/decide"
+	"github.com/optimizely/go-sdk/v2/pkg/decision/evaluator/matchers"
+	"github.com/optimizely/go-sdk/v2/pkg/entities"
+)
+
+// ResultTreeEvaluator is a TreeEvaluator which can also return the result of every node of the tree, the decision
+// trace records it for the audience steps
+type ResultTreeEvaluator interface {
+	TreeEvaluator
+	EvaluateWithResults(*entities.TreeNode, *entities.TreeParameters, *decide.Options) (*decide.NodeResult, decide.DecisionReasons)
+}
+
+// EvaluateWithResults evaluates the tree like Evaluate and returns the result of every node. Evaluate stops at the
+// first child deciding an and or or node, here every child is evaluated so that all leaves are annotated, but the
+// children results are aggregated in the same order and with the same null handling.
+func (c MixedTreeEvaluator) EvaluateWithResults(node *entities.TreeNode, condTreeParams *entities.TreeParameters,
+	options *decide.Options) (*decide.NodeResult, decide.DecisionReasons) {
+	reasons := decide.NewDecisionReasons(options)
+	if node.Operator != "" {
+		nodeResult := &decide.NodeResult{Operator: node.Operator}
+		for _, child := range node.Nodes {
+			childResult, childReasons := c.EvaluateWithResults(child, condTreeParams, options)
+			reasons.Append(childReasons)
+			nodeResult.Nodes = append(nodeResult.Nodes, childResult)
+		}
+		nodeResult.Result = aggregate(node.Operator, nodeResult.Nodes)
+		if nodeResult.Result == nil {
+			nodeResult.NullReason = decide.NullChild
+		}
+		return nodeResult, reasons
+	}
+
+	switch item := node.Item.(type) {
+	case entities.Condition:
+		return c.evaluateConditionWithResult(item, condTreeParams, options)
+	case string:
+		nodeResult := &decide.NodeResult{AudienceID: item}
+		audience, ok := condTreeParams.AudienceMap[item]
+		if !ok || audience.ConditionTree == nil {
+			nodeResult.NullReason = decide.NullUnknownAudience
+			return nodeResult, reasons
+		}
+		audienceResult, audienceReasons := c.EvaluateWithResults(audience.ConditionTree, condTreeParams, options)
+		reasons.Append(audienceReasons)
+		nodeResult.Nodes = []*decide.NodeResult{audienceResult}
+		nodeResult.Result = audienceResult.Result
+		nodeResult.NullReason = audienceResult.NullReason
+		return nodeResult, reasons
+	default:
+		return &decide.NodeResult{NullReason: decide.NullUnknownConditionType}, reasons
+	}
+}
+
+func (c MixedTreeEvaluator) evaluateConditionWithResult(condition entities.Condition, condTreeParams *entities.TreeParameters,
+	options *decide.Options) (*decide.NodeResult, decide.DecisionReasons) {
+	nodeResult := &decide.NodeResult{
+		Name:    condition.Name,
+		Type:    condition.Type,
+		Matcher: condition.Match,
+		Value:   condition.Value,
+	}
+	if nodeResult.Matcher == "" {
+		nodeResult.Matcher = matchers.ExactMatchType
+	}
+	if nodeResult.Matcher == matchers.QualifiedMatchType {
+		nodeResult.AttributeValue = condTreeParams.User.QualifiedSegments
+	} else {
+		nodeResult.AttributeValue = condTreeParams.User.Attributes[condition.Name]
+	}
+
+	result, reasons, err := NewCustomAttributeConditionEvaluatorWithRegistry(c.logger, c.registry).Evaluate(condition, condTreeParams, options)
+	if err == nil {
+		nodeResult.Result = &result
+		return nodeResult, reasons
+	}
+
+	isValidType := false
+	for _, validType := range validTypes {
+		isValidType = isValidType || validType == condition.Type
+	}
+	_, isKnownMatcher := c.registry.Get(nodeResult.Matcher)
+	switch {
+	case !isValidType:
+		nodeResult.NullReason = decide.NullUnknownConditionType
+	case !isKnownMatcher:
+		nodeResult.NullReason = decide.NullUnknownMatchType
+	case nodeResult.Matcher != matchers.QualifiedMatchType && !condTreeParams.User.CheckAttributeExists(condition.Name):
+		nodeResult.NullReason = decide.NullMissingAttribute
+	default:
+		nodeResult.NullReason = decide.NullTypeMismatch
+	}
+	return nodeResult, reasons
+}
+
+// aggregate combines the children results of an operator node like evaluateAnd, evaluateOr and evaluateNot do
+func aggregate(operator string, children []*decide.NodeResult) *bool {
+	result := func(value bool) *bool { return &value }
+	switch operator {
+	case andOperator:
+		for _, child := range children {
+			if child.IsNull() {
+				return nil
+			}
+			if !*child.Result {
+				return result(false)
+			}
+		}
+		return result(true)
+	case notOperator:
+		if len(children) == 0 || children[0].IsNull() {
+			return nil
+		}
+		return result(!*children[0].Result)
+	default: // orOperator
+		sawNull := false
+		for _, child := range children {
+			if child.IsNull() {
+				sawNull = true
+			} else if *child.Result {
+				return result(true)
+			}
+		}
+		if sawNull {
+			return nil
+		}
+		return result(false)
+	}
+}
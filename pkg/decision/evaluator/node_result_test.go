@@ -0,0 +1,126 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/optimizely/go-sdk/v2/pkg/decide"
+	e "github.com/optimizely/go-sdk/v2/pkg/entities"
+	"github.com/optimizely/go-sdk/v2/pkg/logging"
+)
+
+func boolResult(value bool) *bool {
+	return &value
+}
+
+func TestEvaluateWithResultsAnnotatesEveryLeaf(t *testing.T) {
+	user := e.UserContext{Attributes: map[string]interface{}{"string_foo": "foo", "int_42": "not a number"}}
+	audienceMap := map[string]e.Audience{
+		"11111": {ID: "11111", ConditionTree: &e.TreeNode{Operator: "or", Nodes: []*e.TreeNode{{Item: int42Condition}}}},
+	}
+	// or stops at the first true child, every child is still annotated
+	tree := &e.TreeNode{Operator: "or", Nodes: []*e.TreeNode{
+		{Item: stringFooCondition},
+		{Operator: "not", Nodes: []*e.TreeNode{{Item: boolTrueCondition}}},
+		{Item: "11111"},
+		{Item: "22222"},
+	}}
+
+	treeEvaluator := NewMixedTreeEvaluator(logging.GetLogger("", "NodeResultTest"))
+	params := e.NewTreeParameters(&user, audienceMap)
+	result, _ := treeEvaluator.EvaluateWithResults(tree, params, &decide.Options{})
+
+	assert.Equal(t, &decide.NodeResult{Operator: "or", Result: boolResult(true), Nodes: []*decide.NodeResult{
+		{Name: "string_foo", Type: "custom_attribute", Matcher: "exact", Value: "foo", AttributeValue: "foo", Result: boolResult(true)},
+		{Operator: "not", NullReason: decide.NullChild, Nodes: []*decide.NodeResult{
+			{Name: "bool_true", Type: "custom_attribute", Matcher: "exact", Value: true, NullReason: decide.NullMissingAttribute},
+		}},
+		{AudienceID: "11111", NullReason: decide.NullChild, Nodes: []*decide.NodeResult{
+			{Operator: "or", NullReason: decide.NullChild, Nodes: []*decide.NodeResult{
+				{Name: "int_42", Type: "custom_attribute", Matcher: "exact", Value: 42, AttributeValue: "not a number",
+					NullReason: decide.NullTypeMismatch},
+			}},
+		}},
+		{AudienceID: "22222", NullReason: decide.NullUnknownAudience},
+	}}, result)
+
+	evalResult, isValid, _ := treeEvaluator.Evaluate(tree, params, &decide.Options{})
+	assert.True(t, evalResult)
+	assert.True(t, isValid)
+}
+
+func TestEvaluateWithResultsAggregatesLikeEvaluate(t *testing.T) {
+	user := e.UserContext{Attributes: map[string]interface{}{"string_foo": "foo", "bool_true": false}}
+	unknownMatch := e.Condition{Type: "custom_attribute", Match: "unknown", Name: "string_foo", Value: "foo"}
+	unknownType := e.Condition{Type: "invalid", Name: "string_foo", Value: "foo"}
+
+	trees := []*e.TreeNode{
+		{Operator: "and", Nodes: []*e.TreeNode{{Item: boolTrueCondition}, {Item: int42Condition}}},
+		{Operator: "and", Nodes: []*e.TreeNode{{Item: int42Condition}, {Item: boolTrueCondition}}},
+		{Operator: "or", Nodes: []*e.TreeNode{{Item: int42Condition}, {Item: boolTrueCondition}}},
+		{Operator: "or", Nodes: []*e.TreeNode{{Item: int42Condition}, {Item: stringFooCondition}}},
+		{Operator: "not", Nodes: []*e.TreeNode{{Item: boolTrueCondition}}},
+		{Operator: "not"},
+		{Operator: "and", Nodes: []*e.TreeNode{{Item: unknownMatch}, {Item: unknownType}}},
+	}
+
+	treeEvaluator := NewMixedTreeEvaluator(logging.GetLogger("", "NodeResultTest"))
+	params := e.NewTreeParameters(&user, map[string]e.Audience{})
+	for _, tree := range trees {
+		result, _ := treeEvaluator.EvaluateWithResults(tree, params, &decide.Options{})
+		evalResult, isValid, _ := treeEvaluator.Evaluate(tree, params, &decide.Options{})
+		if isValid {
+			assert.Equal(t, boolResult(evalResult), result.Result)
+		} else {
+			assert.True(t, result.IsNull())
+		}
+	}
+
+	result, _ := treeEvaluator.EvaluateWithResults(trees[len(trees)-1], params, &decide.Options{})
+	assert.Equal(t, decide.NullUnknownMatchType, result.Nodes[0].NullReason)
+	assert.Equal(t, decide.NullUnknownConditionType, result.Nodes[1].NullReason)
+}
+
+func TestEvaluateTreeRecordsNodeResults(t *testing.T) {
+	user := e.UserContext{Attributes: map[string]interface{}{"string_foo": "foo"}}
+	tree := &e.TreeNode{Operator: "and", Nodes: []*e.TreeNode{{Item: stringFooCondition}}}
+	params := e.NewTreeParameters(&user, map[string]e.Audience{})
+	logger := logging.GetLogger("", "NodeResultTest")
+
+	for _, treeEvaluator := range []ResultTreeEvaluator{NewMixedTreeEvaluator(logger), NewCompiledTreeEvaluator(logger, nil)} {
+		trace := &decide.DecisionTrace{}
+		evalResult, _ := EvaluateTree(treeEvaluator, tree, params, &decide.Options{Trace: trace})
+		assert.True(t, evalResult)
+		trace.AddStep(decide.TraceStep{Kind: decide.TraceAudience})
+		assert.Equal(t, &decide.NodeResult{Operator: "and", Result: boolResult(true), Nodes: []*decide.NodeResult{
+			{Name: "string_foo", Type: "custom_attribute", Matcher: "exact", Value: "foo", AttributeValue: "foo", Result: boolResult(true)},
+		}}, trace.Steps[0].Audiences)
+	}
+
+	// evaluators which do not return node results are evaluated as usual
+	treeEvaluator := new(MockTreeEvaluator)
+	trace := &decide.DecisionTrace{}
+	options := &decide.Options{Trace: trace}
+	treeEvaluator.On("Evaluate", tree, params, options).Return(true, true, decide.NewDecisionReasons(options))
+	evalResult, _ := EvaluateTree(treeEvaluator, tree, params, options)
+	assert.True(t, evalResult)
+	trace.AddStep(decide.TraceStep{Kind: decide.TraceAudience})
+	assert.Nil(t, trace.Steps[0].Audiences)
+}
@@ -40,7 +40,7 @@ func NewExperimentBucketerService(logger logging.OptimizelyLogProducer) *Experim
 	// @TODO(mng): add experiment override service
 	return &ExperimentBucketerService{
 		logger:                logger,
-		audienceTreeEvaluator: evaluator.NewMixedTreeEvaluator(logger),
+		audienceTreeEvaluator: evaluator.NewCompiledTreeEvaluator(logger, nil),
 		bucketer:              *bucketer.NewMurmurhashExperimentBucketer(logger, bucketer.DefaultHashSeed),
 	}
 }
@@ -59,6 +59,7 @@ func (s ExperimentBucketerService) GetDecision(decisionContext ExperimentDecisio
 	// Audience evaluation using common function
 	inAudience, audienceReasons := evaluator.CheckIfUserInAudience(experiment, userContext, decisionContext.ProjectConfig, s.audienceTreeEvaluator, options, s.logger)
 	reasons.Append(audienceReasons)
+	traceAudience(options, "ExperimentBucketerService", experiment.Key, inAudience)
 
 	if !inAudience {
 		logMessage := reasons.AddInfo("User \"%s\" does not meet conditions to be in experiment \"%s\".", userContext.ID, experiment.Key)
@@ -84,6 +85,7 @@ func (s ExperimentBucketerService) GetDecision(decisionContext ExperimentDecisio
 	}
 	// @TODO: handle error from bucketer
 	variation, reason, _ := s.bucketer.Bucket(bucketingID, *experiment, group)
+	traceBucketing(options, "ExperimentBucketerService", s.bucketer, bucketingID, *experiment, group, variation)
 	experimentDecision.Reason = reason
 	experimentDecision.Variation = variation
 	return experimentDecision, reasons, nil
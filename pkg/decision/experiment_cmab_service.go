@@ -153,7 +153,7 @@ func NewExperimentCmabService(sdkKey string, config *cmab.Config) *ExperimentCma
 	logger := logging.GetLogger(sdkKey, "ExperimentCmabService")
 
 	return &ExperimentCmabService{
-		audienceTreeEvaluator: evaluator.NewMixedTreeEvaluator(logger),
+		audienceTreeEvaluator: evaluator.NewCompiledTreeEvaluator(logger, nil),
 		bucketer:              *bucketer.NewMurmurhashExperimentBucketer(logger, bucketer.DefaultHashSeed),
 		cmabService:           cmabService,
 		logger:                logger,
@@ -188,6 +188,7 @@ func (s *ExperimentCmabService) GetDecision(decisionContext ExperimentDecisionCo
 	// Audience evaluation using common function
 	inAudience, audienceReasons := evaluator.CheckIfUserInAudience(experiment, userContext, projectConfig, s.audienceTreeEvaluator, options, s.logger)
 	decisionReasons.Append(audienceReasons)
+	traceAudience(options, "ExperimentCmabService", experiment.Key, inAudience)
 
 	if !inAudience {
 		logMessage := decisionReasons.AddInfo("User %s not in audience for CMAB experiment %s", userContext.ID, experiment.Key)
@@ -53,6 +53,7 @@ func (f FeatureExperimentService) GetDecision(decisionContext FeatureDecisionCon
 			forcedDecision, _reasons, err := decisionContext.ForcedDecisionService.FindValidatedForcedDecision(decisionContext.ProjectConfig, OptimizelyDecisionContext{FlagKey: feature.Key, RuleKey: featureExperiment.Key}, options)
 			reasons.Append(_reasons)
 			if err == nil {
+				traceOutcome(options, decide.TraceRule, "FeatureExperimentService", featureExperiment.Key, forcedDecision, "")
 				featureDecision := FeatureDecision{
 					Experiment: featureExperiment,
 					Variation:  forcedDecision,
@@ -69,6 +70,7 @@ func (f FeatureExperimentService) GetDecision(decisionContext FeatureDecisionCon
 			reasons.Append(holdoutReasons)
 			if holdoutDecision.Variation != nil {
 				// User is in a local holdout — return holdout decision, skip rule evaluation
+				traceOutcome(options, decide.TraceRule, "FeatureExperimentService", featureExperiment.Key, nil, "captured by local holdout "+holdoutDecision.Experiment.Key)
 				return holdoutDecision, reasons, nil
 			}
 		}
@@ -91,6 +93,7 @@ func (f FeatureExperimentService) GetDecision(decisionContext FeatureDecisionCon
 
 		// Handle CMAB experiment errors - they should terminate the decision process
 		if err != nil && experiment.Cmab != nil {
+			traceOutcome(options, decide.TraceRule, "FeatureExperimentService", experiment.Key, nil, err.Error())
 			// For CMAB experiments, errors should prevent fallback to other experiments AND rollouts
 			// Return the error so CompositeFeatureService can detect it
 			return FeatureDecision{}, reasons, err
@@ -98,6 +101,7 @@ func (f FeatureExperimentService) GetDecision(decisionContext FeatureDecisionCon
 
 		// Variation not nil means we got a decision and should return it
 		if experimentDecision.Variation != nil {
+			traceOutcome(options, decide.TraceRule, "FeatureExperimentService", experiment.Key, experimentDecision.Variation, "")
 			featureDecision := FeatureDecision{
 				Experiment: experiment,
 				Decision:   experimentDecision.Decision,
@@ -108,6 +112,7 @@ func (f FeatureExperimentService) GetDecision(decisionContext FeatureDecisionCon
 
 			return featureDecision, reasons, err
 		}
+		traceOutcome(options, decide.TraceRule, "FeatureExperimentService", experiment.Key, nil, fallThroughReason(options, experiment.Key, experimentDecision.Reason))
 	}
 
 	return FeatureDecision{}, reasons, nil
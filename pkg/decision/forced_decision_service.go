@@ -117,10 +117,12 @@ func (f *ForcedDecisionService) FindValidatedForcedDecision(projectConfig config
 	}
 
 	if err != nil {
-		decisionReasons.AddInfo("Invalid variation is mapped to %s and user (%s) in the forced decision map.", target, f.UserID)
+		reason := decisionReasons.AddInfo("Invalid variation is mapped to %s and user (%s) in the forced decision map.", target, f.UserID)
+		traceOutcome(options, decide.TraceForcedDecision, "ForcedDecisionService", context.RuleKey, nil, reason)
 		return nil, decisionReasons, err
 	}
-	decisionReasons.AddInfo("Variation (%s) is mapped to %s and user (%s) in the forced decision map.", forcedDecision.VariationKey, target, f.UserID)
+	reason := decisionReasons.AddInfo("Variation (%s) is mapped to %s and user (%s) in the forced decision map.", forcedDecision.VariationKey, target, f.UserID)
+	traceOutcome(options, decide.TraceForcedDecision, "ForcedDecisionService", context.RuleKey, _variation, reason)
 	return _variation, decisionReasons, nil
 }
 
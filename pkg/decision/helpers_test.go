@@ -22,6 +22,8 @@
 package decision
 
 import (
+	"context"
+
 	"github.com/optimizely/go-sdk/v2/pkg/config"
 	"github.com/optimizely/go-sdk/v2/pkg/decide"
 	"github.com/optimizely/go-sdk/v2/pkg/entities"
@@ -105,6 +107,34 @@ func (m *MockUserProfileService) Save(userProfile UserProfile) {
 	m.Called(userProfile)
 }
 
+type MockContextUserProfileService struct {
+	MockUserProfileService
+}
+
+func (m *MockContextUserProfileService) LookupWithContext(ctx context.Context, userID string) (UserProfile, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(UserProfile), args.Error(1)
+}
+
+func (m *MockContextUserProfileService) SaveWithContext(ctx context.Context, userProfile UserProfile) error {
+	args := m.Called(ctx, userProfile)
+	return args.Error(0)
+}
+
+type MockBatchUserProfileService struct {
+	MockUserProfileService
+}
+
+func (m *MockBatchUserProfileService) LookupBatch(ctx context.Context, userIDs []string) (map[string]UserProfile, error) {
+	args := m.Called(ctx, userIDs)
+	return args.Get(0).(map[string]UserProfile), args.Error(1)
+}
+
+func (m *MockBatchUserProfileService) SaveBatch(ctx context.Context, userProfiles []UserProfile) error {
+	args := m.Called(ctx, userProfiles)
+	return args.Error(0)
+}
+
 func (m *MockAudienceTreeEvaluator) Evaluate(node *entities.TreeNode, condTreeParams *entities.TreeParameters, options *decide.Options) (evalResult, isValid bool, reasons decide.DecisionReasons) {
 	args := m.Called(node, condTreeParams, options)
 	return args.Bool(0), args.Bool(1), args.Get(2).(decide.DecisionReasons)
@@ -24,6 +24,7 @@ import (
 	"github.com/optimizely/go-sdk/v2/pkg/decide"
 	"github.com/optimizely/go-sdk/v2/pkg/decision/bucketer"
 	"github.com/optimizely/go-sdk/v2/pkg/decision/evaluator"
+	"github.com/optimizely/go-sdk/v2/pkg/decision/evaluator/matchers"
 	"github.com/optimizely/go-sdk/v2/pkg/entities"
 	"github.com/optimizely/go-sdk/v2/pkg/logging"
 )
@@ -35,14 +36,43 @@ type HoldoutService struct {
 	logger                logging.OptimizelyLogProducer
 }
 
+// HSOptionFunc is used to pass custom config options into the HoldoutService.
+type HSOptionFunc func(*HoldoutService)
+
+// WithHoldoutBucketer sets the bucketer used to bucket users into holdouts, defaults to MurmurHash3
+func WithHoldoutBucketer(holdoutBucketer bucketer.Bucketer) HSOptionFunc {
+	return func(h *HoldoutService) {
+		h.bucketer = bucketer.NewExperimentBucketer(holdoutBucketer)
+	}
+}
+
+// WithHoldoutMatcherRegistry sets the registry of the matchers used to evaluate holdout audiences, defaults to the
+// global registry
+func WithHoldoutMatcherRegistry(registry *matchers.MatcherRegistry) HSOptionFunc {
+	return func(h *HoldoutService) {
+		h.audienceTreeEvaluator = evaluator.NewCompiledTreeEvaluator(h.logger, registry)
+	}
+}
+
+// WithHoldoutAudienceEvaluator sets the evaluator of holdout audiences, defaults to a CompiledTreeEvaluator
+func WithHoldoutAudienceEvaluator(audienceEvaluator evaluator.TreeEvaluator) HSOptionFunc {
+	return func(h *HoldoutService) {
+		h.audienceTreeEvaluator = audienceEvaluator
+	}
+}
+
 // NewHoldoutService returns a new instance of the HoldoutService
-func NewHoldoutService(sdkKey string) *HoldoutService {
+func NewHoldoutService(sdkKey string, options ...HSOptionFunc) *HoldoutService {
 	logger := logging.GetLogger(sdkKey, "HoldoutService")
-	return &HoldoutService{
-		audienceTreeEvaluator: evaluator.NewMixedTreeEvaluator(logger),
+	holdoutService := &HoldoutService{
+		audienceTreeEvaluator: evaluator.NewCompiledTreeEvaluator(logger, nil),
 		bucketer:              bucketer.NewMurmurhashExperimentBucketer(logger, bucketer.DefaultHashSeed),
 		logger:                logger,
 	}
+	for _, opt := range options {
+		opt(holdoutService)
+	}
+	return holdoutService
 }
 
 // GetGlobalDecision returns a decision for global holdouts associated with the feature.
@@ -61,6 +91,7 @@ func (h HoldoutService) GetGlobalDecision(decisionContext FeatureDecisionContext
 		if holdout.Status != entities.HoldoutStatusRunning {
 			reason := reasons.AddInfo("Holdout %s is not running.", holdout.Key)
 			h.logger.Info(reason)
+			traceOutcome(options, decide.TraceHoldout, "HoldoutService", holdout.Key, nil, "holdout is not running")
 			continue
 		}
 
@@ -69,6 +100,7 @@ func (h HoldoutService) GetGlobalDecision(decisionContext FeatureDecisionContext
 		reasons.Append(inAudience.reasons)
 
 		if !inAudience.result {
+			traceOutcome(options, decide.TraceHoldout, "HoldoutService", holdout.Key, nil, "audience conditions not met")
 			reason := reasons.AddInfo("User %s does not meet conditions for holdout %s.", userContext.ID, holdout.Key)
 			h.logger.Info(reason)
 			continue
@@ -101,8 +133,10 @@ func (h HoldoutService) GetGlobalDecision(decisionContext FeatureDecisionContext
 
 		// Bucket user into holdout variation
 		variation, _, _ := h.bucketer.Bucket(bucketingID, experimentForBucketing, entities.Group{})
+		traceBucketing(options, "HoldoutService", h.bucketer, bucketingID, experimentForBucketing, entities.Group{}, variation)
 
 		if variation != nil {
+			traceOutcome(options, decide.TraceHoldout, "HoldoutService", holdout.Key, variation, "")
 			reason = reasons.AddInfo("User %s is in variation %s of holdout %s.", userContext.ID, variation.Key, holdout.Key)
 			h.logger.Info(reason)
 
@@ -114,6 +148,7 @@ func (h HoldoutService) GetGlobalDecision(decisionContext FeatureDecisionContext
 			return featureDecision, reasons, nil
 		}
 
+		traceOutcome(options, decide.TraceHoldout, "HoldoutService", holdout.Key, nil, "not bucketed into a holdout variation")
 		reason = reasons.AddInfo("User %s is in no holdout variation.", userContext.ID)
 		h.logger.Info(reason)
 	}
@@ -137,6 +172,7 @@ func (h HoldoutService) GetLocalDecisionForRule(ruleID string, projectConfig con
 		if holdout.Status != entities.HoldoutStatusRunning {
 			reason := reasons.AddInfo("Local holdout %s is not running.", holdout.Key)
 			h.logger.Info(reason)
+			traceOutcome(options, decide.TraceHoldout, "HoldoutService", holdout.Key, nil, "holdout is not running")
 			continue
 		}
 
@@ -145,6 +181,7 @@ func (h HoldoutService) GetLocalDecisionForRule(ruleID string, projectConfig con
 		reasons.Append(inAudience.reasons)
 
 		if !inAudience.result {
+			traceOutcome(options, decide.TraceHoldout, "HoldoutService", holdout.Key, nil, "audience conditions not met")
 			reason := reasons.AddInfo("User %s does not meet conditions for local holdout %s.", userContext.ID, holdout.Key)
 			h.logger.Info(reason)
 			continue
@@ -173,8 +210,10 @@ func (h HoldoutService) GetLocalDecisionForRule(ruleID string, projectConfig con
 
 		// Bucket user into holdout variation
 		variation, _, _ := h.bucketer.Bucket(bucketingID, experimentForBucketing, entities.Group{})
+		traceBucketing(options, "HoldoutService", h.bucketer, bucketingID, experimentForBucketing, entities.Group{}, variation)
 
 		if variation != nil {
+			traceOutcome(options, decide.TraceHoldout, "HoldoutService", holdout.Key, variation, "")
 			reason = reasons.AddInfo("User %s is in variation %s of local holdout %s for rule %s.", userContext.ID, variation.Key, holdout.Key, ruleID)
 			h.logger.Info(reason)
 
@@ -186,6 +225,7 @@ func (h HoldoutService) GetLocalDecisionForRule(ruleID string, projectConfig con
 			return featureDecision, reasons, nil
 		}
 
+		traceOutcome(options, decide.TraceHoldout, "HoldoutService", holdout.Key, nil, "not bucketed into a holdout variation")
 		reason = reasons.AddInfo("User %s is not bucketed into local holdout %s for rule %s.", userContext.ID, holdout.Key, ruleID)
 		h.logger.Info(reason)
 	}
@@ -207,17 +247,19 @@ func (h HoldoutService) checkIfUserInHoldoutAudience(holdout *entities.Holdout,
 		condTreeParams := entities.NewTreeParameters(&userContext, projectConfig.GetAudienceMap())
 		h.logger.Debug(fmt.Sprintf("Evaluating audiences for holdout %q.", holdout.Key))
 
-		evalResult, _, audienceReasons := h.audienceTreeEvaluator.Evaluate(holdout.AudienceConditionTree, condTreeParams, options)
+		evalResult, audienceReasons := evaluator.EvaluateTree(h.audienceTreeEvaluator, holdout.AudienceConditionTree, condTreeParams, options)
 		decisionReasons.Append(audienceReasons)
 
 		logMessage := decisionReasons.AddInfo("Audiences for holdout %s collectively evaluated to %v.", holdout.Key, evalResult)
 		h.logger.Debug(logMessage)
+		traceAudience(options, "HoldoutService", holdout.Key, evalResult)
 
 		return decisionResult{result: evalResult, reasons: decisionReasons}
 	}
 
 	logMessage := decisionReasons.AddInfo("Audiences for holdout %s collectively evaluated to true.", holdout.Key)
 	h.logger.Debug(logMessage)
+	traceAudience(options, "HoldoutService", holdout.Key, true)
 	return decisionResult{result: true, reasons: decisionReasons}
 }
 
@@ -243,6 +243,37 @@ func (s *HoldoutServiceTestSuite) TestGetDecisionNoAudienceTargeting() {
 	s.mockBucketer.AssertExpectations(s.T())
 }
 
+func (s *HoldoutServiceTestSuite) TestGetDecisionTrace() {
+	s.mockConfig.On("GetGlobalHoldouts").Return([]entities.Holdout{testHoldout3NotRunning, testHoldout1, testHoldout2NoAudience})
+	trace := &decide.DecisionTrace{}
+	options := &decide.Options{Trace: trace}
+	s.mockAudienceTreeEvaluator.On("Evaluate", testHoldout1.AudienceConditionTree, mock.Anything, options).Return(false, true, s.decisionReasons)
+	s.mockLogger.On("Debug", mock.Anything).Return()
+	s.mockLogger.On("Info", mock.Anything).Return()
+
+	testHoldoutService := HoldoutService{
+		audienceTreeEvaluator: s.mockAudienceTreeEvaluator,
+		bucketer:              bucketer.NewExperimentBucketer(bucketer.NewMurmurhashBucketer(s.mockLogger, bucketer.DefaultHashSeed)),
+		logger:                s.mockLogger,
+	}
+
+	decision, _, err := testHoldoutService.GetGlobalDecision(s.testFeatureDecisionContext, s.testUserContext, options)
+	s.NoError(err)
+	s.Equal(testHoldoutVar1.ID, decision.Variation.ID)
+
+	bucketValue, _ := bucketer.NewExperimentBucketer(bucketer.NewMurmurhashBucketer(s.mockLogger, bucketer.DefaultHashSeed)).
+		LocateBucket("test_user_holdoutholdout_2", testHoldout2NoAudience.TrafficAllocation)
+	s.Equal([]decide.TraceStep{
+		{Kind: decide.TraceHoldout, Service: "HoldoutService", RuleKey: "test_holdout_3_not_running", Reason: "holdout is not running"},
+		{Kind: decide.TraceAudience, Service: "HoldoutService", RuleKey: "test_holdout_1"},
+		{Kind: decide.TraceHoldout, Service: "HoldoutService", RuleKey: "test_holdout_1", Reason: "audience conditions not met"},
+		{Kind: decide.TraceAudience, Service: "HoldoutService", RuleKey: "test_holdout_2_no_audience", Matched: true},
+		{Kind: decide.TraceBucketing, Service: "HoldoutService", RuleKey: "test_holdout_2_no_audience", Matched: true, VariationKey: "holdout_variation_1",
+			Bucketing: &decide.BucketingResult{BucketingID: "test_user_holdout", BucketValue: bucketValue, RangeEnd: 10000, EntityID: "holdout_var_1"}},
+		{Kind: decide.TraceHoldout, Service: "HoldoutService", RuleKey: "test_holdout_2_no_audience", Matched: true, VariationKey: "holdout_variation_1"},
+	}, trace.Steps)
+}
+
 func (s *HoldoutServiceTestSuite) TestGetDecisionMultipleHoldoutsFirstMatches() {
 	// Setup: Multiple holdouts, first one matches
 	holdouts := []entities.Holdout{testHoldout1, testHoldout2NoAudience}
@@ -349,6 +380,13 @@ func TestNewHoldoutService(t *testing.T) {
 	assert.NotNil(t, service.logger)
 }
 
+func TestNewHoldoutServiceWithBucketer(t *testing.T) {
+	xxHashBucketer := bucketer.NewXXHashBucketer(bucketer.DefaultHashSeed)
+	service := NewHoldoutService("test_sdk_key", WithHoldoutBucketer(xxHashBucketer))
+
+	assert.Equal(t, bucketer.NewExperimentBucketer(xxHashBucketer), service.bucketer)
+}
+
 // Integration test with real bucketer and evaluator
 func TestHoldoutServiceIntegration(t *testing.T) {
 	logger := logging.GetLogger("", "HoldoutService")
@@ -18,6 +18,8 @@
 package decision
 
 import (
+	"context"
+
 	"github.com/optimizely/go-sdk/v2/pkg/decide"
 	"github.com/optimizely/go-sdk/v2/pkg/entities"
 	"github.com/optimizely/go-sdk/v2/pkg/notification"
@@ -46,3 +48,21 @@ type UserProfileService interface {
 	Lookup(string) UserProfile
 	Save(UserProfile)
 }
+
+// ContextUserProfileService is a UserProfileService whose lookups and saves take a context and return the storage
+// failures, which are reported in the decision reasons instead of being ignored
+type ContextUserProfileService interface {
+	UserProfileService
+	// LookupWithContext returns the saved profile of the user, a profile with an empty ID when none was saved
+	LookupWithContext(ctx context.Context, userID string) (UserProfile, error)
+	SaveWithContext(ctx context.Context, userProfile UserProfile) error
+}
+
+// BatchUserProfileService is a UserProfileService able to look up and save the profiles of several users in a single
+// round-trip to its store
+type BatchUserProfileService interface {
+	UserProfileService
+	// LookupBatch returns the saved profiles keyed by user ID, users without a saved profile are left out
+	LookupBatch(ctx context.Context, userIDs []string) (map[string]UserProfile, error)
+	SaveBatch(ctx context.Context, userProfiles []UserProfile) error
+}
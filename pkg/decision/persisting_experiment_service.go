@@ -18,6 +18,7 @@
 package decision
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/optimizely/go-sdk/v2/pkg/decide"
@@ -57,12 +58,15 @@ func (p PersistingExperimentService) GetDecision(decisionContext ExperimentDecis
 
 	var userProfile UserProfile
 	var decisionReasons decide.DecisionReasons
+	var lookupErr error
 	// check to see if there is a saved decision for the user
-	experimentDecision, userProfile, decisionReasons = p.getSavedDecision(decisionContext, userContext, options)
+	experimentDecision, userProfile, decisionReasons, lookupErr = p.getSavedDecision(decisionContext, userContext, options)
 	reasons.Append(decisionReasons)
 	if experimentDecision.Variation != nil {
+		traceOutcome(options, decide.TraceUserProfile, "PersistingExperimentService", decisionContext.Experiment.Key, experimentDecision.Variation, "")
 		return experimentDecision, reasons, nil
 	}
+	traceOutcome(options, decide.TraceUserProfile, "PersistingExperimentService", decisionContext.Experiment.Key, nil, "no saved variation")
 
 	experimentDecision, decisionReasons, err = p.experimentBucketedService.GetDecision(decisionContext, userContext, options)
 	reasons.Append(decisionReasons)
@@ -71,7 +75,13 @@ func (p PersistingExperimentService) GetDecision(decisionContext ExperimentDecis
 		userProfile.ID = userContext.ID
 		decisionKey := NewUserDecisionKey(decisionContext.Experiment.ID)
 		if isUserProfileNil {
-			p.saveDecision(userProfile, decisionKey, experimentDecision)
+			// a profile that could not be looked up is not saved, that would overwrite the decisions saved before
+			if lookupErr == nil {
+				if saveErr := p.saveDecision(userProfile, decisionKey, experimentDecision); saveErr != nil {
+					reasons.AddError("%v", saveErr)
+					p.logger.Error("Unable to save the user profile.", saveErr)
+				}
+			}
 		} else {
 			if decisionContext.UserProfile.ExperimentBucketMap == nil {
 				decisionContext.UserProfile.ExperimentBucketMap = make(map[UserDecisionKey]string)
@@ -84,12 +94,17 @@ func (p PersistingExperimentService) GetDecision(decisionContext ExperimentDecis
 	return experimentDecision, reasons, err
 }
 
-func (p PersistingExperimentService) getSavedDecision(decisionContext ExperimentDecisionContext, userContext entities.UserContext, options *decide.Options) (ExperimentDecision, UserProfile, decide.DecisionReasons) {
+func (p PersistingExperimentService) getSavedDecision(decisionContext ExperimentDecisionContext, userContext entities.UserContext, options *decide.Options) (ExperimentDecision, UserProfile, decide.DecisionReasons, error) {
 	reasons := decide.NewDecisionReasons(options)
 	experimentDecision := ExperimentDecision{}
 	var userProfile UserProfile
 	if decisionContext.UserProfile == nil {
-		userProfile = p.userProfileService.Lookup(userContext.ID)
+		var err error
+		if userProfile, err = LookupUserProfile(context.Background(), p.userProfileService, userContext.ID); err != nil {
+			reasons.AddError("%v", err)
+			p.logger.Error("Unable to look up the user profile.", err)
+			return experimentDecision, userProfile, reasons, err
+		}
 	} else {
 		userProfile = *decisionContext.UserProfile
 	}
@@ -97,7 +112,7 @@ func (p PersistingExperimentService) getSavedDecision(decisionContext Experiment
 	// look up experiment decision from user profile
 	decisionKey := NewUserDecisionKey(decisionContext.Experiment.ID)
 	if userProfile.ExperimentBucketMap == nil {
-		return experimentDecision, userProfile, reasons
+		return experimentDecision, userProfile, reasons, nil
 	}
 
 	if savedVariationID, ok := userProfile.ExperimentBucketMap[decisionKey]; ok {
@@ -111,18 +126,21 @@ func (p PersistingExperimentService) getSavedDecision(decisionContext Experiment
 		}
 	}
 
-	return experimentDecision, userProfile, reasons
+	return experimentDecision, userProfile, reasons, nil
 }
 
-func (p PersistingExperimentService) saveDecision(userProfile UserProfile, decisionKey UserDecisionKey, decision ExperimentDecision) {
+func (p PersistingExperimentService) saveDecision(userProfile UserProfile, decisionKey UserDecisionKey, decision ExperimentDecision) error {
 	if p.userProfileService != nil {
 		if userProfile.ExperimentBucketMap == nil {
 			userProfile.ExperimentBucketMap = map[UserDecisionKey]string{}
 		}
 		userProfile.ExperimentBucketMap[decisionKey] = decision.Variation.ID
-		p.userProfileService.Save(userProfile)
+		if err := SaveUserProfile(context.Background(), p.userProfileService, userProfile); err != nil {
+			return err
+		}
 		p.logger.Debug(fmt.Sprintf(`Decision saved for user %q.`, userProfile.ID))
 	}
+	return nil
 }
 
 // isCmabExperiment checks if the experiment is a CMAB experiment
@@ -18,6 +18,7 @@
 package decision
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/optimizely/go-sdk/v2/pkg/decide"
@@ -176,6 +177,39 @@ func (s *PersistingExperimentServiceTestSuite) TestCmabExperimentSkipsUserProfil
 	s.mockUserProfileService.AssertNotCalled(s.T(), "Save", mock.Anything)
 }
 
+func (s *PersistingExperimentServiceTestSuite) TestUserProfileLookupFailure() {
+	contextUserProfileService := new(MockContextUserProfileService)
+	contextUserProfileService.On("LookupWithContext", mock.Anything, testUserContext.ID).Return(UserProfile{}, errors.New("connection refused"))
+
+	persistingExperimentService := NewPersistingExperimentService(contextUserProfileService, s.mockExperimentService, logging.GetLogger("", "NewPersistingExperimentService"))
+	decision, rsons, err := persistingExperimentService.GetDecision(s.testDecisionContext, testUserContext, s.options)
+	s.Equal(s.testComputedDecision, decision)
+	s.NoError(err)
+	// errors are reported without IncludeReasons
+	s.Equal([]string{`user profile lookup failed for user "test_user_1": connection refused`}, rsons.ToReport())
+	// the decision is not saved over the profile that could not be looked up
+	contextUserProfileService.AssertNotCalled(s.T(), "SaveWithContext", mock.Anything, mock.Anything)
+	contextUserProfileService.AssertNotCalled(s.T(), "Save", mock.Anything)
+}
+
+func (s *PersistingExperimentServiceTestSuite) TestUserProfileSaveFailure() {
+	decisionKey := NewUserDecisionKey(s.testDecisionContext.Experiment.ID)
+	updatedUserProfile := UserProfile{
+		ID:                  testUserContext.ID,
+		ExperimentBucketMap: map[UserDecisionKey]string{decisionKey: s.testComputedDecision.Variation.ID},
+	}
+	contextUserProfileService := new(MockContextUserProfileService)
+	contextUserProfileService.On("LookupWithContext", mock.Anything, testUserContext.ID).Return(UserProfile{ID: testUserContext.ID}, nil)
+	contextUserProfileService.On("SaveWithContext", mock.Anything, updatedUserProfile).Return(errors.New("read-only replica"))
+
+	persistingExperimentService := NewPersistingExperimentService(contextUserProfileService, s.mockExperimentService, logging.GetLogger("", "NewPersistingExperimentService"))
+	decision, rsons, err := persistingExperimentService.GetDecision(s.testDecisionContext, testUserContext, s.options)
+	s.Equal(s.testComputedDecision, decision)
+	s.NoError(err)
+	s.Equal([]string{`user profile save failed for user "test_user_1": read-only replica`}, rsons.ToReport())
+	contextUserProfileService.AssertExpectations(s.T())
+}
+
 func TestPersistingExperimentServiceTestSuite(t *testing.T) {
 	suite.Run(t, new(PersistingExperimentServiceTestSuite))
 }
@@ -22,7 +22,9 @@ import (
 	"strconv"
 
 	"github.com/optimizely/go-sdk/v2/pkg/decide"
+	"github.com/optimizely/go-sdk/v2/pkg/decision/bucketer"
 	"github.com/optimizely/go-sdk/v2/pkg/decision/evaluator"
+	"github.com/optimizely/go-sdk/v2/pkg/decision/evaluator/matchers"
 	pkgReasons "github.com/optimizely/go-sdk/v2/pkg/decision/reasons"
 	"github.com/optimizely/go-sdk/v2/pkg/entities"
 	"github.com/optimizely/go-sdk/v2/pkg/logging"
@@ -33,18 +35,62 @@ type RolloutService struct {
 	audienceTreeEvaluator     evaluator.TreeEvaluator
 	experimentBucketerService ExperimentService
 	holdoutService            *HoldoutService
+	bucketer                  bucketer.Bucketer
+	matcherRegistry           *matchers.MatcherRegistry
 	logger                    logging.OptimizelyLogProducer
 }
 
+// RSOptionFunc is used to pass custom config options into the RolloutService.
+type RSOptionFunc func(*RolloutService)
+
+// WithRolloutBucketer sets the bucketer used to bucket users into rollout rules and their holdouts, defaults to MurmurHash3
+func WithRolloutBucketer(rolloutBucketer bucketer.Bucketer) RSOptionFunc {
+	return func(r *RolloutService) {
+		r.bucketer = rolloutBucketer
+	}
+}
+
+// WithRolloutMatcherRegistry sets the registry of the matchers used to evaluate the audiences of rollout rules and their
+// holdouts, defaults to the global registry
+func WithRolloutMatcherRegistry(registry *matchers.MatcherRegistry) RSOptionFunc {
+	return func(r *RolloutService) {
+		r.matcherRegistry = registry
+	}
+}
+
+// WithRolloutAudienceEvaluator sets the evaluator of the audiences of rollout rules and their holdouts, defaults to a
+// CompiledTreeEvaluator. It takes precedence over WithRolloutMatcherRegistry.
+func WithRolloutAudienceEvaluator(audienceEvaluator evaluator.TreeEvaluator) RSOptionFunc {
+	return func(r *RolloutService) {
+		r.audienceTreeEvaluator = audienceEvaluator
+	}
+}
+
 // NewRolloutService returns a new instance of the Rollout service
-func NewRolloutService(sdkKey string) *RolloutService {
+func NewRolloutService(sdkKey string, options ...RSOptionFunc) *RolloutService {
 	logger := logging.GetLogger(sdkKey, "RolloutService")
-	return &RolloutService{
-		logger:                    logger,
-		audienceTreeEvaluator:     evaluator.NewMixedTreeEvaluator(logger),
-		experimentBucketerService: NewExperimentBucketerService(logging.GetLogger(sdkKey, "ExperimentBucketerService")),
-		holdoutService:            NewHoldoutService(sdkKey),
+	rolloutService := &RolloutService{
+		logger: logger,
 	}
+	for _, opt := range options {
+		opt(rolloutService)
+	}
+
+	experimentBucketerService := NewExperimentBucketerService(logging.GetLogger(sdkKey, "ExperimentBucketerService"))
+	var holdoutOptions []HSOptionFunc
+	if rolloutService.bucketer != nil {
+		experimentBucketerService.bucketer = bucketer.NewExperimentBucketer(rolloutService.bucketer)
+		holdoutOptions = append(holdoutOptions, WithHoldoutBucketer(rolloutService.bucketer))
+	}
+	if rolloutService.audienceTreeEvaluator == nil {
+		rolloutService.audienceTreeEvaluator = evaluator.NewCompiledTreeEvaluator(logger, rolloutService.matcherRegistry)
+	}
+	// the rules and their holdouts share the evaluator so that audiences are compiled once
+	experimentBucketerService.audienceTreeEvaluator = rolloutService.audienceTreeEvaluator
+	holdoutOptions = append(holdoutOptions, WithHoldoutAudienceEvaluator(rolloutService.audienceTreeEvaluator))
+	rolloutService.experimentBucketerService = experimentBucketerService
+	rolloutService.holdoutService = NewHoldoutService(sdkKey, holdoutOptions...)
+	return rolloutService
 }
 
 // GetDecision returns a decision for the given feature and user context
@@ -59,7 +105,7 @@ func (r RolloutService) GetDecision(decisionContext FeatureDecisionContext, user
 	evaluateConditionTree := func(experiment *entities.Experiment, loggingKey string) bool {
 		condTreeParams := entities.NewTreeParameters(&userContext, decisionContext.ProjectConfig.GetAudienceMap())
 		r.logger.Debug(fmt.Sprintf(logging.EvaluatingAudiencesForRollout.String(), loggingKey))
-		evalResult, _, decisionReasons := r.audienceTreeEvaluator.Evaluate(experiment.AudienceConditionTree, condTreeParams, options)
+		evalResult, decisionReasons := evaluator.EvaluateTree(r.audienceTreeEvaluator, experiment.AudienceConditionTree, condTreeParams, options)
 		reasons.Append(decisionReasons)
 		if !evalResult {
 			featureDecision.Reason = pkgReasons.FailedRolloutTargeting
@@ -95,6 +141,7 @@ func (r RolloutService) GetDecision(decisionContext FeatureDecisionContext, user
 				Decision:  Decision{Reason: pkgReasons.ForcedDecisionFound},
 			}
 			decision := r.getFeatureDecision(&featureDecision, userContext, *feature, exp, experimentDecision)
+			traceOutcome(options, decide.TraceRule, "RolloutService", exp.Key, forcedDecision, "")
 			return &decision
 		}
 		return nil
@@ -113,6 +160,7 @@ func (r RolloutService) GetDecision(decisionContext FeatureDecisionContext, user
 		// Local holdouts are evaluated after forced decisions but before audience/traffic checks.
 		if holdoutDecision, holdoutReasons := r.getLocalHoldoutDecision(experiment, decisionContext, userContext, options); holdoutDecision != nil {
 			reasons.Append(holdoutReasons)
+			traceOutcome(options, decide.TraceRule, "RolloutService", experiment.Key, nil, "captured by local holdout "+holdoutDecision.Experiment.Key)
 			return *holdoutDecision, reasons, nil
 		}
 
@@ -121,9 +169,11 @@ func (r RolloutService) GetDecision(decisionContext FeatureDecisionContext, user
 
 		evaluationResult := experiment.AudienceConditionTree == nil || evaluateConditionTree(experiment, loggingKey)
 		r.logger.Debug(fmt.Sprintf(logging.RolloutAudiencesEvaluatedTo.String(), loggingKey, evaluationResult))
+		traceAudience(options, "RolloutService", experiment.Key, evaluationResult)
 		if !evaluationResult {
 			logMessage := reasons.AddInfo(logging.UserNotInRollout.String(), userContext.ID, loggingKey)
 			r.logger.Debug(logMessage)
+			traceOutcome(options, decide.TraceRule, "RolloutService", experiment.Key, nil, "audience conditions not met, evaluating the next rule")
 			// Evaluate this user for the next rule
 			continue
 		}
@@ -131,9 +181,11 @@ func (r RolloutService) GetDecision(decisionContext FeatureDecisionContext, user
 		decision, decisionReasons, _ := r.experimentBucketerService.GetDecision(experimentDecisionContext, userContext, options)
 		reasons.Append(decisionReasons)
 		if decision.Variation == nil {
+			traceOutcome(options, decide.TraceRule, "RolloutService", experiment.Key, nil, fallThroughReason(options, experiment.Key, decision.Reason)+", evaluating the everyone else rule")
 			// Evaluate fall back rule / last rule now
 			break
 		}
+		traceOutcome(options, decide.TraceRule, "RolloutService", experiment.Key, decision.Variation, "")
 		finalFeatureDecision := r.getFeatureDecision(&featureDecision, userContext, *feature, experiment, &decision)
 		return finalFeatureDecision, reasons, nil
 	}
@@ -149,6 +201,7 @@ func (r RolloutService) GetDecision(decisionContext FeatureDecisionContext, user
 	// [FSSDK-12369] Check local holdouts for the fallback/everyone else rule
 	if holdoutDecision, holdoutReasons := r.getLocalHoldoutDecision(experiment, decisionContext, userContext, options); holdoutDecision != nil {
 		reasons.Append(holdoutReasons)
+		traceOutcome(options, decide.TraceRule, "RolloutService", experiment.Key, nil, "captured by local holdout "+holdoutDecision.Experiment.Key)
 		return *holdoutDecision, reasons, nil
 	}
 
@@ -156,6 +209,7 @@ func (r RolloutService) GetDecision(decisionContext FeatureDecisionContext, user
 	// Move to bucketing if conditionTree is unavailable or evaluation passes
 	evaluationResult := experiment.AudienceConditionTree == nil || evaluateConditionTree(experiment, "Everyone Else")
 	r.logger.Debug(fmt.Sprintf(logging.RolloutAudiencesEvaluatedTo.String(), "Everyone Else", evaluationResult))
+	traceAudience(options, "RolloutService", experiment.Key, evaluationResult)
 
 	if evaluationResult {
 		decision, decisionReasons, err := r.experimentBucketerService.GetDecision(experimentDecisionContext, userContext, options)
@@ -164,10 +218,16 @@ func (r RolloutService) GetDecision(decisionContext FeatureDecisionContext, user
 			logMessage := reasons.AddInfo(logging.UserInEveryoneElse.String(), userContext.ID)
 			r.logger.Debug(logMessage)
 		}
+		if decision.Variation != nil {
+			traceOutcome(options, decide.TraceRule, "RolloutService", experiment.Key, decision.Variation, "")
+		} else {
+			traceOutcome(options, decide.TraceRule, "RolloutService", experiment.Key, nil, fallThroughReason(options, experiment.Key, decision.Reason))
+		}
 		finalFeatureDecision := r.getFeatureDecision(&featureDecision, userContext, *feature, experiment, &decision)
 		return finalFeatureDecision, reasons, nil
 	}
 
+	traceOutcome(options, decide.TraceRule, "RolloutService", experiment.Key, nil, "audience conditions not met")
 	return featureDecision, reasons, nil
 }
 
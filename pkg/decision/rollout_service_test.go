@@ -547,7 +547,7 @@ func (s *RolloutServiceTestSuite) TestForcedDecisionBeatsLocalHoldout_Rollout()
 
 func TestNewRolloutService(t *testing.T) {
 	rolloutService := NewRolloutService("")
-	assert.IsType(t, &evaluator.MixedTreeEvaluator{}, rolloutService.audienceTreeEvaluator)
+	assert.IsType(t, &evaluator.CompiledTreeEvaluator{}, rolloutService.audienceTreeEvaluator)
 	assert.IsType(t, &ExperimentBucketerService{logger: logging.GetLogger("sdkKey", "ExperimentBucketerService")}, rolloutService.experimentBucketerService)
 }
 
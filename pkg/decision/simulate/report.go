@@ -0,0 +1,148 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package simulate //
+package simulate
+
+import (
+	"github.com/optimizely/go-sdk/v2/pkg/decision"
+	"github.com/optimizely/go-sdk/v2/pkg/entities"
+)
+
+// Report is the outcome of a simulation
+type Report struct {
+	Users int
+	Flags map[string]*FlagReport // keyed by flag key
+}
+
+// FlagReport counts the decisions made for a flag
+type FlagReport struct {
+	Key             string
+	Users           int
+	Enabled         int                    // users assigned a variation with the feature enabled, CMAB decisions excluded
+	NoDecision      int                    // users no rule or holdout assigned a variation to
+	CmabDecisions   int                    // users decided by a CMAB rule, whose variation is unknown offline
+	Errors          int                    // decisions that failed
+	HoldoutCaptures map[string]int         // keyed by holdout key
+	Rules           map[string]*RuleReport // keyed by rule ID since experiments and rollout rules may share a key
+}
+
+// HoldoutCaptureRate returns the share of the users of the flag that were captured by any holdout
+func (f FlagReport) HoldoutCaptureRate() float64 {
+	if f.Users == 0 {
+		return 0
+	}
+	captured := 0
+	for _, count := range f.HoldoutCaptures {
+		captured += count
+	}
+	return float64(captured) / float64(f.Users)
+}
+
+// RuleReport counts how the users that reached a rule were decided
+type RuleReport struct {
+	ID              string
+	Key             string
+	Source          decision.Source
+	Evaluated       int            // users the rule was evaluated for
+	AudienceMisses  int            // users that did not match the rule audiences
+	TrafficMisses   int            // users that matched the audiences but were not bucketed into a variation
+	HoldoutCaptures int            // users captured by a local holdout of the rule
+	CmabDecisions   int            // users a CMAB rule decided, they are not part of Variations
+	Variations      map[string]int // keyed by variation key
+	// ExpectedShares is the share of bucketed users each variation should get according to the traffic allocation
+	ExpectedShares map[string]float64
+}
+
+// Bucketed returns the number of users assigned a variation of the rule
+func (r RuleReport) Bucketed() int {
+	bucketed := 0
+	for _, count := range r.Variations {
+		bucketed += count
+	}
+	return bucketed
+}
+
+// Allocation returns the share of bucketed users each variation got
+func (r RuleReport) Allocation() map[string]float64 {
+	allocation := map[string]float64{}
+	bucketed := r.Bucketed()
+	if bucketed == 0 {
+		return allocation
+	}
+	for variationKey, count := range r.Variations {
+		allocation[variationKey] = float64(count) / float64(bucketed)
+	}
+	return allocation
+}
+
+// ChiSquare returns the chi-square statistic of the observed variation counts against ExpectedShares, to check
+// for a sample ratio mismatch. It has len(ExpectedShares)-1 degrees of freedom.
+func (r RuleReport) ChiSquare() float64 {
+	bucketed := float64(r.Bucketed())
+	statistic := 0.0
+	for variationKey, share := range r.ExpectedShares {
+		expected := share * bucketed
+		if expected == 0 {
+			continue
+		}
+		diff := float64(r.Variations[variationKey]) - expected
+		statistic += diff * diff / expected
+	}
+	return statistic
+}
+
+func newFlagReport(feature entities.Feature) *FlagReport {
+	flagReport := &FlagReport{
+		Key:             feature.Key,
+		HoldoutCaptures: map[string]int{},
+		Rules:           map[string]*RuleReport{},
+	}
+	for _, rule := range feature.FeatureExperiments {
+		flagReport.Rules[rule.ID] = newRuleReport(rule, decision.FeatureTest)
+	}
+	for _, rule := range feature.Rollout.Experiments {
+		flagReport.Rules[rule.ID] = newRuleReport(rule, decision.Rollout)
+	}
+	return flagReport
+}
+
+func newRuleReport(rule entities.Experiment, source decision.Source) *RuleReport {
+	ruleReport := &RuleReport{
+		ID:             rule.ID,
+		Key:            rule.Key,
+		Source:         source,
+		Variations:     map[string]int{},
+		ExpectedShares: map[string]float64{},
+	}
+
+	allocated := map[string]int{}
+	total, previousEnd := 0, 0
+	for _, trafficRange := range rule.TrafficAllocation {
+		width := trafficRange.EndOfRange - previousEnd
+		previousEnd = trafficRange.EndOfRange
+		variation, ok := rule.Variations[trafficRange.EntityID]
+		if !ok || width <= 0 {
+			continue
+		}
+		allocated[variation.Key] += width
+		total += width
+	}
+	for variationKey, width := range allocated {
+		ruleReport.ExpectedShares[variationKey] = float64(width) / float64(total)
+	}
+	return ruleReport
+}
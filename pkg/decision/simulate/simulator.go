@@ -0,0 +1,326 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package simulate runs the decision pipeline offline to report how users are distributed across flags and rules
+package simulate
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/optimizely/go-sdk/v2/pkg/cmab"
+	"github.com/optimizely/go-sdk/v2/pkg/config"
+	"github.com/optimizely/go-sdk/v2/pkg/decide"
+	"github.com/optimizely/go-sdk/v2/pkg/decision"
+	"github.com/optimizely/go-sdk/v2/pkg/decision/evaluator"
+	"github.com/optimizely/go-sdk/v2/pkg/decision/evaluator/matchers"
+	"github.com/optimizely/go-sdk/v2/pkg/entities"
+	"github.com/optimizely/go-sdk/v2/pkg/logging"
+)
+
+// UserSource provides the user contexts to simulate, Next returns false once the source is exhausted
+type UserSource interface {
+	Next() (entities.UserContext, bool)
+}
+
+type sliceSource struct {
+	users []entities.UserContext
+}
+
+func (s *sliceSource) Next() (entities.UserContext, bool) {
+	if len(s.users) == 0 {
+		return entities.UserContext{}, false
+	}
+	user := s.users[0]
+	s.users = s.users[1:]
+	return user, true
+}
+
+// UsersFromSlice returns a source of the given user contexts
+func UsersFromSlice(users []entities.UserContext) UserSource {
+	return &sliceSource{users: users}
+}
+
+type channelSource struct {
+	users <-chan entities.UserContext
+}
+
+func (s channelSource) Next() (entities.UserContext, bool) {
+	user, ok := <-s.users
+	return user, ok
+}
+
+// UsersFromChannel returns a source of the user contexts received on the channel until it is closed
+func UsersFromChannel(users <-chan entities.UserContext) UserSource {
+	return channelSource{users: users}
+}
+
+type generatedSource struct {
+	count    int
+	index    int
+	generate func(index int) entities.UserContext
+}
+
+func (s *generatedSource) Next() (entities.UserContext, bool) {
+	if s.index >= s.count {
+		return entities.UserContext{}, false
+	}
+	user := s.generate(s.index)
+	s.index++
+	return user, true
+}
+
+// GeneratedUsers returns a source of count user contexts built by generate from their index
+func GeneratedUsers(count int, generate func(index int) entities.UserContext) UserSource {
+	return &generatedSource{count: count, generate: generate}
+}
+
+// Simulator makes flag decisions for simulated users with the real decision pipeline. It never dispatches events,
+// and user profiles and forced decisions are not used. CMAB rules do not call the prediction endpoint, the users
+// they decide are counted separately since their variation is unknown offline.
+type Simulator struct {
+	decisionService   decision.Service
+	audienceEvaluator evaluator.TreeEvaluator
+	flagKeys          []string
+	decideOptions     decide.Options
+	matcherRegistry   *matchers.MatcherRegistry
+	logger            logging.OptimizelyLogProducer
+}
+
+// OptionFunc is used to provide custom configuration to the Simulator.
+type OptionFunc func(*Simulator)
+
+// WithDecisionService sets the decision service used to make decisions, defaults to a CompositeService that does not
+// call the CMAB prediction endpoint
+func WithDecisionService(decisionService decision.Service) OptionFunc {
+	return func(s *Simulator) {
+		s.decisionService = decisionService
+	}
+}
+
+// WithFlagKeys restricts the simulation to the given flags, every flag of the project config is simulated otherwise
+func WithFlagKeys(flagKeys ...string) OptionFunc {
+	return func(s *Simulator) {
+		s.flagKeys = append(s.flagKeys, flagKeys...)
+	}
+}
+
+// WithDecideOptions sets the decide options passed to the decision service
+func WithDecideOptions(decideOptions decide.Options) OptionFunc {
+	return func(s *Simulator) {
+		s.decideOptions = decideOptions
+	}
+}
+
+// WithMatcherRegistry sets the registry of the matchers used to evaluate audiences, defaults to the global registry.
+// It does not apply to a decision service provided with WithDecisionService.
+func WithMatcherRegistry(registry *matchers.MatcherRegistry) OptionFunc {
+	return func(s *Simulator) {
+		s.matcherRegistry = registry
+	}
+}
+
+// NewSimulator returns a Simulator with the given configuration
+func NewSimulator(sdkKey string, options ...OptionFunc) *Simulator {
+	logger := logging.GetLogger(sdkKey, "Simulator")
+	simulator := &Simulator{
+		logger: logger,
+	}
+	for _, opt := range options {
+		opt(simulator)
+	}
+	simulator.audienceEvaluator = evaluator.NewCompiledTreeEvaluator(logger, simulator.matcherRegistry)
+	if simulator.decisionService == nil {
+		experimentService := decision.NewCompositeExperimentService(sdkKey, decision.WithMatcherRegistry(simulator.matcherRegistry),
+			decision.WithAudienceEvaluator(simulator.audienceEvaluator), decision.WithCmabService(offlineCmabService{}))
+		simulator.decisionService = decision.NewCompositeService(sdkKey, decision.WithCompositeMatcherRegistry(simulator.matcherRegistry),
+			decision.WithCompositeAudienceEvaluator(simulator.audienceEvaluator), decision.WithCompositeExperimentService(experimentService))
+	}
+	return simulator
+}
+
+// Run decides every simulated flag for every user of the source and reports the resulting distribution
+func (s *Simulator) Run(projectConfig config.ProjectConfig, users UserSource) (*Report, error) {
+	features, err := s.features(projectConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	globalHoldouts := map[string]bool{}
+	for _, holdout := range projectConfig.GetGlobalHoldouts() {
+		globalHoldouts[holdout.ID] = true
+	}
+
+	report := &Report{Flags: map[string]*FlagReport{}}
+	for _, feature := range features {
+		report.Flags[feature.Key] = newFlagReport(feature)
+	}
+
+	for {
+		userContext, ok := users.Next()
+		if !ok {
+			break
+		}
+		report.Users++
+		for i := range features {
+			s.decide(projectConfig, &features[i], userContext, globalHoldouts, report.Flags[features[i].Key])
+		}
+	}
+	return report, nil
+}
+
+func (s *Simulator) features(projectConfig config.ProjectConfig) ([]entities.Feature, error) {
+	if len(s.flagKeys) == 0 {
+		return projectConfig.GetFeatureList(), nil
+	}
+	features := make([]entities.Feature, 0, len(s.flagKeys))
+	for _, flagKey := range s.flagKeys {
+		feature, err := projectConfig.GetFeatureByKey(flagKey)
+		if err != nil {
+			return nil, fmt.Errorf("unable to simulate flag %q: %w", flagKey, err)
+		}
+		features = append(features, feature)
+	}
+	return features, nil
+}
+
+func (s *Simulator) decide(projectConfig config.ProjectConfig, feature *entities.Feature, userContext entities.UserContext, globalHoldouts map[string]bool, flagReport *FlagReport) {
+	decisionContext := decision.FeatureDecisionContext{
+		Feature:       feature,
+		ProjectConfig: projectConfig,
+	}
+	options := s.decideOptions
+	featureDecision, _, err := s.decisionService.GetFeatureDecision(decisionContext, userContext, &options)
+	flagReport.Users++
+	if err != nil {
+		flagReport.Errors++
+		s.logger.Debug(fmt.Sprintf("Simulated decision for flag %q and user %q failed: %v", feature.Key, userContext.ID, err))
+		return
+	}
+
+	switch {
+	case featureDecision.Variation == nil:
+		flagReport.NoDecision++
+	case featureDecision.Experiment.Cmab != nil:
+		flagReport.CmabDecisions++
+	case featureDecision.Variation.FeatureEnabled:
+		flagReport.Enabled++
+	}
+
+	decidedRuleID := ""
+	if featureDecision.Variation != nil {
+		decidedRuleID = featureDecision.Experiment.ID
+	}
+	if featureDecision.Source == decision.Holdout && featureDecision.Variation != nil {
+		flagReport.HoldoutCaptures[featureDecision.Experiment.Key]++
+		if globalHoldouts[decidedRuleID] {
+			// global holdouts are evaluated before any rule
+			return
+		}
+	}
+
+	s.walkRules(projectConfig, feature, userContext, featureDecision, decidedRuleID, flagReport)
+}
+
+// walkRules replays the order rules are evaluated in to attribute the user to the rules it reached
+func (s *Simulator) walkRules(projectConfig config.ProjectConfig, feature *entities.Feature, userContext entities.UserContext,
+	featureDecision decision.FeatureDecision, decidedRuleID string, flagReport *FlagReport) {
+	// reached records the user on the rule and returns whether the decision was made there
+	reached := func(rule *entities.Experiment) bool {
+		ruleReport := flagReport.Rules[rule.ID]
+		ruleReport.Evaluated++
+		if decidedRuleID == "" {
+			return false
+		}
+		if featureDecision.Source == decision.Holdout {
+			if holdsOut(projectConfig, rule.ID, decidedRuleID) {
+				ruleReport.HoldoutCaptures++
+				return true
+			}
+			return false
+		}
+		if rule.ID == decidedRuleID {
+			if rule.Cmab != nil {
+				ruleReport.CmabDecisions++
+			} else {
+				ruleReport.Variations[featureDecision.Variation.Key]++
+			}
+			return true
+		}
+		return false
+	}
+
+	for i := range feature.FeatureExperiments {
+		rule := &feature.FeatureExperiments[i]
+		if reached(rule) {
+			return
+		}
+		s.recordMiss(projectConfig, rule, userContext, flagReport.Rules[rule.ID])
+	}
+
+	rules := feature.Rollout.Experiments
+	for i := 0; i < len(rules); i++ {
+		rule := &rules[i]
+		if reached(rule) {
+			return
+		}
+		if inAudience := s.recordMiss(projectConfig, rule, userContext, flagReport.Rules[rule.ID]); inAudience && i < len(rules)-1 {
+			// a targeted rollout rule missing on traffic falls through to the everyone else rule
+			i = len(rules) - 2
+		}
+	}
+}
+
+// recordMiss records why the user was not decided on a rule it reached and returns whether it was in the audience
+func (s *Simulator) recordMiss(projectConfig config.ProjectConfig, rule *entities.Experiment, userContext entities.UserContext, ruleReport *RuleReport) bool {
+	inAudience, _ := evaluator.CheckIfUserInAudience(rule, userContext, projectConfig, s.audienceEvaluator, &decide.Options{}, s.logger)
+	if inAudience {
+		ruleReport.TrafficMisses++
+	} else {
+		ruleReport.AudienceMisses++
+	}
+	return inAudience
+}
+
+func holdsOut(projectConfig config.ProjectConfig, ruleID, holdoutID string) bool {
+	for _, holdout := range projectConfig.GetHoldoutsForRule(ruleID) {
+		if holdout.ID == holdoutID {
+			return true
+		}
+	}
+	return false
+}
+
+// offlineCmabService stands in for the CMAB prediction endpoint. It assigns the variation with the lowest ID, which is
+// not reported.
+type offlineCmabService struct{}
+
+func (offlineCmabService) GetDecision(projectConfig config.ProjectConfig, userContext entities.UserContext, ruleID string,
+	options *decide.Options) (cmab.Decision, error) {
+	experiment, err := projectConfig.GetExperimentByID(ruleID)
+	if err != nil {
+		return cmab.Decision{}, err
+	}
+	variationIDs := make([]string, 0, len(experiment.Variations))
+	for variationID := range experiment.Variations {
+		variationIDs = append(variationIDs, variationID)
+	}
+	if len(variationIDs) == 0 {
+		return cmab.Decision{}, fmt.Errorf("CMAB rule %q has no variation", experiment.Key)
+	}
+	sort.Strings(variationIDs)
+	return cmab.Decision{VariationID: variationIDs[0]}, nil
+}
@@ -0,0 +1,184 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package simulate
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/optimizely/go-sdk/v2/pkg/config"
+	"github.com/optimizely/go-sdk/v2/pkg/config/datafileprojectconfig"
+	"github.com/optimizely/go-sdk/v2/pkg/entities"
+	"github.com/optimizely/go-sdk/v2/pkg/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const simulatedDatafile = `{
+	"version": "4", "revision": "1",
+	"audiences": [
+		{"id": "a1", "name": "us", "conditions": "[\"or\", {\"name\": \"country\", \"type\": \"custom_attribute\", \"match\": \"exact\", \"value\": \"us\"}]"},
+		{"id": "a2", "name": "ca", "conditions": "[\"or\", {\"name\": \"country\", \"type\": \"custom_attribute\", \"match\": \"exact\", \"value\": \"ca\"}]"}
+	],
+	"experiments": [{
+		"id": "e1", "key": "exp", "layerId": "l1", "status": "Running", "audienceIds": ["a1"],
+		"variations": [{"id": "v1", "key": "control", "featureEnabled": true}, {"id": "v2", "key": "treatment", "featureEnabled": true}],
+		"trafficAllocation": [{"entityId": "v1", "endOfRange": 5000}, {"entityId": "v2", "endOfRange": 10000}]
+	}],
+	"rollouts": [{"id": "r1", "experiments": [{
+		"id": "rule1", "key": "canada", "layerId": "l2", "status": "Running", "audienceIds": ["a2"],
+		"variations": [{"id": "v3", "key": "on", "featureEnabled": true}],
+		"trafficAllocation": [{"entityId": "v3", "endOfRange": 5000}]
+	}, {
+		"id": "rule2", "key": "everyone_else", "layerId": "l3", "status": "Running", "audienceIds": [],
+		"variations": [{"id": "v4", "key": "off", "featureEnabled": false}],
+		"trafficAllocation": [{"entityId": "v4", "endOfRange": 10000}]
+	}]}],
+	"featureFlags": [{"id": "f1", "key": "flag", "rolloutId": "r1", "experimentIds": ["e1"], "variables": []}],
+	%s
+}`
+
+const simulatedUsers = 3000
+
+func newSimulatedConfig(t *testing.T, holdouts string) config.ProjectConfig {
+	datafile := fmt.Sprintf(simulatedDatafile, holdouts)
+	projectConfig, err := datafileprojectconfig.NewDatafileProjectConfig([]byte(datafile), logging.GetLogger("", "SimulatorTest"))
+	require.NoError(t, err)
+	return projectConfig
+}
+
+func simulatedUser(index int) entities.UserContext {
+	countries := []string{"us", "ca", "fr"}
+	return entities.UserContext{
+		ID:         fmt.Sprintf("user_%d", index),
+		Attributes: map[string]interface{}{"country": countries[index%len(countries)]},
+	}
+}
+
+func TestSimulatorRun(t *testing.T) {
+	projectConfig := newSimulatedConfig(t, `"holdouts": [{
+		"id": "g1", "key": "global_holdout", "status": "Running", "audienceIds": [],
+		"variations": [{"id": "vg1", "key": "off"}],
+		"trafficAllocation": [{"entityId": "vg1", "endOfRange": 1000}]
+	}]`)
+
+	report, err := NewSimulator("").Run(projectConfig, GeneratedUsers(simulatedUsers, simulatedUser))
+	require.NoError(t, err)
+	assert.Equal(t, simulatedUsers, report.Users)
+
+	flag := report.Flags["flag"]
+	require.NotNil(t, flag)
+	assert.Equal(t, simulatedUsers, flag.Users)
+	assert.Zero(t, flag.Errors)
+	assert.Zero(t, flag.NoDecision)
+	assert.InDelta(t, 0.1, flag.HoldoutCaptureRate(), 0.02)
+
+	captured := flag.HoldoutCaptures["global_holdout"]
+	exp, canada, everyoneElse := flag.Rules["e1"], flag.Rules["rule1"], flag.Rules["rule2"]
+
+	assert.Equal(t, simulatedUsers-captured, exp.Evaluated)
+	assert.Zero(t, exp.TrafficMisses)
+	assert.Equal(t, exp.Evaluated, exp.AudienceMisses+exp.Bucketed())
+	assert.InDelta(t, exp.Evaluated/3, exp.Bucketed(), float64(exp.Evaluated)*0.05)
+	assert.Equal(t, map[string]float64{"control": 0.5, "treatment": 0.5}, exp.ExpectedShares)
+	// 10.83 is the chi-square critical value for p = 0.001 with one degree of freedom
+	assert.Less(t, exp.ChiSquare(), 10.83)
+
+	assert.Equal(t, exp.AudienceMisses, canada.Evaluated)
+	assert.Equal(t, canada.Evaluated, canada.AudienceMisses+canada.TrafficMisses+canada.Bucketed())
+	assert.InDelta(t, canada.TrafficMisses, canada.Bucketed(), float64(canada.Evaluated)*0.1)
+
+	assert.Equal(t, canada.AudienceMisses+canada.TrafficMisses, everyoneElse.Evaluated)
+	assert.Equal(t, everyoneElse.Evaluated, everyoneElse.Bucketed())
+	assert.Equal(t, map[string]float64{"off": 1}, everyoneElse.Allocation())
+
+	assert.Equal(t, simulatedUsers, captured+exp.Bucketed()+canada.Bucketed()+everyoneElse.Bucketed())
+	assert.Equal(t, exp.Bucketed()+canada.Bucketed(), flag.Enabled)
+}
+
+func TestSimulatorRunWithLocalHoldout(t *testing.T) {
+	projectConfig := newSimulatedConfig(t, `"localHoldouts": [{
+		"id": "h1", "key": "canada_holdout", "status": "Running", "audienceIds": [], "includedRules": ["rule1"],
+		"variations": [{"id": "vh1", "key": "off"}],
+		"trafficAllocation": [{"entityId": "vh1", "endOfRange": 10000}]
+	}]`)
+
+	report, err := NewSimulator("").Run(projectConfig, GeneratedUsers(simulatedUsers, simulatedUser))
+	require.NoError(t, err)
+
+	flag := report.Flags["flag"]
+	exp, canada := flag.Rules["e1"], flag.Rules["rule1"]
+	// local holdouts are evaluated before the rule audiences, every user reaching the rule is captured
+	assert.Equal(t, exp.AudienceMisses, canada.Evaluated)
+	assert.Equal(t, canada.Evaluated, canada.HoldoutCaptures)
+	assert.Equal(t, canada.HoldoutCaptures, flag.HoldoutCaptures["canada_holdout"])
+	assert.Zero(t, canada.AudienceMisses)
+	assert.Zero(t, flag.Rules["rule2"].Evaluated)
+}
+
+func TestSimulatorRunWithCmabRule(t *testing.T) {
+	datafile := `{
+		"version": "4", "revision": "1", "audiences": [],
+		"experiments": [{
+			"id": "e1", "key": "shared", "layerId": "l1", "status": "Running", "audienceIds": [],
+			"variations": [{"id": "v1", "key": "arm_1", "featureEnabled": true}, {"id": "v2", "key": "arm_2", "featureEnabled": true}],
+			"trafficAllocation": [], "cmab": {"attributes": [], "trafficAllocation": 5000}
+		}],
+		"rollouts": [{"id": "r1", "experiments": [{
+			"id": "rule1", "key": "shared", "layerId": "l2", "status": "Running", "audienceIds": [],
+			"variations": [{"id": "v3", "key": "off", "featureEnabled": false}],
+			"trafficAllocation": [{"entityId": "v3", "endOfRange": 10000}]
+		}]}],
+		"featureFlags": [{"id": "f1", "key": "flag", "rolloutId": "r1", "experimentIds": ["e1"], "variables": []}]
+	}`
+	projectConfig, err := datafileprojectconfig.NewDatafileProjectConfig([]byte(datafile), logging.GetLogger("", "SimulatorTest"))
+	require.NoError(t, err)
+
+	report, err := NewSimulator("").Run(projectConfig, GeneratedUsers(simulatedUsers, simulatedUser))
+	require.NoError(t, err)
+
+	// the prediction endpoint is not called, CMAB decisions are counted apart from the variations
+	flag := report.Flags["flag"]
+	assert.Zero(t, flag.Errors)
+	assert.Zero(t, flag.Enabled)
+	cmabRule, everyoneElse := flag.Rules["e1"], flag.Rules["rule1"]
+	assert.Equal(t, "shared", cmabRule.Key)
+	assert.Equal(t, "shared", everyoneElse.Key)
+	assert.Equal(t, simulatedUsers, cmabRule.Evaluated)
+	assert.Empty(t, cmabRule.Variations)
+	assert.InDelta(t, simulatedUsers/2, cmabRule.CmabDecisions, simulatedUsers*0.05)
+	assert.Equal(t, cmabRule.CmabDecisions, flag.CmabDecisions)
+	assert.Equal(t, cmabRule.TrafficMisses, everyoneElse.Evaluated)
+	assert.Equal(t, everyoneElse.Evaluated, everyoneElse.Variations["off"])
+}
+
+func TestSimulatorRunWithFlagKeys(t *testing.T) {
+	projectConfig := newSimulatedConfig(t, `"holdouts": []`)
+
+	users := make(chan entities.UserContext, 2)
+	users <- simulatedUser(0)
+	users <- simulatedUser(1)
+	close(users)
+	report, err := NewSimulator("", WithFlagKeys("flag")).Run(projectConfig, UsersFromChannel(users))
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Users)
+	assert.Equal(t, 2, report.Flags["flag"].Users)
+
+	_, err = NewSimulator("", WithFlagKeys("unknown")).Run(projectConfig, UsersFromSlice(nil))
+	assert.Error(t, err)
+}
@@ -0,0 +1,124 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package decision //
+package decision
+
+import (
+	"github.com/optimizely/go-sdk/v2/pkg/decide"
+	"github.com/optimizely/go-sdk/v2/pkg/decision/bucketer"
+	pkgReasons "github.com/optimizely/go-sdk/v2/pkg/decision/reasons"
+	"github.com/optimizely/go-sdk/v2/pkg/entities"
+)
+
+// bucketLocator is implemented by experiment bucketers able to tell where a bucket key falls in a traffic allocation
+type bucketLocator interface {
+	LocateBucket(bucketKey string, trafficAllocation []entities.Range) (bucketValue, rangeIndex int)
+}
+
+// traceOutcome records the outcome of a rule or holdout, the variation is nil when it fell through for the given reason
+func traceOutcome(options *decide.Options, kind decide.TraceStepKind, service, ruleKey string, variation *entities.Variation, reason string) {
+	trace := options.GetTrace()
+	if trace == nil {
+		return
+	}
+	step := decide.TraceStep{Kind: kind, Service: service, RuleKey: ruleKey, Reason: reason}
+	if variation != nil {
+		step.Matched = true
+		step.VariationKey = variation.Key
+	}
+	trace.AddStep(step)
+}
+
+// fallThroughReason describes why a rule did not decide a variation. The experiment services do not always tell why,
+// the last failed audience or bucketing step recorded for the rule is used then.
+func fallThroughReason(options *decide.Options, ruleKey string, reason pkgReasons.Reason) string {
+	if reason != "" {
+		return string(reason)
+	}
+	if trace := options.GetTrace(); trace != nil {
+		for i := len(trace.Steps) - 1; i >= 0; i-- {
+			step := trace.Steps[i]
+			if step.RuleKey != ruleKey || step.Matched {
+				continue
+			}
+			switch step.Kind {
+			case decide.TraceAudience:
+				return string(pkgReasons.FailedAudienceTargeting)
+			case decide.TraceBucketing:
+				return step.Reason
+			}
+		}
+	}
+	return string(pkgReasons.NotBucketedIntoVariation)
+}
+
+// traceAudience records the result of the audience evaluation of a rule or holdout with the result of every node
+func traceAudience(options *decide.Options, service, ruleKey string, inAudience bool) {
+	options.GetTrace().AddStep(decide.TraceStep{Kind: decide.TraceAudience, Service: service, RuleKey: ruleKey, Matched: inAudience})
+}
+
+// traceBucketing records where the bucketing ID fell in the traffic allocations of the mutex group and of the experiment
+func traceBucketing(options *decide.Options, service string, experimentBucketer bucketer.ExperimentBucketer, bucketingID string,
+	experiment entities.Experiment, group entities.Group, variation *entities.Variation) {
+	trace := options.GetTrace()
+	if trace == nil {
+		return
+	}
+	locator, ok := experimentBucketer.(bucketLocator)
+	if !ok {
+		trace.AddStep(decide.TraceStep{Kind: decide.TraceBucketing, Service: service, RuleKey: experiment.Key, Matched: variation != nil,
+			Reason: "bucketer does not report bucketing values"})
+		return
+	}
+
+	step := decide.TraceStep{Kind: decide.TraceBucketing, Service: service, RuleKey: experiment.Key}
+	if experiment.GroupID != "" && group.Policy == "random" {
+		result := locateBucket(locator, bucketingID, group.ID, group.TrafficAllocation)
+		result.GroupID = group.ID
+		if result.EntityID != experiment.ID {
+			step.Bucketing = result
+			step.Reason = "not bucketed into this experiment of the mutually exclusive group"
+			trace.AddStep(step)
+			return
+		}
+	}
+
+	step.Bucketing = locateBucket(locator, bucketingID, experiment.ID, experiment.TrafficAllocation)
+	if experiment.GroupID != "" && group.Policy == "random" {
+		step.Bucketing.GroupID = group.ID
+	}
+	if variation != nil {
+		step.Matched = true
+		step.VariationKey = variation.Key
+	} else {
+		step.Reason = "bucketing value is not in a variation's traffic range"
+	}
+	trace.AddStep(step)
+}
+
+func locateBucket(locator bucketLocator, bucketingID, entityID string, trafficAllocation []entities.Range) *decide.BucketingResult {
+	bucketValue, rangeIndex := locator.LocateBucket(bucketingID+entityID, trafficAllocation)
+	result := &decide.BucketingResult{BucketingID: bucketingID, BucketValue: bucketValue}
+	if rangeIndex >= 0 {
+		if rangeIndex > 0 {
+			result.RangeStart = trafficAllocation[rangeIndex-1].EndOfRange
+		}
+		result.RangeEnd = trafficAllocation[rangeIndex].EndOfRange
+		result.EntityID = trafficAllocation[rangeIndex].EntityID
+	}
+	return result
+}
@@ -0,0 +1,126 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package decision //
+package decision
+
+import (
+	"context"
+	"fmt"
+)
+
+// LookupUserProfile looks the user profile up with LookupWithContext when the service is a ContextUserProfileService,
+// with Lookup otherwise
+func LookupUserProfile(ctx context.Context, userProfileService UserProfileService, userID string) (UserProfile, error) {
+	contextService, ok := userProfileService.(ContextUserProfileService)
+	if !ok {
+		return userProfileService.Lookup(userID), nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	userProfile, err := contextService.LookupWithContext(ctx, userID)
+	if err != nil {
+		return UserProfile{}, fmt.Errorf(`user profile lookup failed for user "%s": %w`, userID, err)
+	}
+	return userProfile, nil
+}
+
+// SaveUserProfile saves the user profile with SaveWithContext when the service is a ContextUserProfileService, with
+// Save otherwise
+func SaveUserProfile(ctx context.Context, userProfileService UserProfileService, userProfile UserProfile) error {
+	contextService, ok := userProfileService.(ContextUserProfileService)
+	if !ok {
+		userProfileService.Save(userProfile)
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := contextService.SaveWithContext(ctx, userProfile); err != nil {
+		return fmt.Errorf(`user profile save failed for user "%s": %w`, userProfile.ID, err)
+	}
+	return nil
+}
+
+// LookupUserProfiles looks the profiles of the users up with LookupBatch when the service is a
+// BatchUserProfileService, one user at a time otherwise. Users without a saved profile get a profile with an empty
+// ID, the lookup errors are returned by user ID.
+func LookupUserProfiles(ctx context.Context, userProfileService UserProfileService, userIDs []string) (map[string]UserProfile, map[string]error) {
+	userProfiles := make(map[string]UserProfile, len(userIDs))
+	var errs map[string]error
+	batchService, ok := userProfileService.(BatchUserProfileService)
+	if !ok {
+		for _, userID := range userIDs {
+			userProfile, err := LookupUserProfile(ctx, userProfileService, userID)
+			if err != nil {
+				if errs == nil {
+					errs = map[string]error{}
+				}
+				errs[userID] = err
+			}
+			userProfiles[userID] = userProfile
+		}
+		return userProfiles, errs
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	found, err := batchService.LookupBatch(ctx, userIDs)
+	if err != nil {
+		errs = make(map[string]error, len(userIDs))
+	}
+	for _, userID := range userIDs {
+		userProfiles[userID] = found[userID]
+		if err != nil {
+			errs[userID] = fmt.Errorf(`user profile lookup failed for user "%s": %w`, userID, err)
+		}
+	}
+	return userProfiles, errs
+}
+
+// SaveUserProfiles saves the profiles with SaveBatch when the service is a BatchUserProfileService, one profile at a
+// time otherwise. The save errors are returned by user ID.
+func SaveUserProfiles(ctx context.Context, userProfileService UserProfileService, userProfiles []UserProfile) map[string]error {
+	if len(userProfiles) == 0 {
+		return nil
+	}
+	var errs map[string]error
+	batchService, ok := userProfileService.(BatchUserProfileService)
+	if !ok {
+		for _, userProfile := range userProfiles {
+			if err := SaveUserProfile(ctx, userProfileService, userProfile); err != nil {
+				if errs == nil {
+					errs = map[string]error{}
+				}
+				errs[userProfile.ID] = err
+			}
+		}
+		return errs
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := batchService.SaveBatch(ctx, userProfiles); err != nil {
+		errs = make(map[string]error, len(userProfiles))
+		for _, userProfile := range userProfiles {
+			errs[userProfile.ID] = fmt.Errorf(`user profile save failed for user "%s": %w`, userProfile.ID, err)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,70 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package decision
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLookupUserProfiles(t *testing.T) {
+	userProfileService := new(MockContextUserProfileService)
+	userProfileService.On("LookupWithContext", mock.Anything, "user_1").Return(UserProfile{ID: "user_1"}, nil)
+	userProfileService.On("LookupWithContext", mock.Anything, "user_2").Return(UserProfile{}, errors.New("timeout"))
+	userProfileService.On("LookupWithContext", mock.Anything, "user_3").Return(UserProfile{}, nil)
+
+	userProfiles, errs := LookupUserProfiles(context.Background(), userProfileService, []string{"user_1", "user_2", "user_3"})
+	assert.Equal(t, map[string]UserProfile{"user_1": {ID: "user_1"}, "user_2": {}, "user_3": {}}, userProfiles)
+	assert.Len(t, errs, 1)
+	assert.EqualError(t, errs["user_2"], `user profile lookup failed for user "user_2": timeout`)
+}
+
+func TestLookupUserProfilesWithBatchService(t *testing.T) {
+	userProfileService := new(MockBatchUserProfileService)
+	userProfileService.On("LookupBatch", mock.Anything, []string{"user_1", "user_2"}).Return(map[string]UserProfile{"user_1": {ID: "user_1"}}, nil).Once()
+
+	userProfiles, errs := LookupUserProfiles(context.Background(), userProfileService, []string{"user_1", "user_2"})
+	assert.Equal(t, map[string]UserProfile{"user_1": {ID: "user_1"}, "user_2": {}}, userProfiles)
+	assert.Nil(t, errs)
+	userProfileService.AssertNotCalled(t, "Lookup", mock.Anything)
+
+	userProfileService.On("LookupBatch", mock.Anything, []string{"user_1", "user_2"}).Return(map[string]UserProfile(nil), errors.New("timeout"))
+	userProfiles, errs = LookupUserProfiles(context.Background(), userProfileService, []string{"user_1", "user_2"})
+	assert.Equal(t, map[string]UserProfile{"user_1": {}, "user_2": {}}, userProfiles)
+	assert.EqualError(t, errs["user_1"], `user profile lookup failed for user "user_1": timeout`)
+	assert.EqualError(t, errs["user_2"], `user profile lookup failed for user "user_2": timeout`)
+}
+
+func TestSaveUserProfiles(t *testing.T) {
+	userProfileService := new(MockUserProfileService)
+	userProfileService.On("Save", mock.Anything)
+	assert.Nil(t, SaveUserProfiles(context.Background(), userProfileService, []UserProfile{{ID: "user_1"}, {ID: "user_2"}}))
+	userProfileService.AssertNumberOfCalls(t, "Save", 2)
+
+	batchUserProfileService := new(MockBatchUserProfileService)
+	assert.Nil(t, SaveUserProfiles(context.Background(), batchUserProfileService, nil))
+	batchUserProfileService.On("SaveBatch", mock.Anything, []UserProfile{{ID: "user_1"}, {ID: "user_2"}}).Return(errors.New("read-only replica"))
+	errs := SaveUserProfiles(context.Background(), batchUserProfileService, []UserProfile{{ID: "user_1"}, {ID: "user_2"}})
+	assert.Len(t, errs, 2)
+	assert.EqualError(t, errs["user_2"], `user profile save failed for user "user_2": read-only replica`)
+	batchUserProfileService.AssertNumberOfCalls(t, "SaveBatch", 1)
+	batchUserProfileService.AssertNotCalled(t, "Save", mock.Anything)
+}
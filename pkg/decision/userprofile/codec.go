@@ -0,0 +1,73 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package userprofile provides UserProfileService implementations: an in-memory LRU cache, a file backed store and an
+// adapter for key-value stores such as Redis.
+package userprofile
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/optimizely/go-sdk/v2/pkg/decision"
+)
+
+// storedProfile is the JSON representation of a user profile, the one used by the other Optimizely SDKs
+type storedProfile struct {
+	UserID              string                       `json:"user_id"`
+	ExperimentBucketMap map[string]map[string]string `json:"experiment_bucket_map"`
+}
+
+// Marshal encodes the user profile as {"user_id": ..., "experiment_bucket_map": {experimentID: {"variation_id": ...}}}
+func Marshal(userProfile decision.UserProfile) ([]byte, error) {
+	stored := storedProfile{UserID: userProfile.ID, ExperimentBucketMap: make(map[string]map[string]string, len(userProfile.ExperimentBucketMap))}
+	for decisionKey, value := range userProfile.ExperimentBucketMap {
+		fields, ok := stored.ExperimentBucketMap[decisionKey.ExperimentID]
+		if !ok {
+			fields = map[string]string{}
+			stored.ExperimentBucketMap[decisionKey.ExperimentID] = fields
+		}
+		fields[decisionKey.Field] = value
+	}
+	return json.Marshal(stored)
+}
+
+// Unmarshal decodes a user profile encoded by Marshal
+func Unmarshal(data []byte) (decision.UserProfile, error) {
+	var stored storedProfile
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return decision.UserProfile{}, err
+	}
+	if stored.UserID == "" {
+		return decision.UserProfile{}, errors.New("stored user profile has no user ID")
+	}
+	userProfile := decision.UserProfile{ID: stored.UserID, ExperimentBucketMap: map[decision.UserDecisionKey]string{}}
+	for experimentID, fields := range stored.ExperimentBucketMap {
+		for field, value := range fields {
+			userProfile.ExperimentBucketMap[decision.UserDecisionKey{ExperimentID: experimentID, Field: field}] = value
+		}
+	}
+	return userProfile, nil
+}
+
+// clone copies the profile so that the caller and the store do not share the bucket map
+func clone(userProfile decision.UserProfile) decision.UserProfile {
+	experimentBucketMap := make(map[decision.UserDecisionKey]string, len(userProfile.ExperimentBucketMap))
+	for decisionKey, value := range userProfile.ExperimentBucketMap {
+		experimentBucketMap[decisionKey] = value
+	}
+	return decision.UserProfile{ID: userProfile.ID, ExperimentBucketMap: experimentBucketMap}
+}
@@ -0,0 +1,52 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package userprofile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/optimizely/go-sdk/v2/pkg/decision"
+)
+
+func testProfile(userID string, variationID string) decision.UserProfile {
+	return decision.UserProfile{
+		ID:                  userID,
+		ExperimentBucketMap: map[decision.UserDecisionKey]string{decision.NewUserDecisionKey("exp_1"): variationID},
+	}
+}
+
+func TestMarshalUnmarshal(t *testing.T) {
+	userProfile := testProfile("user_1", "var_1")
+	data, err := Marshal(userProfile)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"user_id": "user_1", "experiment_bucket_map": {"exp_1": {"variation_id": "var_1"}}}`, string(data))
+
+	decoded, err := Unmarshal(data)
+	require.NoError(t, err)
+	assert.Equal(t, userProfile, decoded)
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	_, err := Unmarshal([]byte(`{"user_id": "user_1"`))
+	assert.Error(t, err)
+
+	_, err = Unmarshal([]byte(`{"experiment_bucket_map": {}}`))
+	assert.EqualError(t, err, "stored user profile has no user ID")
+}
@@ -0,0 +1,262 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package userprofile //
+package userprofile
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/optimizely/go-sdk/v2/pkg/decision"
+	"github.com/optimizely/go-sdk/v2/pkg/logging"
+)
+
+// minCompactionRecords is the number of records the file must hold before it is compacted
+const minCompactionRecords = 1000
+
+// maxRecordSize bounds the size of a single profile record read from the file
+const maxRecordSize = 16 * 1024 * 1024
+
+// ErrFileServiceClosed is returned when saving into a closed FileService
+var ErrFileServiceClosed = errors.New("file user profile service is closed")
+
+// FileService is a UserProfileService keeping every profile in a single local file, one JSON record per line. The
+// profiles are held in memory and each save appends a record, the file is compacted once it holds twice as many
+// records as profiles. It suits a single process whose profiles fit in memory.
+type FileService struct {
+	path     string
+	lock     sync.Mutex
+	file     *os.File
+	profiles map[string]decision.UserProfile
+	records  int
+	logger   logging.OptimizelyLogProducer
+}
+
+// OpenFileService loads the profiles stored at the given path and returns a FileService appending to it, the file and
+// its directory are created when they do not exist. Records that cannot be decoded, e.g. one truncated by a crash,
+// are skipped.
+func OpenFileService(path string) (*FileService, error) {
+	service := &FileService{
+		path:     path,
+		profiles: map[string]decision.UserProfile{},
+		logger:   logging.GetLogger("", "FileUserProfileService"),
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, err
+	}
+	if err := service.load(); err != nil {
+		return nil, err
+	}
+
+	var err error
+	// a record left without its newline by a crash is rewritten cleanly before appending after it
+	if service.records > len(service.profiles) || !service.endsWithNewline() {
+		err = service.compact()
+	} else {
+		service.file, err = service.openForAppend()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return service, nil
+}
+
+// Lookup returns the saved profile of the user, a profile with an empty ID when none was saved
+func (s *FileService) Lookup(userID string) decision.UserProfile {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if userProfile, ok := s.profiles[userID]; ok {
+		return clone(userProfile)
+	}
+	return decision.UserProfile{}
+}
+
+// Save saves the profile, failures are logged
+func (s *FileService) Save(userProfile decision.UserProfile) {
+	if err := s.SaveWithContext(context.Background(), userProfile); err != nil {
+		s.logger.Error("Unable to save the user profile.", err)
+	}
+}
+
+// LookupWithContext is Lookup, it fails when the context is done
+func (s *FileService) LookupWithContext(ctx context.Context, userID string) (decision.UserProfile, error) {
+	if err := ctx.Err(); err != nil {
+		return decision.UserProfile{}, err
+	}
+	return s.Lookup(userID), nil
+}
+
+// SaveWithContext appends the profile to the file
+func (s *FileService) SaveWithContext(ctx context.Context, userProfile decision.UserProfile) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if userProfile.ID == "" {
+		return nil
+	}
+	data, err := Marshal(userProfile)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.file == nil {
+		return ErrFileServiceClosed
+	}
+	if _, err = s.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	s.profiles[userProfile.ID] = clone(userProfile)
+	s.records++
+
+	if s.records >= minCompactionRecords && s.records > 2*len(s.profiles) {
+		return s.compact()
+	}
+	return nil
+}
+
+// Compact rewrites the file with a single record per profile
+func (s *FileService) Compact() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.file == nil {
+		return ErrFileServiceClosed
+	}
+	return s.compact()
+}
+
+// Close closes the file, the profiles can still be looked up but no longer saved
+func (s *FileService) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+func (s *FileService) load() error {
+	file, err := os.Open(s.path) // #nosec G304 - path is given by the SDK user
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, maxRecordSize)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		s.records++
+		userProfile, err := Unmarshal(line)
+		if err != nil {
+			s.logger.Warning("Skipping an invalid user profile record: " + err.Error())
+			continue
+		}
+		s.profiles[userProfile.ID] = userProfile
+	}
+	return scanner.Err()
+}
+
+func (s *FileService) endsWithNewline() bool {
+	file, err := os.Open(s.path) // #nosec G304 - path is given by the SDK user
+	if err != nil {
+		return true
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil || info.Size() == 0 {
+		return true
+	}
+	last := make([]byte, 1)
+	if _, err = file.ReadAt(last, info.Size()-1); err != nil {
+		return true
+	}
+	return last[0] == '\n'
+}
+
+func (s *FileService) openForAppend() (*os.File, error) {
+	return os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600) // #nosec G304 - path is given by the SDK user
+}
+
+// compact writes every profile into a temporary file which then replaces the file, so that a crash never loses the
+// profiles saved before
+func (s *FileService) compact() error {
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".user-profiles-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// no-op once the temporary file has been renamed
+		_ = os.Remove(tmp.Name())
+	}()
+
+	userIDs := make([]string, 0, len(s.profiles))
+	for userID := range s.profiles {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Strings(userIDs)
+
+	writer := bufio.NewWriter(tmp)
+	for _, userID := range userIDs {
+		data, err := Marshal(s.profiles[userID])
+		if err != nil {
+			_ = tmp.Close()
+			return err
+		}
+		_, _ = writer.Write(data)
+		_ = writer.WriteByte('\n')
+	}
+	if err = writer.Flush(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	if s.file != nil {
+		_ = s.file.Close()
+		s.file = nil
+	}
+	if err = os.Rename(tmp.Name(), s.path); err != nil {
+		// keep appending to the previous file
+		s.file, _ = s.openForAppend()
+		return err
+	}
+	s.records = len(userIDs)
+	s.file, err = s.openForAppend()
+	return err
+}
@@ -0,0 +1,100 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package userprofile
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/optimizely/go-sdk/v2/pkg/decision"
+)
+
+func countLines(t *testing.T, path string) int {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return strings.Count(string(data), "\n")
+}
+
+func TestFileService(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles", "ups.jsonl")
+	service, err := OpenFileService(path)
+	require.NoError(t, err)
+	var _ decision.ContextUserProfileService = service
+
+	assert.Equal(t, decision.UserProfile{}, service.Lookup("user_1"))
+	service.Save(testProfile("user_1", "var_1"))
+	service.Save(testProfile("user_2", "var_1"))
+	service.Save(testProfile("user_1", "var_2"))
+	assert.Equal(t, testProfile("user_1", "var_2"), service.Lookup("user_1"))
+	assert.Equal(t, 3, countLines(t, path))
+	require.NoError(t, service.Close())
+
+	assert.ErrorIs(t, service.SaveWithContext(context.Background(), testProfile("user_3", "var_1")), ErrFileServiceClosed)
+	assert.Equal(t, testProfile("user_2", "var_1"), service.Lookup("user_2"))
+
+	// reopening compacts the superseded records
+	service, err = OpenFileService(path)
+	require.NoError(t, err)
+	defer service.Close()
+	assert.Equal(t, testProfile("user_1", "var_2"), service.Lookup("user_1"))
+	assert.Equal(t, testProfile("user_2", "var_1"), service.Lookup("user_2"))
+	assert.Equal(t, 2, countLines(t, path))
+}
+
+func TestFileServiceSkipsInvalidRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ups.jsonl")
+	valid, err := Marshal(testProfile("user_1", "var_1"))
+	require.NoError(t, err)
+	// the last record was truncated by a crash
+	require.NoError(t, os.WriteFile(path, []byte(string(valid)+"\n"+string(valid[:10])), 0o600))
+
+	service, err := OpenFileService(path)
+	require.NoError(t, err)
+	defer service.Close()
+	assert.Equal(t, testProfile("user_1", "var_1"), service.Lookup("user_1"))
+
+	service.Save(testProfile("user_2", "var_1"))
+	reopened, err := OpenFileService(path)
+	require.NoError(t, err)
+	defer reopened.Close()
+	assert.Equal(t, testProfile("user_1", "var_1"), reopened.Lookup("user_1"))
+	assert.Equal(t, testProfile("user_2", "var_1"), reopened.Lookup("user_2"))
+}
+
+func TestFileServiceCompactsWhileSaving(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ups.jsonl")
+	service, err := OpenFileService(path)
+	require.NoError(t, err)
+	defer service.Close()
+
+	for i := 0; i < minCompactionRecords; i++ {
+		service.Save(testProfile(fmt.Sprintf("user_%d", i%10), fmt.Sprintf("var_%d", i)))
+	}
+	assert.Equal(t, 10, countLines(t, path))
+	assert.Equal(t, testProfile("user_9", fmt.Sprintf("var_%d", minCompactionRecords-1)), service.Lookup("user_9"))
+
+	service.Save(testProfile("user_10", "var_1"))
+	require.NoError(t, service.Compact())
+	assert.Equal(t, 11, countLines(t, path))
+}
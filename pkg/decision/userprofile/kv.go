@@ -0,0 +1,258 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package userprofile //
+package userprofile
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/optimizely/go-sdk/v2/pkg/decision"
+	"github.com/optimizely/go-sdk/v2/pkg/logging"
+)
+
+const (
+	// DefaultKeyPrefix is prepended to user IDs to build the keys of their profiles
+	DefaultKeyPrefix = "optimizely:user-profile:"
+	// DefaultKeyValueTimeout bounds every lookup and save, decisions wait for the lookup
+	DefaultKeyValueTimeout = 500 * time.Millisecond
+)
+
+// KeyValueStore is the storage of a KeyValueService. A Redis client satisfies it with a thin adapter, e.g. with
+// go-redis:
+//
+//	func (s redisStore) Get(ctx context.Context, key string) ([]byte, error) {
+//		value, err := s.client.Get(ctx, key).Bytes()
+//		if errors.Is(err, redis.Nil) {
+//			return nil, nil
+//		}
+//		return value, err
+//	}
+//
+//	func (s redisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+//		return s.client.Set(ctx, key, value, ttl).Err()
+//	}
+type KeyValueStore interface {
+	// Get returns the value of the key, nil without error when the key does not exist
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set stores the value of the key, it expires after the ttl when positive
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// BatchKeyValueStore is a KeyValueStore able to get and set several keys in a single round-trip, e.g. with Redis MGET
+// and a pipeline of SETs
+type BatchKeyValueStore interface {
+	KeyValueStore
+	// GetMany returns the values of the keys in the order of the keys, nil for the keys that do not exist
+	GetMany(ctx context.Context, keys []string) ([][]byte, error)
+	// SetMany stores the values by key, they expire after the ttl when positive
+	SetMany(ctx context.Context, values map[string][]byte, ttl time.Duration) error
+}
+
+// KeyValueService is a UserProfileService storing the profiles encoded by Marshal in a KeyValueStore. It is a
+// decision.BatchUserProfileService, the batches take a single round-trip when the store is a BatchKeyValueStore.
+type KeyValueService struct {
+	store     KeyValueStore
+	keyPrefix string
+	ttl       time.Duration
+	timeout   time.Duration
+	logger    logging.OptimizelyLogProducer
+}
+
+// KeyValueOptionFunc is used to provide custom configuration to the KeyValueService.
+type KeyValueOptionFunc func(*KeyValueService)
+
+// WithKeyPrefix sets the prefix of the keys of the profiles, defaults to DefaultKeyPrefix
+func WithKeyPrefix(keyPrefix string) KeyValueOptionFunc {
+	return func(s *KeyValueService) {
+		s.keyPrefix = keyPrefix
+	}
+}
+
+// WithTTL sets how long the profiles are kept after they were last saved, they are kept until evicted by default
+func WithTTL(ttl time.Duration) KeyValueOptionFunc {
+	return func(s *KeyValueService) {
+		s.ttl = ttl
+	}
+}
+
+// WithTimeout bounds every lookup and save, defaults to DefaultKeyValueTimeout. It does not apply when not positive.
+func WithTimeout(timeout time.Duration) KeyValueOptionFunc {
+	return func(s *KeyValueService) {
+		s.timeout = timeout
+	}
+}
+
+// WithLogger sets the logger reporting the failures of Lookup and Save
+func WithLogger(logger logging.OptimizelyLogProducer) KeyValueOptionFunc {
+	return func(s *KeyValueService) {
+		s.logger = logger
+	}
+}
+
+// NewKeyValueService returns a KeyValueService storing the profiles in the given store
+func NewKeyValueService(store KeyValueStore, options ...KeyValueOptionFunc) *KeyValueService {
+	service := &KeyValueService{
+		store:     store,
+		keyPrefix: DefaultKeyPrefix,
+		timeout:   DefaultKeyValueTimeout,
+		logger:    logging.GetLogger("", "KeyValueUserProfileService"),
+	}
+	for _, opt := range options {
+		opt(service)
+	}
+	return service
+}
+
+// Lookup returns the saved profile of the user, a profile with an empty ID when none was saved or the lookup failed
+func (s *KeyValueService) Lookup(userID string) decision.UserProfile {
+	userProfile, err := s.LookupWithContext(context.Background(), userID)
+	if err != nil {
+		s.logger.Error("Unable to look up the user profile.", err)
+	}
+	return userProfile
+}
+
+// Save saves the profile, failures are logged
+func (s *KeyValueService) Save(userProfile decision.UserProfile) {
+	if err := s.SaveWithContext(context.Background(), userProfile); err != nil {
+		s.logger.Error("Unable to save the user profile.", err)
+	}
+}
+
+// LookupWithContext returns the saved profile of the user, a profile with an empty ID when none was saved.
+// A profile that cannot be decoded is logged and treated as not saved so that the decisions of the user are saved again.
+func (s *KeyValueService) LookupWithContext(ctx context.Context, userID string) (decision.UserProfile, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	data, err := s.store.Get(ctx, s.keyPrefix+userID)
+	if err != nil || data == nil {
+		return decision.UserProfile{}, err
+	}
+	userProfile, _ := s.decode(userID, data)
+	return userProfile, nil
+}
+
+// SaveWithContext saves the profile
+func (s *KeyValueService) SaveWithContext(ctx context.Context, userProfile decision.UserProfile) error {
+	if userProfile.ID == "" {
+		return nil
+	}
+	data, err := Marshal(userProfile)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	return s.store.Set(ctx, s.keyPrefix+userProfile.ID, data, s.ttl)
+}
+
+// LookupBatch returns the saved profiles of the users keyed by user ID, users without a saved profile are left out.
+// A profile that cannot be decoded is logged and treated as not saved, like in LookupWithContext.
+func (s *KeyValueService) LookupBatch(ctx context.Context, userIDs []string) (map[string]decision.UserProfile, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	keys := make([]string, len(userIDs))
+	for i, userID := range userIDs {
+		keys[i] = s.keyPrefix + userID
+	}
+	values, err := s.getMany(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	userProfiles := make(map[string]decision.UserProfile, len(userIDs))
+	for i, data := range values {
+		if data == nil {
+			continue
+		}
+		if userProfile, ok := s.decode(userIDs[i], data); ok {
+			userProfiles[userIDs[i]] = userProfile
+		}
+	}
+	return userProfiles, nil
+}
+
+// SaveBatch saves the profiles
+func (s *KeyValueService) SaveBatch(ctx context.Context, userProfiles []decision.UserProfile) error {
+	values := make(map[string][]byte, len(userProfiles))
+	for _, userProfile := range userProfiles {
+		if userProfile.ID == "" {
+			continue
+		}
+		data, err := Marshal(userProfile)
+		if err != nil {
+			return err
+		}
+		values[s.keyPrefix+userProfile.ID] = data
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	if batchStore, ok := s.store.(BatchKeyValueStore); ok {
+		return batchStore.SetMany(ctx, values, s.ttl)
+	}
+	for key, data := range values {
+		if err := s.store.Set(ctx, key, data, s.ttl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *KeyValueService) getMany(ctx context.Context, keys []string) ([][]byte, error) {
+	if batchStore, ok := s.store.(BatchKeyValueStore); ok {
+		values, err := batchStore.GetMany(ctx, keys)
+		if err == nil && len(values) != len(keys) {
+			err = fmt.Errorf("key-value store returned %d values for %d keys", len(values), len(keys))
+		}
+		return values, err
+	}
+	values := make([][]byte, len(keys))
+	for i, key := range keys {
+		data, err := s.store.Get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = data
+	}
+	return values, nil
+}
+
+func (s *KeyValueService) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, s.timeout)
+}
+
+// decode returns the profile stored for the user, false when it cannot be decoded
+func (s *KeyValueService) decode(userID string, data []byte) (decision.UserProfile, bool) {
+	userProfile, err := Unmarshal(data)
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("Unable to decode the user profile of user %s.", userID), err)
+		return decision.UserProfile{}, false
+	}
+	return userProfile, true
+}
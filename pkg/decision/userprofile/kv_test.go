@@ -0,0 +1,204 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package userprofile
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/optimizely/go-sdk/v2/pkg/decision"
+)
+
+type memoryStore struct {
+	lock   sync.Mutex
+	values map[string][]byte
+	ttls   map[string]time.Duration
+	err    error
+	delay  time.Duration
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{values: map[string][]byte{}, ttls: map[string]time.Duration{}}
+}
+
+func (s *memoryStore) wait(ctx context.Context) error {
+	if s.err != nil {
+		return s.err
+	}
+	select {
+	case <-time.After(s.delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *memoryStore) Get(ctx context.Context, key string) ([]byte, error) {
+	if err := s.wait(ctx); err != nil {
+		return nil, err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.values[key], nil
+}
+
+func (s *memoryStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := s.wait(ctx); err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.values[key] = value
+	s.ttls[key] = ttl
+	return nil
+}
+
+type memoryBatchStore struct {
+	*memoryStore
+	getManyCalls int
+	setManyCalls int
+}
+
+func (s *memoryBatchStore) GetMany(ctx context.Context, keys []string) ([][]byte, error) {
+	s.getManyCalls++
+	values := make([][]byte, len(keys))
+	for i, key := range keys {
+		value, err := s.Get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+func (s *memoryBatchStore) SetMany(ctx context.Context, values map[string][]byte, ttl time.Duration) error {
+	s.setManyCalls++
+	for key, value := range values {
+		if err := s.Set(ctx, key, value, ttl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestKeyValueService(t *testing.T) {
+	store := newMemoryStore()
+	service := NewKeyValueService(store, WithKeyPrefix("ups:"), WithTTL(time.Hour))
+	var _ decision.ContextUserProfileService = service
+
+	assert.Equal(t, decision.UserProfile{}, service.Lookup("user_1"))
+
+	service.Save(testProfile("user_1", "var_1"))
+	assert.JSONEq(t, `{"user_id": "user_1", "experiment_bucket_map": {"exp_1": {"variation_id": "var_1"}}}`, string(store.values["ups:user_1"]))
+	assert.Equal(t, time.Hour, store.ttls["ups:user_1"])
+	assert.Equal(t, testProfile("user_1", "var_1"), service.Lookup("user_1"))
+
+	service.Save(decision.UserProfile{})
+	assert.Len(t, store.values, 1)
+}
+
+func TestKeyValueServiceErrors(t *testing.T) {
+	store := newMemoryStore()
+	service := NewKeyValueService(store)
+
+	store.err = errors.New("connection refused")
+	_, err := service.LookupWithContext(context.Background(), "user_2")
+	assert.EqualError(t, err, "connection refused")
+	assert.EqualError(t, service.SaveWithContext(context.Background(), testProfile("user_2", "var_1")), "connection refused")
+}
+
+func TestKeyValueServiceCorruptedProfile(t *testing.T) {
+	store := &memoryBatchStore{memoryStore: newMemoryStore()}
+	service := NewKeyValueService(store)
+	store.values[DefaultKeyPrefix+"user_1"] = []byte("corrupted")
+
+	// both lookups treat the profile as not saved
+	userProfile, err := service.LookupWithContext(context.Background(), "user_1")
+	require.NoError(t, err)
+	assert.Equal(t, decision.UserProfile{}, userProfile)
+	assert.Equal(t, decision.UserProfile{}, service.Lookup("user_1"))
+
+	userProfiles, err := service.LookupBatch(context.Background(), []string{"user_1"})
+	require.NoError(t, err)
+	assert.Empty(t, userProfiles)
+
+	// so that the next decision overwrites it
+	require.NoError(t, service.SaveWithContext(context.Background(), testProfile("user_1", "var_1")))
+	userProfile, err = service.LookupWithContext(context.Background(), "user_1")
+	require.NoError(t, err)
+	assert.Equal(t, testProfile("user_1", "var_1"), userProfile)
+}
+
+func TestKeyValueServiceTimeout(t *testing.T) {
+	store := newMemoryStore()
+	store.delay = time.Second
+	service := NewKeyValueService(store, WithTimeout(10*time.Millisecond))
+
+	start := time.Now()
+	_, err := service.LookupWithContext(context.Background(), "user_1")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorIs(t, service.SaveWithContext(context.Background(), testProfile("user_1", "var_1")), context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestKeyValueServiceBatch(t *testing.T) {
+	store := &memoryBatchStore{memoryStore: newMemoryStore()}
+	service := NewKeyValueService(store, WithTTL(time.Minute))
+	var _ decision.BatchUserProfileService = service
+
+	require.NoError(t, service.SaveBatch(context.Background(), []decision.UserProfile{testProfile("user_1", "var_1"), testProfile("user_2", "var_2"), {}}))
+	assert.Equal(t, 1, store.setManyCalls)
+	assert.Len(t, store.values, 2)
+	assert.Equal(t, time.Minute, store.ttls[DefaultKeyPrefix+"user_2"])
+
+	userProfiles, err := service.LookupBatch(context.Background(), []string{"user_1", "user_2", "user_3"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, store.getManyCalls)
+	assert.Equal(t, map[string]decision.UserProfile{
+		"user_1": testProfile("user_1", "var_1"),
+		"user_2": testProfile("user_2", "var_2"),
+	}, userProfiles)
+
+	// a corrupted profile is treated as not saved, the other profiles are still returned
+	store.values[DefaultKeyPrefix+"user_3"] = []byte("corrupted")
+	userProfiles, err = service.LookupBatch(context.Background(), []string{"user_1", "user_3"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]decision.UserProfile{"user_1": testProfile("user_1", "var_1")}, userProfiles)
+}
+
+func TestKeyValueServiceBatchWithoutBatchStore(t *testing.T) {
+	store := newMemoryStore()
+	service := NewKeyValueService(store)
+
+	require.NoError(t, service.SaveBatch(context.Background(), []decision.UserProfile{testProfile("user_1", "var_1"), testProfile("user_2", "var_2")}))
+	userProfiles, err := service.LookupBatch(context.Background(), []string{"user_1", "user_2", "user_3"})
+	require.NoError(t, err)
+	assert.Len(t, userProfiles, 2)
+	assert.Equal(t, testProfile("user_2", "var_2"), userProfiles["user_2"])
+
+	store.err = errors.New("connection refused")
+	_, err = service.LookupBatch(context.Background(), []string{"user_1"})
+	assert.EqualError(t, err, "connection refused")
+	assert.EqualError(t, service.SaveBatch(context.Background(), []decision.UserProfile{testProfile("user_1", "var_1")}), "connection refused")
+}
@@ -0,0 +1,72 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package userprofile //
+package userprofile
+
+import (
+	"context"
+	"time"
+
+	"github.com/optimizely/go-sdk/v2/pkg/cache"
+	"github.com/optimizely/go-sdk/v2/pkg/decision"
+)
+
+// LRUService is a UserProfileService keeping the profiles of the users seen last in memory. A profile expires after
+// the timeout since it was last saved, it never expires when the timeout is not positive.
+type LRUService struct {
+	cache *cache.LRUCache
+}
+
+// NewLRUService returns an LRUService keeping up to size profiles
+func NewLRUService(size int, timeout time.Duration) *LRUService {
+	return &LRUService{cache: cache.NewLRUCache(size, timeout)}
+}
+
+// Lookup returns the saved profile of the user, a profile with an empty ID when none was saved or it expired
+func (s *LRUService) Lookup(userID string) decision.UserProfile {
+	if userProfile, ok := s.cache.Lookup(userID).(decision.UserProfile); ok {
+		return clone(userProfile)
+	}
+	return decision.UserProfile{}
+}
+
+// Save saves a copy of the profile
+func (s *LRUService) Save(userProfile decision.UserProfile) {
+	if userProfile.ID == "" {
+		return
+	}
+	// the profile is removed first so that saving restarts its timeout
+	s.cache.Remove(userProfile.ID)
+	s.cache.Save(userProfile.ID, clone(userProfile))
+}
+
+// LookupWithContext is Lookup, it fails when the context is done
+func (s *LRUService) LookupWithContext(ctx context.Context, userID string) (decision.UserProfile, error) {
+	if err := ctx.Err(); err != nil {
+		return decision.UserProfile{}, err
+	}
+	return s.Lookup(userID), nil
+}
+
+// SaveWithContext is Save, it fails when the context is done
+func (s *LRUService) SaveWithContext(ctx context.Context, userProfile decision.UserProfile) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.Save(userProfile)
+	return nil
+}
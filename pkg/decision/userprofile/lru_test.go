@@ -0,0 +1,78 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package userprofile
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/optimizely/go-sdk/v2/pkg/decision"
+)
+
+func TestLRUService(t *testing.T) {
+	service := NewLRUService(2, 0)
+	var _ decision.ContextUserProfileService = service
+
+	userProfile := testProfile("user_1", "var_1")
+	service.Save(userProfile)
+	// the saved profile is a copy
+	userProfile.ExperimentBucketMap[decision.NewUserDecisionKey("exp_1")] = "var_2"
+	assert.Equal(t, testProfile("user_1", "var_1"), service.Lookup("user_1"))
+
+	// the lookup returns a copy
+	service.Lookup("user_1").ExperimentBucketMap[decision.NewUserDecisionKey("exp_1")] = "var_2"
+	assert.Equal(t, testProfile("user_1", "var_1"), service.Lookup("user_1"))
+
+	service.Save(testProfile("user_2", "var_1"))
+	service.Save(testProfile("user_3", "var_1"))
+	assert.Equal(t, decision.UserProfile{}, service.Lookup("user_1"))
+	assert.Equal(t, "user_3", service.Lookup("user_3").ID)
+
+	service.Save(decision.UserProfile{})
+	assert.Equal(t, decision.UserProfile{}, service.Lookup(""))
+}
+
+func TestLRUServiceTimeout(t *testing.T) {
+	service := NewLRUService(10, 50*time.Millisecond)
+	service.Save(testProfile("user_1", "var_1"))
+	time.Sleep(30 * time.Millisecond)
+	// saving again restarts the timeout
+	service.Save(testProfile("user_1", "var_2"))
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, testProfile("user_1", "var_2"), service.Lookup("user_1"))
+
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, decision.UserProfile{}, service.Lookup("user_1"))
+}
+
+func TestLRUServiceWithContext(t *testing.T) {
+	service := NewLRUService(10, 0)
+	assert.NoError(t, service.SaveWithContext(context.Background(), testProfile("user_1", "var_1")))
+	userProfile, err := service.LookupWithContext(context.Background(), "user_1")
+	assert.NoError(t, err)
+	assert.Equal(t, testProfile("user_1", "var_1"), userProfile)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = service.LookupWithContext(ctx, "user_1")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, service.SaveWithContext(ctx, testProfile("user_1", "var_2")), context.Canceled)
+	assert.Equal(t, testProfile("user_1", "var_1"), service.Lookup("user_1"))
+}
@@ -0,0 +1,138 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package event //
+package event
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrDeadLetterSinkClosed is returned when writing into a closed FileDeadLetterSink
+var ErrDeadLetterSinkClosed = errors.New("dead letter sink is closed")
+
+// DeadLetterSink receives the log events which failed permanently, e.g. rejected by the event endpoint with a 400, so
+// that they can be inspected or replayed instead of blocking the events queued after them
+type DeadLetterSink interface {
+	Write(event LogEvent, cause error) error
+}
+
+// DeadLetter is a log event which failed permanently, as written by the FileDeadLetterSink
+type DeadLetter struct {
+	Time       time.Time `json:"time"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Error      string    `json:"error"`
+	Event      LogEvent  `json:"event"`
+}
+
+// FileDeadLetterSink is a DeadLetterSink appending the log events to a local file, one JSON DeadLetter per line
+type FileDeadLetterSink struct {
+	lock sync.Mutex
+	file *os.File
+	sync bool
+}
+
+// FileDeadLetterSinkOptionConfig is the FileDeadLetterSink options that give you the ability to add one more more
+// options before the sink is opened.
+type FileDeadLetterSinkOptionConfig func(s *FileDeadLetterSink)
+
+// WithDeadLetterSyncWrites makes every write synced to the disk
+func WithDeadLetterSyncWrites(sync bool) FileDeadLetterSinkOptionConfig {
+	return func(s *FileDeadLetterSink) {
+		s.sync = sync
+	}
+}
+
+// OpenFileDeadLetterSink returns a FileDeadLetterSink appending to the file at the given path, the file and its
+// directory are created when they do not exist
+func OpenFileDeadLetterSink(path string, options ...FileDeadLetterSinkOptionConfig) (*FileDeadLetterSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(filepath.Clean(path), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	sink := &FileDeadLetterSink{file: file}
+	for _, opt := range options {
+		opt(sink)
+	}
+	return sink, nil
+}
+
+// Write appends the log event and the cause of its failure to the file
+func (s *FileDeadLetterSink) Write(event LogEvent, cause error) error {
+	deadLetter := DeadLetter{Time: time.Now().UTC(), Event: event}
+	deadLetter.StatusCode, _ = dispatchStatus(cause)
+	if cause != nil {
+		deadLetter.Error = cause.Error()
+	}
+	data, err := json.Marshal(deadLetter)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.file == nil {
+		return ErrDeadLetterSinkClosed
+	}
+	if _, err = s.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	if s.sync {
+		return s.file.Sync()
+	}
+	return nil
+}
+
+// Close closes the file, later writes fail
+func (s *FileDeadLetterSink) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// ReadDeadLetters reads the dead letters written to the file at the given path by a FileDeadLetterSink, e.g. to
+// dispatch them again once the cause of their failure is fixed
+func ReadDeadLetters(path string) ([]DeadLetter, error) {
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var deadLetters []DeadLetter
+	decoder := json.NewDecoder(file)
+	for decoder.More() {
+		var deadLetter DeadLetter
+		if err = decoder.Decode(&deadLetter); err != nil {
+			return deadLetters, err
+		}
+		deadLetters = append(deadLetters, deadLetter)
+	}
+	return deadLetters, nil
+}
@@ -0,0 +1,63 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package event
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileDeadLetterSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events", "dead-letters.jsonl")
+	sink, err := OpenFileDeadLetterSink(path, WithDeadLetterSyncWrites(true))
+	require.NoError(t, err)
+
+	rejected := createLogEvent(buildTestBatch(2), EventEndPoints["US"])
+	require.NoError(t, sink.Write(rejected, &DispatchError{StatusCode: http.StatusBadRequest}))
+	require.NoError(t, sink.Write(rejected, errors.New("invalid endpoint")))
+	require.NoError(t, sink.Close())
+	assert.ErrorIs(t, sink.Write(rejected, nil), ErrDeadLetterSinkClosed)
+
+	// the sink appends to the existing file
+	sink, err = OpenFileDeadLetterSink(path)
+	require.NoError(t, err)
+	require.NoError(t, sink.Write(rejected, nil))
+	require.NoError(t, sink.Close())
+
+	deadLetters, err := ReadDeadLetters(path)
+	require.NoError(t, err)
+	require.Len(t, deadLetters, 3)
+	assert.Equal(t, http.StatusBadRequest, deadLetters[0].StatusCode)
+	assert.Equal(t, "event dispatch failed with status 400", deadLetters[0].Error)
+	assert.Zero(t, deadLetters[1].StatusCode)
+	assert.Equal(t, "invalid endpoint", deadLetters[1].Error)
+	assert.Empty(t, deadLetters[2].Error)
+	expected, err := json.Marshal(rejected)
+	require.NoError(t, err)
+	for _, deadLetter := range deadLetters {
+		actual, marshalErr := json.Marshal(deadLetter.Event)
+		require.NoError(t, marshalErr)
+		assert.JSONEq(t, string(expected), string(actual))
+		assert.False(t, deadLetter.Time.IsZero())
+	}
+}
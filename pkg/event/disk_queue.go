@@ -0,0 +1,489 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package event //
+package event
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/optimizely/go-sdk/v2/pkg/logging"
+)
+
+const (
+	// DefaultDiskQueueMaxBytes bounds the disk space used by a DiskQueue
+	DefaultDiskQueueMaxBytes = 64 * 1024 * 1024
+	// DefaultDiskQueueSegmentBytes is the size above which a DiskQueue appends to a new segment file
+	DefaultDiskQueueSegmentBytes = 4 * 1024 * 1024
+)
+
+const (
+	diskRecordHeaderSize = 8
+	maxDiskRecordSize    = 16 * 1024 * 1024
+	diskSegmentPattern   = "segment-%020d.log"
+	diskCheckpointFile   = "checkpoint"
+)
+
+// DiskQueue is a Queue of UserEvents or LogEvents backed by a write-ahead log, so that the events not yet removed
+// survive a crash or restart and are replayed by the next DiskQueue opened on the same directory. The events are
+// appended to segment files and a checkpoint records how far they were removed, a segment is deleted once all its
+// events were removed.
+//
+// The items are also held in memory, at most maxSize of them. The events given to a BatchEventProcessor are removed
+// from its queue once handed to its dispatcher, so the dispatcher queue must be durable as well, see
+// WithDispatcherQueue.
+type DiskQueue struct {
+	dir          string
+	maxSize      int
+	maxBytes     int64
+	segmentBytes int64
+	syncWrites   bool
+	logger       logging.OptimizelyLogProducer
+
+	mux       sync.Mutex
+	items     []interface{}
+	positions []diskPosition
+	// segments are ordered from the oldest, events are appended to the last one
+	segments   []diskSegment
+	tail       *os.File
+	usedBytes  int64
+	checkpoint diskPosition
+	closed     bool
+}
+
+// diskPosition is the end of a record in a segment
+type diskPosition struct {
+	Segment uint64 `json:"segment"`
+	Offset  int64  `json:"offset"`
+}
+
+type diskSegment struct {
+	id   uint64
+	size int64
+}
+
+type diskRecord struct {
+	UserEvent *UserEvent `json:"userEvent,omitempty"`
+	LogEvent  *LogEvent  `json:"logEvent,omitempty"`
+}
+
+// DiskQueueOptionConfig is the DiskQueue options that give you the ability to add one more more options before the
+// queue is opened.
+type DiskQueueOptionConfig func(q *DiskQueue)
+
+// WithDiskQueueMaxSize sets the maximum number of events in the queue, defaults to DefaultEventQueueSize
+func WithDiskQueueMaxSize(maxSize int) DiskQueueOptionConfig {
+	return func(q *DiskQueue) {
+		q.maxSize = maxSize
+	}
+}
+
+// WithDiskQueueMaxBytes sets the disk space the queue may use, defaults to DefaultDiskQueueMaxBytes
+func WithDiskQueueMaxBytes(maxBytes int64) DiskQueueOptionConfig {
+	return func(q *DiskQueue) {
+		q.maxBytes = maxBytes
+	}
+}
+
+// WithDiskQueueSegmentBytes sets the size of the segment files, defaults to DefaultDiskQueueSegmentBytes
+func WithDiskQueueSegmentBytes(segmentBytes int64) DiskQueueOptionConfig {
+	return func(q *DiskQueue) {
+		q.segmentBytes = segmentBytes
+	}
+}
+
+// WithDiskQueueSyncWrites makes the queue sync every event to disk before Add returns, so that the events also
+// survive a power loss. Events written without sync survive the crash of the process.
+func WithDiskQueueSyncWrites(syncWrites bool) DiskQueueOptionConfig {
+	return func(q *DiskQueue) {
+		q.syncWrites = syncWrites
+	}
+}
+
+// WithDiskQueueLogger sets the logger of the queue
+func WithDiskQueueLogger(logger logging.OptimizelyLogProducer) DiskQueueOptionConfig {
+	return func(q *DiskQueue) {
+		q.logger = logger
+	}
+}
+
+// NewDiskQueue opens the queue stored in the given directory, creating it when it does not exist. The events not
+// removed before are replayed, they are the first items of the queue.
+func NewDiskQueue(dir string, options ...DiskQueueOptionConfig) (*DiskQueue, error) {
+	q := &DiskQueue{
+		dir:          dir,
+		maxSize:      DefaultEventQueueSize,
+		maxBytes:     DefaultDiskQueueMaxBytes,
+		segmentBytes: DefaultDiskQueueSegmentBytes,
+		logger:       logging.GetLogger("", "DiskQueue"),
+	}
+	for _, opt := range options {
+		opt(q)
+	}
+	if q.segmentBytes <= 0 || q.segmentBytes > q.maxBytes {
+		q.segmentBytes = q.maxBytes
+	}
+
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	nextSegment, err := q.replay()
+	if err != nil {
+		return nil, err
+	}
+	// the replayed segments are left as they are, events are appended to a new segment
+	if err = q.openSegment(nextSegment); err != nil {
+		return nil, err
+	}
+	q.dropConsumedSegments()
+	return q, nil
+}
+
+// Get returns queue for given count size
+func (q *DiskQueue) Get(count int) []interface{} {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+
+	count = q.getSafeCount(count)
+	return q.items[:count]
+}
+
+// Add appends the event to the queue, items other than UserEvent and LogEvent are discarded
+func (q *DiskQueue) Add(item interface{}) {
+	record, err := encodeDiskRecord(item)
+	if err != nil {
+		q.logger.Error("Unable to queue the event.", err)
+		return
+	}
+
+	q.mux.Lock()
+	defer q.mux.Unlock()
+
+	if q.closed {
+		q.logger.Warning("Disk queue is closed. Discarding event")
+		return
+	}
+	if len(q.items) >= q.maxSize {
+		q.logger.Warning("MaxQueueSize has been met. Discarding event")
+		return
+	}
+	if q.usedBytes+int64(len(record)) > q.maxBytes {
+		q.logger.Warning("Disk queue size limit has been met. Discarding event")
+		return
+	}
+
+	if tail := q.segments[len(q.segments)-1]; tail.size > 0 && tail.size+int64(len(record)) > q.segmentBytes {
+		if err = q.roll(); err != nil {
+			q.logger.Error("Unable to create a disk queue segment.", err)
+			return
+		}
+	}
+
+	tail := &q.segments[len(q.segments)-1]
+	n, err := q.tail.Write(record)
+	tail.size += int64(n)
+	q.usedBytes += int64(n)
+	if err == nil && q.syncWrites {
+		err = q.tail.Sync()
+	}
+	if err != nil {
+		q.logger.Error("Unable to write the event to the disk queue.", err)
+		if n > 0 {
+			// the partial record ends the segment, replay stops there
+			_ = q.roll()
+		}
+		return
+	}
+
+	q.items = append(q.items, item)
+	q.positions = append(q.positions, diskPosition{Segment: tail.id, Offset: tail.size})
+}
+
+// Remove removes item from queue and returns elements slice, the removal is recorded on disk
+func (q *DiskQueue) Remove(count int) []interface{} {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+
+	count = q.getSafeCount(count)
+	elem := q.items[:count]
+	if count == 0 {
+		return elem
+	}
+	last := q.positions[count-1]
+	q.items = q.items[count:]
+	q.positions = q.positions[count:]
+
+	if err := q.writeCheckpoint(last); err != nil {
+		// the removed events will be replayed on restart
+		q.logger.Error("Unable to write the disk queue checkpoint.", err)
+		return elem
+	}
+	if len(q.items) == 0 && !q.closed && q.segments[len(q.segments)-1].size > 0 {
+		// the segment only holds removed events, start a new one so that it can be deleted
+		if err := q.roll(); err != nil {
+			q.logger.Error("Unable to create a disk queue segment.", err)
+		}
+	}
+	q.dropConsumedSegments()
+	return elem
+}
+
+// Size returns size of queue
+func (q *DiskQueue) Size() int {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	return len(q.items)
+}
+
+// Close closes the segment file appended to, events added after are discarded
+func (q *DiskQueue) Close() error {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	if q.closed {
+		return nil
+	}
+	q.closed = true
+	return q.tail.Close()
+}
+
+func (q *DiskQueue) getSafeCount(count int) int {
+	if size := len(q.items); size < count {
+		return size
+	}
+
+	return count
+}
+
+// replay loads the events recorded after the checkpoint and returns the ID of the next segment
+func (q *DiskQueue) replay() (uint64, error) {
+	data, err := os.ReadFile(filepath.Join(q.dir, diskCheckpointFile)) // #nosec G304 - path is built from the configured directory
+	if err == nil {
+		if err = json.Unmarshal(data, &q.checkpoint); err != nil {
+			q.logger.Warning("Ignoring the invalid disk queue checkpoint: " + err.Error())
+			q.checkpoint = diskPosition{}
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return 0, err
+	}
+
+	ids, err := q.listSegments()
+	if err != nil {
+		return 0, err
+	}
+
+	dropped := 0
+	for _, id := range ids {
+		path := q.segmentPath(id)
+		if id < q.checkpoint.Segment {
+			_ = os.Remove(path)
+			continue
+		}
+		data, err := os.ReadFile(path) // #nosec G304 - path is built from the configured directory
+		if err != nil {
+			return 0, err
+		}
+		q.segments = append(q.segments, diskSegment{id: id, size: int64(len(data))})
+		q.usedBytes += int64(len(data))
+
+		offset := int64(0)
+		if id == q.checkpoint.Segment {
+			offset = q.checkpoint.Offset
+		}
+		for offset < int64(len(data)) {
+			item, next, err := decodeDiskRecord(data, offset)
+			if err != nil {
+				q.logger.Warning(fmt.Sprintf("Discarding the end of disk queue segment %d: %s", id, err))
+				break
+			}
+			offset = next
+			if len(q.items) >= q.maxSize {
+				dropped++
+				continue
+			}
+			q.items = append(q.items, item)
+			q.positions = append(q.positions, diskPosition{Segment: id, Offset: offset})
+		}
+	}
+
+	if dropped > 0 {
+		q.logger.Warning(fmt.Sprintf("MaxQueueSize has been met. Discarding %d replayed events", dropped))
+	}
+	if len(q.items) > 0 {
+		q.logger.Info(fmt.Sprintf("Replaying %d events from the disk queue", len(q.items)))
+	}
+
+	nextSegment := q.checkpoint.Segment + 1
+	if len(q.segments) > 0 && q.segments[len(q.segments)-1].id >= nextSegment {
+		nextSegment = q.segments[len(q.segments)-1].id + 1
+	}
+	return nextSegment, nil
+}
+
+func (q *DiskQueue) listSegments() ([]uint64, error) {
+	entries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return nil, err
+	}
+	var ids []uint64
+	for _, entry := range entries {
+		var id uint64
+		if _, err := fmt.Sscanf(entry.Name(), diskSegmentPattern, &id); err == nil && entry.Name() == fmt.Sprintf(diskSegmentPattern, id) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+func (q *DiskQueue) segmentPath(id uint64) string {
+	return filepath.Join(q.dir, fmt.Sprintf(diskSegmentPattern, id))
+}
+
+func (q *DiskQueue) openSegment(id uint64) error {
+	file, err := os.OpenFile(q.segmentPath(id), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	q.tail = file
+	q.segments = append(q.segments, diskSegment{id: id})
+	return nil
+}
+
+// roll closes the segment appended to and starts the next one
+func (q *DiskQueue) roll() error {
+	if err := q.tail.Close(); err != nil {
+		q.logger.Warning("Unable to close the disk queue segment: " + err.Error())
+	}
+	return q.openSegment(q.segments[len(q.segments)-1].id + 1)
+}
+
+// dropConsumedSegments deletes the segments before the one holding the first event of the queue
+func (q *DiskQueue) dropConsumedSegments() {
+	head := q.segments[len(q.segments)-1].id
+	if len(q.positions) > 0 {
+		head = q.positions[0].Segment
+	}
+	kept := q.segments[:0]
+	for _, segment := range q.segments {
+		if segment.id >= head {
+			kept = append(kept, segment)
+			continue
+		}
+		if err := os.Remove(q.segmentPath(segment.id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			q.logger.Warning("Unable to delete the disk queue segment: " + err.Error())
+			kept = append(kept, segment)
+			continue
+		}
+		q.usedBytes -= segment.size
+	}
+	q.segments = kept
+}
+
+// writeCheckpoint atomically replaces the checkpoint
+func (q *DiskQueue) writeCheckpoint(position diskPosition) error {
+	data, err := json.Marshal(position)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(q.dir, ".checkpoint-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// no-op once the temporary file has been renamed
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if _, err = tmp.Write(data); err == nil && q.syncWrites {
+		err = tmp.Sync()
+	}
+	if err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp.Name(), filepath.Join(q.dir, diskCheckpointFile)); err != nil {
+		return err
+	}
+	q.checkpoint = position
+	return nil
+}
+
+// encodeDiskRecord frames the event as its length, its CRC-32 and its JSON encoding
+func encodeDiskRecord(item interface{}) ([]byte, error) {
+	var record diskRecord
+	switch event := item.(type) {
+	case UserEvent:
+		record.UserEvent = &event
+	case LogEvent:
+		record.LogEvent = &event
+	default:
+		return nil, fmt.Errorf("disk queue does not support items of type %T", item)
+	}
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) > maxDiskRecordSize {
+		return nil, fmt.Errorf("event of %d bytes exceeds the disk queue record size limit", len(payload))
+	}
+
+	data := make([]byte, diskRecordHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(data[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(data[4:8], crc32.ChecksumIEEE(payload))
+	copy(data[diskRecordHeaderSize:], payload)
+	return data, nil
+}
+
+// decodeDiskRecord decodes the record starting at the offset and returns the offset of the next one
+func decodeDiskRecord(data []byte, offset int64) (interface{}, int64, error) {
+	if int64(len(data))-offset < diskRecordHeaderSize {
+		return nil, 0, errors.New("truncated record header")
+	}
+	length := int64(binary.BigEndian.Uint32(data[offset : offset+4]))
+	checksum := binary.BigEndian.Uint32(data[offset+4 : offset+8])
+	start := offset + diskRecordHeaderSize
+	if length > maxDiskRecordSize || start+length > int64(len(data)) {
+		return nil, 0, errors.New("truncated record")
+	}
+	payload := data[start : start+length]
+	if crc32.ChecksumIEEE(payload) != checksum {
+		return nil, 0, errors.New("record checksum mismatch")
+	}
+
+	var record diskRecord
+	if err := json.Unmarshal(payload, &record); err != nil {
+		return nil, 0, err
+	}
+	switch {
+	case record.UserEvent != nil:
+		return *record.UserEvent, start + length, nil
+	case record.LogEvent != nil:
+		return *record.LogEvent, start + length, nil
+	default:
+		return nil, 0, errors.New("empty record")
+	}
+}
@@ -0,0 +1,206 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package event
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func segmentFiles(t *testing.T, dir string) []string {
+	files, err := filepath.Glob(filepath.Join(dir, "segment-*.log"))
+	require.NoError(t, err)
+	return files
+}
+
+func TestDiskQueue_Add_Remove_Replay(t *testing.T) {
+	dir := t.TempDir()
+	q, err := NewDiskQueue(dir)
+	require.NoError(t, err)
+
+	impression := BuildTestImpressionEvent()
+	logEvent := createLogEvent(createBatchEvent(impression, createVisitorFromUserEvent(impression)), EventEndPoints["US"])
+	q.Add(impression)
+	q.Add(BuildTestConversionEvent())
+	q.Add(logEvent)
+	// unsupported items are discarded
+	q.Add(1)
+	assert.Equal(t, 3, q.Size())
+
+	assert.Equal(t, []interface{}{impression}, q.Remove(1))
+	assert.Equal(t, 2, q.Size())
+	require.NoError(t, q.Close())
+
+	q, err = NewDiskQueue(dir)
+	require.NoError(t, err)
+	defer q.Close()
+	items := q.Get(5)
+	require.Len(t, items, 2)
+	conversion, ok := items[0].(UserEvent)
+	require.True(t, ok)
+	assert.Equal(t, "sample_conversion", conversion.Conversion.Key)
+	replayedLogEvent, ok := items[1].(LogEvent)
+	require.True(t, ok)
+	assert.Equal(t, logEvent.EndPoint, replayedLogEvent.EndPoint)
+	assert.Equal(t, logEvent.Event.Visitors[0].VisitorID, replayedLogEvent.Event.Visitors[0].VisitorID)
+}
+
+func TestDiskQueue_Segments(t *testing.T) {
+	dir := t.TempDir()
+	q, err := NewDiskQueue(dir, WithDiskQueueSegmentBytes(2048))
+	require.NoError(t, err)
+	defer q.Close()
+
+	impression := BuildTestImpressionEvent()
+	for i := 0; i < 20; i++ {
+		q.Add(impression)
+	}
+	assert.Equal(t, 20, q.Size())
+	segments := len(segmentFiles(t, dir))
+	assert.Greater(t, segments, 2)
+
+	// segments are deleted once all their events were removed
+	q.Remove(10)
+	assert.Less(t, len(segmentFiles(t, dir)), segments)
+
+	q.Remove(10)
+	assert.Equal(t, 0, q.Size())
+	assert.Len(t, segmentFiles(t, dir), 1)
+
+	q.Add(impression)
+	reopened, err := NewDiskQueue(dir)
+	require.NoError(t, err)
+	defer reopened.Close()
+	assert.Equal(t, []interface{}{impression}, reopened.Get(2))
+}
+
+func TestDiskQueue_Limits(t *testing.T) {
+	impression := BuildTestImpressionEvent()
+	record, err := encodeDiskRecord(impression)
+	require.NoError(t, err)
+
+	q, err := NewDiskQueue(t.TempDir(), WithDiskQueueMaxBytes(int64(3*len(record))))
+	require.NoError(t, err)
+	defer q.Close()
+	for i := 0; i < 5; i++ {
+		q.Add(impression)
+	}
+	assert.Equal(t, 3, q.Size())
+
+	// removing the events frees disk space
+	q.Remove(3)
+	q.Add(impression)
+	assert.Equal(t, 1, q.Size())
+
+	sized, err := NewDiskQueue(t.TempDir(), WithDiskQueueMaxSize(2))
+	require.NoError(t, err)
+	defer sized.Close()
+	for i := 0; i < 5; i++ {
+		sized.Add(impression)
+	}
+	assert.Equal(t, 2, sized.Size())
+}
+
+func TestDiskQueue_Replay_Truncated_Record(t *testing.T) {
+	dir := t.TempDir()
+	q, err := NewDiskQueue(dir, WithDiskQueueSyncWrites(true))
+	require.NoError(t, err)
+	impression := BuildTestImpressionEvent()
+	q.Add(impression)
+	q.Add(impression)
+	require.NoError(t, q.Close())
+
+	// a crash in the middle of a write leaves a partial record behind
+	segments := segmentFiles(t, dir)
+	require.Len(t, segments, 1)
+	record, err := encodeDiskRecord(impression)
+	require.NoError(t, err)
+	file, err := os.OpenFile(segments[0], os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = file.Write(record[:len(record)/2])
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	q, err = NewDiskQueue(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 2, q.Size())
+	// events are appended to a new segment after the partial record
+	q.Add(impression)
+	require.NoError(t, q.Close())
+
+	q, err = NewDiskQueue(dir)
+	require.NoError(t, err)
+	defer q.Close()
+	assert.Equal(t, 3, q.Size())
+}
+
+func TestDiskQueue_Closed(t *testing.T) {
+	q, err := NewDiskQueue(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, q.Close())
+	q.Add(BuildTestImpressionEvent())
+	assert.Equal(t, 0, q.Size())
+	assert.NoError(t, q.Close())
+}
+
+func TestBatchEventProcessor_DiskQueue_Survives_Restart(t *testing.T) {
+	dir := t.TempDir()
+	q, err := NewDiskQueue(filepath.Join(dir, "processor"))
+	require.NoError(t, err)
+	dispatcherQueue, err := NewDiskQueue(filepath.Join(dir, "dispatcher"))
+	require.NoError(t, err)
+
+	processor := NewBatchEventProcessor(WithQueue(q), WithDispatcherQueue(dispatcherQueue), WithBatchSize(5))
+	dispatcher, ok := processor.EventDispatcher.(*QueueEventDispatcher)
+	require.True(t, ok)
+	dispatcher.Dispatcher = &MockDispatcher{ShouldFail: true, Events: NewInMemoryQueue(100)}
+	assert.Same(t, dispatcherQueue, dispatcher.eventQueue)
+
+	processor.ProcessEvent(BuildTestImpressionEvent())
+	processor.ProcessEvent(BuildTestImpressionEvent())
+	// the batch is handed to the dispatcher, which fails to send it
+	processor.flushEvents()
+	assert.Equal(t, 0, q.Size())
+	assert.Equal(t, 1, dispatcherQueue.Size())
+	processor.ProcessEvent(BuildTestConversionEvent())
+	require.NoError(t, q.Close())
+	require.NoError(t, dispatcherQueue.Close())
+
+	q, err = NewDiskQueue(filepath.Join(dir, "processor"))
+	require.NoError(t, err)
+	defer q.Close()
+	dispatcherQueue, err = NewDiskQueue(filepath.Join(dir, "dispatcher"))
+	require.NoError(t, err)
+	defer dispatcherQueue.Close()
+	assert.Equal(t, 1, q.Size())
+	assert.Equal(t, 1, dispatcherQueue.Size())
+
+	events := NewInMemoryQueue(100)
+	processor = NewBatchEventProcessor(WithQueue(q), WithDispatcherQueue(dispatcherQueue))
+	processor.EventDispatcher.(*QueueEventDispatcher).Dispatcher = &MockDispatcher{Events: events}
+	eg := newExecutionContext()
+	eg.Go(processor.Start)
+	eg.TerminateAndWait()
+
+	assert.Equal(t, 2, events.Size())
+	assert.Equal(t, 0, q.Size())
+	assert.Equal(t, 0, dispatcherQueue.Size())
+}
@@ -18,9 +18,14 @@
 package event
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"sync"
 	"time"
 
 	"golang.org/x/sync/semaphore"
@@ -41,36 +46,102 @@ type Dispatcher interface {
 	DispatchEvent(event LogEvent) (bool, error)
 }
 
+// DefaultCompressionThreshold holds the default payload size in bytes from which event batches are compressed
+const DefaultCompressionThreshold = 1024
+
+// ContentEncodingGzip is the Content-Encoding value of gzip compressed payloads
+const ContentEncodingGzip = "gzip"
+
+var gzipWriterPool = sync.Pool{
+	New: func() interface{} { return gzip.NewWriter(io.Discard) },
+}
+
 // httpEventDispatcher is the HTTP implementation of the Dispatcher interface
 type httpEventDispatcher struct {
 	requester *utils.HTTPRequester
 	logger    logging.OptimizelyLogProducer
+
+	compress             bool
+	compressionThreshold int
 }
 
-// DispatchEvent dispatches event with callback
+// HTTPDispatcherOptionConfig is the http event dispatcher options that give you the ability to add one more more
+// options before the dispatcher is initialized.
+type HTTPDispatcherOptionConfig func(ed *httpEventDispatcher)
+
+// WithGzipCompression compresses with gzip the event batches whose JSON payload has at least threshold bytes and sets
+// the Content-Encoding header accordingly. Payloads smaller than threshold are sent uncompressed because compressing
+// them is not worth the CPU. A threshold that is not positive compresses every payload.
+func WithGzipCompression(threshold int) HTTPDispatcherOptionConfig {
+	return func(ed *httpEventDispatcher) {
+		ed.compress = true
+		ed.compressionThreshold = threshold
+	}
+}
+
+// DispatchEvent dispatches event with callback. Unexpected response statuses are returned as a *DispatchError.
 func (ed *httpEventDispatcher) DispatchEvent(event LogEvent) (bool, error) {
 
-	_, _, code, err := ed.requester.Post(event.EndPoint, event.Event)
+	var headers http.Header
+	var code int
+	var err error
+	if ed.compress {
+		headers, code, err = ed.postCompressed(event)
+	} else {
+		_, headers, code, err = ed.requester.Post(event.EndPoint, event.Event)
+	}
 
 	// also check response codes
 	// resp.StatusCode == 400 is an error
-	var success bool
-	if err != nil {
+	if err != nil && code == 0 {
 		ed.logger.Error("http.Post failed:", err)
-		success = false
-	} else {
-		if code == http.StatusNoContent {
-			success = true
-		} else {
-			ed.logger.Error(fmt.Sprintf("http.Post invalid response %d", code), nil)
-			success = false
+		return false, err
+	}
+	if code != http.StatusNoContent {
+		ed.logger.Error(fmt.Sprintf("http.Post invalid response %d", code), err)
+		return false, &DispatchError{StatusCode: code, RetryAfter: parseRetryAfter(headers.Get("Retry-After"), time.Now()), Err: err}
+	}
+	return true, nil
+}
+
+// postCompressed posts the event batch, gzip compressed when its payload reaches the compression threshold. A payload
+// which cannot be built is a bad request like for an uncompressed post, it is not worth retrying.
+func (ed *httpEventDispatcher) postCompressed(event LogEvent) (http.Header, int, error) {
+	payload, err := json.Marshal(event.Event)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	var headers []utils.Header
+	if len(payload) >= ed.compressionThreshold {
+		compressed, gzipErr := gzipPayload(payload)
+		if gzipErr != nil {
+			return nil, http.StatusBadRequest, gzipErr
 		}
+		ed.logger.Debug(fmt.Sprintf("compressed event batch from %d to %d bytes", len(payload), len(compressed)))
+		payload = compressed
+		headers = []utils.Header{{Name: utils.HeaderContentEncoding, Value: ContentEncodingGzip}}
 	}
-	return success, err
+	_, responseHeaders, code, err := ed.requester.Do(event.EndPoint, http.MethodPost, bytes.NewReader(payload), headers)
+	return responseHeaders, code, err
+}
+
+func gzipPayload(payload []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzipWriterPool.Get().(*gzip.Writer)
+	defer gzipWriterPool.Put(writer)
+	writer.Reset(&buf)
+	if _, err := writer.Write(payload); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
 }
 
 // NewHTTPEventDispatcher creates a full http dispatcher. The requester and logger parameters can be nil.
-func NewHTTPEventDispatcher(sdkKey string, requester *utils.HTTPRequester, logger logging.OptimizelyLogProducer) Dispatcher {
+func NewHTTPEventDispatcher(sdkKey string, requester *utils.HTTPRequester, logger logging.OptimizelyLogProducer,
+	options ...HTTPDispatcherOptionConfig) Dispatcher {
 	if requester == nil {
 		requester = utils.NewHTTPRequester(logging.GetLogger(sdkKey, "HTTPRequester"))
 	}
@@ -78,7 +149,11 @@ func NewHTTPEventDispatcher(sdkKey string, requester *utils.HTTPRequester, logge
 		logger = logging.GetLogger(sdkKey, "httpEventDispatcher")
 	}
 
-	return &httpEventDispatcher{requester: requester, logger: logger}
+	dispatcher := &httpEventDispatcher{requester: requester, logger: logger}
+	for _, opt := range options {
+		opt(dispatcher)
+	}
+	return dispatcher
 }
 
 // QueueEventDispatcher is a queued version of the event Dispatcher that queues, returns success, and dispatches events in the background
@@ -86,13 +161,26 @@ type QueueEventDispatcher struct {
 	eventQueue Queue
 	processing *semaphore.Weighted
 	Dispatcher Dispatcher
-	logger     logging.OptimizelyLogProducer
+	// Workers is the number of log events sent concurrently, 1 when not positive. Only the failed events are sent
+	// again by the next flush, the ones delivered along with them are not.
+	Workers int
+	// RetryPolicy tells how failed log events are retried, DefaultRetryPolicy when nil
+	RetryPolicy *RetryPolicy
+	// DeadLetterSink receives the log events which failed permanently, they are logged and dropped when nil
+	DeadLetterSink DeadLetterSink
+	logger         logging.OptimizelyLogProducer
+
+	// retryNotBefore is set when a response asked to wait longer than the retry policy allows, flushes do nothing
+	// before it
+	retryMux       sync.Mutex
+	retryNotBefore time.Time
 
 	// metrics
 	queueSizeGauge     metrics.Gauge
 	sucessFlushCounter metrics.Counter
 	failFlushCounter   metrics.Counter
 	retryFlushCounter  metrics.Counter
+	deadLetterCounter  metrics.Counter
 }
 
 // DispatchEvent queues event with callback and calls flush in a go routine.
@@ -120,11 +208,36 @@ func (ed *QueueEventDispatcher) waitForDispatchingEventsOnClose(timeout time.Dur
 	}
 }
 
-// getRetryInterval calculates exponential backoff interval.
-// Uses bit-shift (1<<retryCount) to compute 2^retryCount for doubling: 200ms, 400ms, 800ms, ... capped at 1s.
+// getRetryInterval calculates the exponential backoff interval of the default retry policy: 200ms, 400ms, 800ms, ...
+// capped at 1s.
 func getRetryInterval(retryCount int) time.Duration {
-	interval := initialRetryInterval * time.Duration(1<<retryCount)
-	return min(interval, maxRetryInterval)
+	return DefaultRetryPolicy().Backoff(retryCount)
+}
+
+// retryPolicy returns the retry policy of the dispatcher, fixing the values which cannot be used
+func (ed *QueueEventDispatcher) retryPolicy() RetryPolicy {
+	policy := DefaultRetryPolicy()
+	if ed.RetryPolicy != nil {
+		policy = *ed.RetryPolicy
+	}
+	policy.MaxAttempts = max(policy.MaxAttempts, 1)
+	policy.MaxInterval = max(policy.MaxInterval, policy.InitialInterval)
+	return policy
+}
+
+// deferFlushes makes the flushes do nothing for the given delay
+func (ed *QueueEventDispatcher) deferFlushes(delay time.Duration) {
+	ed.retryMux.Lock()
+	defer ed.retryMux.Unlock()
+	if notBefore := time.Now().Add(delay); notBefore.After(ed.retryNotBefore) {
+		ed.retryNotBefore = notBefore
+	}
+}
+
+func (ed *QueueEventDispatcher) isFlushDeferred() bool {
+	ed.retryMux.Lock()
+	defer ed.retryMux.Unlock()
+	return time.Now().Before(ed.retryNotBefore)
 }
 
 // flush the events
@@ -135,64 +248,135 @@ func (ed *QueueEventDispatcher) flushEvents() {
 	}
 	defer ed.processing.Release(1)
 
-	retryCount := 0
+	if ed.isFlushDeferred() {
+		ed.logger.Debug("event dispatch deferred as asked by the event endpoint")
+		return
+	}
+
+	workers := max(ed.Workers, 1)
 	queueSize := ed.eventQueue.Size()
 	for ; queueSize > 0; queueSize = ed.eventQueue.Size() {
 		ed.queueSizeGauge.Set(float64(queueSize))
-		if retryCount > maxRetries {
-			ed.logger.Error(fmt.Sprintf("event failed to send %d times. It will retry on next event sent", maxRetries), nil)
-			ed.failFlushCounter.Add(1)
-			break
-		}
 
-		items := ed.eventQueue.Get(1)
+		items := ed.eventQueue.Get(workers)
 		if len(items) == 0 {
 			// something happened.  Just continue and you should expect size to be zero.
 			continue
 		}
-		event, ok := items[0].(LogEvent)
-		if !ok {
-			// remove it
-			ed.logger.Error("invalid type passed to event Dispatcher", nil)
-			ed.eventQueue.Remove(1)
+
+		results := make([]bool, len(items))
+		if len(items) == 1 {
+			results[0] = ed.dispatch(items[0])
+		} else {
+			var wg sync.WaitGroup
+			for i := range items {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					results[i] = ed.dispatch(items[i])
+				}(i)
+			}
+			wg.Wait()
+		}
+
+		// the events are removed up to the last one delivered, the failed ones before it are queued again first so that
+		// the delivered ones are not sent twice and a durable queue never loses an event
+		delivered := len(results)
+		for delivered > 0 && !results[delivered-1] {
+			delivered--
+		}
+		failed := len(results) - delivered
+		for i := 0; i < delivered; i++ {
+			if !results[i] {
+				ed.eventQueue.Add(items[i])
+				failed++
+			}
+		}
+		ed.eventQueue.Remove(delivered)
+		if failed > 0 {
+			// a deferral was logged when the event endpoint asked for it, the events did not fail MaxAttempts times
+			if !ed.isFlushDeferred() {
+				ed.logger.Error(fmt.Sprintf("event failed to send %d times. It will retry on next event sent", ed.retryPolicy().MaxAttempts), nil)
+			}
 			ed.failFlushCounter.Add(1)
-			continue
+			queueSize = ed.eventQueue.Size()
+			break
 		}
+	}
+	ed.queueSizeGauge.Set(float64(queueSize))
+}
 
+// dispatch sends the log event, retrying it as told by the retry policy, and returns whether the event is done with.
+// Items which are not log events and log events which failed permanently are done with as they can never be sent.
+func (ed *QueueEventDispatcher) dispatch(item interface{}) bool {
+	event, ok := item.(LogEvent)
+	if !ok {
+		ed.logger.Error("invalid type passed to event Dispatcher", nil)
+		ed.failFlushCounter.Add(1)
+		return true
+	}
+
+	policy := ed.retryPolicy()
+	for attempt := 1; ; attempt++ {
 		success, err := ed.Dispatcher.DispatchEvent(event)
+		if err == nil && success {
+			ed.logger.Debug("dispatch log event succeeded")
+			ed.sucessFlushCounter.Add(1)
+			return true
+		}
 
 		if err == nil {
-			if success {
-				ed.logger.Debug("dispatch log event succeeded")
-				ed.eventQueue.Remove(1)
-				retryCount = 0
-				ed.sucessFlushCounter.Add(1)
-			} else {
-				ed.logger.Warning("dispatch event failed")
-				// we failed. Use exponential backoff and try again.
-				// increase retryCount. We exit if we have retried x times.
-				// we will retry again next event that is added.
-				retryCount++
-				ed.retryFlushCounter.Add(1)
-				ed.logger.Debug(fmt.Sprintf("retrying event dispatch (attempt %d of %d) after %v", retryCount, maxRetries, getRetryInterval(retryCount-1)))
-				time.Sleep(getRetryInterval(retryCount - 1))
-			}
+			ed.logger.Warning("dispatch event failed")
 		} else {
 			ed.logger.Error("Error dispatching ", err)
-			// we failed. Use exponential backoff and try again.
-			// increase retryCount. We exit if we have retried x times.
-			// we will retry again next event that is added.
-			retryCount++
-			ed.retryFlushCounter.Add(1)
-			ed.logger.Debug(fmt.Sprintf("retrying event dispatch (attempt %d of %d) after %v", retryCount, maxRetries, getRetryInterval(retryCount-1)))
-			time.Sleep(getRetryInterval(retryCount - 1))
 		}
+		statusCode, retryAfter := dispatchStatus(err)
+		if !policy.IsRetryable(statusCode) {
+			ed.deadLetter(event, err)
+			return true
+		}
+		if attempt >= policy.MaxAttempts {
+			return false
+		}
+
+		// we failed. Use exponential backoff and try again.
+		// we give up if we have tried MaxAttempts times, we will retry again next event that is added.
+		wait := policy.Backoff(attempt - 1)
+		if policy.RespectRetryAfter && retryAfter > wait {
+			if retryAfter > policy.MaxInterval {
+				ed.logger.Warning(fmt.Sprintf("event endpoint asked to retry after %v, deferring event dispatch", retryAfter))
+				ed.deferFlushes(retryAfter)
+				return false
+			}
+			wait = retryAfter
+		}
+		ed.retryFlushCounter.Add(1)
+		ed.logger.Debug(fmt.Sprintf("retrying event dispatch (attempt %d of %d) after %v", attempt+1, policy.MaxAttempts, wait))
+		time.Sleep(wait)
+	}
+}
+
+// deadLetter hands the log event which failed permanently to the dead letter sink
+func (ed *QueueEventDispatcher) deadLetter(event LogEvent, cause error) {
+	ed.deadLetterCounter.Add(1)
+	if ed.DeadLetterSink == nil {
+		ed.logger.Error("dropping event which failed permanently, no dead letter sink is set", cause)
+		return
+	}
+	if err := ed.DeadLetterSink.Write(event, cause); err != nil {
+		ed.logger.Error("unable to write event to the dead letter sink, dropping it", err)
 	}
-	ed.queueSizeGauge.Set(float64(queueSize))
 }
 
 // NewQueueEventDispatcher creates a Dispatcher that queues in memory and then sends via go routine.
 func NewQueueEventDispatcher(sdkKey string, metricsRegistry metrics.Registry) *QueueEventDispatcher {
+	return NewQueueEventDispatcherWithQueue(sdkKey, metricsRegistry, nil)
+}
+
+// NewQueueEventDispatcherWithQueue creates a Dispatcher that queues in the given queue, e.g. a DiskQueue, and then
+// sends via go routine. An in-memory queue is used when nil. The events already in the queue are sent when the
+// BatchEventProcessor using the dispatcher starts.
+func NewQueueEventDispatcherWithQueue(sdkKey string, metricsRegistry metrics.Registry, queue Queue) *QueueEventDispatcher {
 
 	var dispatcherMetricsRegistry metrics.Registry
 	if metricsRegistry != nil {
@@ -202,13 +386,17 @@ func NewQueueEventDispatcher(sdkKey string, metricsRegistry metrics.Registry) *Q
 	}
 
 	logger := logging.GetLogger(sdkKey, "QueueEventDispatcher")
+	if queue == nil {
+		queue = NewInMemoryQueueWithLogger(defaultQueueSize, logger)
+	}
 	return &QueueEventDispatcher{
-		eventQueue:         NewInMemoryQueueWithLogger(defaultQueueSize, logger),
+		eventQueue:         queue,
 		Dispatcher:         NewHTTPEventDispatcher(sdkKey, nil, nil),
 		queueSizeGauge:     dispatcherMetricsRegistry.GetGauge(metrics.DispatcherQueueSize),
 		retryFlushCounter:  dispatcherMetricsRegistry.GetCounter(metrics.DispatcherRetryFlush),
 		failFlushCounter:   dispatcherMetricsRegistry.GetCounter(metrics.DispatcherFailedFlush),
 		sucessFlushCounter: dispatcherMetricsRegistry.GetCounter(metrics.DispatcherSuccessFlush),
+		deadLetterCounter:  dispatcherMetricsRegistry.GetCounter(metrics.DispatcherDeadLetters),
 		logger:             logger,
 		processing:         semaphore.NewWeighted(maxWorkers),
 	}
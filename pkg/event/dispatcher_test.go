@@ -18,14 +18,22 @@
 package event
 
 import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/optimizely/go-sdk/v2/pkg/entities"
 	"github.com/optimizely/go-sdk/v2/pkg/metrics"
+	"github.com/optimizely/go-sdk/v2/pkg/utils"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type MetricsRegistry struct {
@@ -235,6 +243,48 @@ func TestQueueEventDispatcher_WaitForDispatchingEventsOnClose(t *testing.T) {
 	assert.Equal(t, 0, q.eventQueue.Size())
 }
 
+func TestQueueEventDispatcher_Workers(t *testing.T) {
+	metricsRegistry := NewMetricsRegistry()
+	q := NewQueueEventDispatcher("", metricsRegistry)
+	sender := &ConcurrentDispatcher{delay: 50 * time.Millisecond}
+	q.Dispatcher = sender
+	q.Workers = 3
+
+	for i := 0; i < 4; i++ {
+		impression := buildVisitorImpression(fmt.Sprintf("visitor_%d", i))
+		q.eventQueue.Add(createLogEvent(createBatchEvent(impression, createVisitorFromUserEvent(impression)), EventEndPoints["US"]))
+	}
+	q.flushEvents()
+
+	assert.Equal(t, 0, q.eventQueue.Size())
+	assert.Equal(t, 3, sender.maxRunning)
+	assert.ElementsMatch(t, []string{"visitor_0", "visitor_1", "visitor_2", "visitor_3"}, sender.visitors)
+	assert.Equal(t, float64(4), metricsRegistry.GetCounter(metrics.DispatcherSuccessFlush).(*MetricsCounter).Get())
+	assert.Equal(t, float64(0), metricsRegistry.GetGauge(metrics.DispatcherQueueSize).(*MetricsGauge).Get())
+}
+
+func TestQueueEventDispatcher_WorkersFailure(t *testing.T) {
+	q := NewQueueEventDispatcher("", NewMetricsRegistry())
+	sender := &ConcurrentDispatcher{delay: 10 * time.Millisecond, failVisitor: "visitor_0"}
+	q.Dispatcher = sender
+	q.Workers = 3
+	q.RetryPolicy = &RetryPolicy{MaxAttempts: 1}
+
+	for i := 0; i < 3; i++ {
+		impression := buildVisitorImpression(fmt.Sprintf("visitor_%d", i))
+		q.eventQueue.Add(createLogEvent(createBatchEvent(impression, createVisitorFromUserEvent(impression)), EventEndPoints["US"]))
+	}
+	q.flushEvents()
+	// only the failed event is kept, the events delivered along with it are not sent again
+	assert.Equal(t, 1, q.eventQueue.Size())
+	assert.ElementsMatch(t, []string{"visitor_1", "visitor_2"}, sender.visitors)
+
+	sender.failVisitor = ""
+	q.flushEvents()
+	assert.Equal(t, 0, q.eventQueue.Size())
+	assert.ElementsMatch(t, []string{"visitor_0", "visitor_1", "visitor_2"}, sender.visitors)
+}
+
 func TestGetRetryInterval(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -291,3 +341,329 @@ func TestQueueEventDispatcher_ExponentialBackoff(t *testing.T) {
 	// Allow some tolerance for test execution overhead
 	assert.True(t, elapsed >= 500*time.Millisecond, "Expected at least 500ms elapsed for exponential backoff, got %v", elapsed)
 }
+
+// buildTestBatch returns a batch of impressions and conversions of the given number of visitors
+func buildTestBatch(visitors int) Batch {
+	impression := BuildTestImpressionEvent()
+	batch := createBatchEvent(impression, createVisitorFromUserEvent(impression))
+	for i := 1; i < visitors; i++ {
+		userEvent := BuildTestImpressionEvent()
+		if i%2 == 1 {
+			userEvent = BuildTestConversionEvent()
+		}
+		userEvent.VisitorID = fmt.Sprintf("visitor_%d", i)
+		batch.Visitors = append(batch.Visitors, createVisitorFromUserEvent(userEvent))
+	}
+	return batch
+}
+
+// recordingServer records the Content-Encoding header and the decoded body of the last request
+type recordingServer struct {
+	*httptest.Server
+	lock            sync.Mutex
+	contentEncoding string
+	body            []byte
+}
+
+func newRecordingServer(t *testing.T) *recordingServer {
+	server := &recordingServer{}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reader io.Reader = r.Body
+		if r.Header.Get(utils.HeaderContentEncoding) == ContentEncodingGzip {
+			gzipReader, err := gzip.NewReader(r.Body)
+			assert.NoError(t, err)
+			reader = gzipReader
+		}
+		body, err := io.ReadAll(reader)
+		assert.NoError(t, err)
+
+		server.lock.Lock()
+		defer server.lock.Unlock()
+		server.contentEncoding = r.Header.Get(utils.HeaderContentEncoding)
+		server.body = body
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (s *recordingServer) lastRequest() (contentEncoding string, body []byte) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.contentEncoding, s.body
+}
+
+func TestHTTPEventDispatcher_GzipCompression(t *testing.T) {
+	server := newRecordingServer(t)
+	batch := buildTestBatch(10)
+	payload, err := json.Marshal(batch)
+	require.NoError(t, err)
+
+	dispatcher := NewHTTPEventDispatcher("", nil, nil, WithGzipCompression(len(payload)))
+	success, err := dispatcher.DispatchEvent(LogEvent{EndPoint: server.URL, Event: batch})
+	assert.NoError(t, err)
+	assert.True(t, success)
+	contentEncoding, body := server.lastRequest()
+	assert.Equal(t, ContentEncodingGzip, contentEncoding)
+	assert.JSONEq(t, string(payload), string(body))
+
+	// payloads below the threshold are sent uncompressed
+	dispatcher = NewHTTPEventDispatcher("", nil, nil, WithGzipCompression(len(payload)+1))
+	success, err = dispatcher.DispatchEvent(LogEvent{EndPoint: server.URL, Event: batch})
+	assert.NoError(t, err)
+	assert.True(t, success)
+	contentEncoding, body = server.lastRequest()
+	assert.Empty(t, contentEncoding)
+	assert.JSONEq(t, string(payload), string(body))
+
+	dispatcher = NewHTTPEventDispatcher("", nil, nil)
+	success, err = dispatcher.DispatchEvent(LogEvent{EndPoint: server.URL, Event: batch})
+	assert.NoError(t, err)
+	assert.True(t, success)
+	contentEncoding, body = server.lastRequest()
+	assert.Empty(t, contentEncoding)
+	assert.JSONEq(t, string(payload), string(body))
+
+	// a batch which cannot be encoded is a bad request, not worth retrying
+	batch.Visitors[0].Attributes = append(batch.Visitors[0].Attributes, VisitorAttribute{Key: "unsupported", Value: make(chan int)})
+	dispatcher = NewHTTPEventDispatcher("", nil, nil, WithGzipCompression(0))
+	success, err = dispatcher.DispatchEvent(LogEvent{EndPoint: server.URL, Event: batch})
+	assert.False(t, success)
+	statusCode, _ := dispatchStatus(err)
+	assert.Equal(t, http.StatusBadRequest, statusCode)
+	assert.False(t, DefaultRetryPolicy().IsRetryable(statusCode))
+}
+
+func TestBatchEventProcessor_HTTPDispatcherOptions(t *testing.T) {
+	processor := NewBatchEventProcessor(WithHTTPDispatcherOptions(WithGzipCompression(DefaultCompressionThreshold)))
+	dispatcher, ok := processor.EventDispatcher.(*QueueEventDispatcher).Dispatcher.(*httpEventDispatcher)
+	require.True(t, ok)
+	assert.True(t, dispatcher.compress)
+	assert.Equal(t, DefaultCompressionThreshold, dispatcher.compressionThreshold)
+
+	processor = NewBatchEventProcessor()
+	dispatcher, ok = processor.EventDispatcher.(*QueueEventDispatcher).Dispatcher.(*httpEventDispatcher)
+	require.True(t, ok)
+	assert.False(t, dispatcher.compress)
+}
+
+// BenchmarkGzipPayload reports the size of typical batch payloads before and after compression
+func BenchmarkGzipPayload(b *testing.B) {
+	for _, visitors := range []int{1, 10, 100} {
+		payload, err := json.Marshal(buildTestBatch(visitors))
+		require.NoError(b, err)
+		b.Run(fmt.Sprintf("visitors=%d", visitors), func(b *testing.B) {
+			var compressed []byte
+			for i := 0; i < b.N; i++ {
+				if compressed, err = gzipPayload(payload); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(len(payload)), "json-bytes")
+			b.ReportMetric(float64(len(compressed)), "gzip-bytes")
+			b.ReportMetric(float64(len(compressed))/float64(len(payload)), "ratio")
+		})
+	}
+}
+
+// StatusDispatcher fails the log events of the visitors mapped to an error and records every attempt
+type StatusDispatcher struct {
+	errors   map[string]error
+	lock     sync.Mutex
+	attempts []string
+}
+
+func (s *StatusDispatcher) DispatchEvent(event LogEvent) (bool, error) {
+	visitorID := event.Event.Visitors[0].VisitorID
+	s.lock.Lock()
+	s.attempts = append(s.attempts, visitorID)
+	s.lock.Unlock()
+	if err, ok := s.errors[visitorID]; ok {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *StatusDispatcher) getAttempts() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]string{}, s.attempts...)
+}
+
+// MemoryDeadLetterSink keeps the dead letters in memory
+type MemoryDeadLetterSink struct {
+	lock        sync.Mutex
+	deadLetters []DeadLetter
+}
+
+func (m *MemoryDeadLetterSink) Write(event LogEvent, cause error) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.deadLetters = append(m.deadLetters, DeadLetter{Event: event, Error: cause.Error()})
+	return nil
+}
+
+func addVisitorLogEvents(q *QueueEventDispatcher, visitorIDs ...string) {
+	for _, visitorID := range visitorIDs {
+		impression := buildVisitorImpression(visitorID)
+		q.eventQueue.Add(createLogEvent(createBatchEvent(impression, createVisitorFromUserEvent(impression)), EventEndPoints["US"]))
+	}
+}
+
+func TestHTTPEventDispatcher_DispatchError(t *testing.T) {
+	statusCode := http.StatusTooManyRequests
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "2")
+		w.WriteHeader(statusCode)
+	}))
+	defer server.Close()
+
+	dispatcher := NewHTTPEventDispatcher("", nil, nil)
+	success, err := dispatcher.DispatchEvent(LogEvent{EndPoint: server.URL, Event: buildTestBatch(1)})
+	assert.False(t, success)
+	var dispatchErr *DispatchError
+	require.ErrorAs(t, err, &dispatchErr)
+	assert.Equal(t, http.StatusTooManyRequests, dispatchErr.StatusCode)
+	assert.Equal(t, 2*time.Second, dispatchErr.RetryAfter)
+
+	// a success status other than 204 is an unexpected response too
+	statusCode = http.StatusOK
+	success, err = dispatcher.DispatchEvent(LogEvent{EndPoint: server.URL, Event: buildTestBatch(1)})
+	assert.False(t, success)
+	require.ErrorAs(t, err, &dispatchErr)
+	assert.Equal(t, http.StatusOK, dispatchErr.StatusCode)
+	assert.NoError(t, dispatchErr.Err)
+
+	statusCode = http.StatusNoContent
+	success, err = dispatcher.DispatchEvent(LogEvent{EndPoint: server.URL, Event: buildTestBatch(1)})
+	assert.True(t, success)
+	assert.NoError(t, err)
+}
+
+func TestQueueEventDispatcher_DeadLetterSink(t *testing.T) {
+	metricsRegistry := NewMetricsRegistry()
+	q := NewQueueEventDispatcher("", metricsRegistry)
+	sender := &StatusDispatcher{errors: map[string]error{"poison": &DispatchError{StatusCode: http.StatusBadRequest}}}
+	sink := &MemoryDeadLetterSink{}
+	q.Dispatcher = sender
+	q.DeadLetterSink = sink
+
+	addVisitorLogEvents(q, "visitor_0", "poison", "visitor_1")
+	q.flushEvents()
+
+	// the rejected event is not retried and does not block the events after it
+	assert.Equal(t, 0, q.eventQueue.Size())
+	assert.Equal(t, []string{"visitor_0", "poison", "visitor_1"}, sender.getAttempts())
+	require.Len(t, sink.deadLetters, 1)
+	assert.Equal(t, "poison", sink.deadLetters[0].Event.Event.Visitors[0].VisitorID)
+	assert.Equal(t, "event dispatch failed with status 400", sink.deadLetters[0].Error)
+	assert.Equal(t, float64(1), metricsRegistry.GetCounter(metrics.DispatcherDeadLetters).(*MetricsCounter).Get())
+	assert.Equal(t, float64(2), metricsRegistry.GetCounter(metrics.DispatcherSuccessFlush).(*MetricsCounter).Get())
+	assert.Equal(t, float64(0), metricsRegistry.GetCounter(metrics.DispatcherRetryFlush).(*MetricsCounter).Get())
+
+	// without a sink the rejected events are dropped
+	q.DeadLetterSink = nil
+	addVisitorLogEvents(q, "poison")
+	q.flushEvents()
+	assert.Equal(t, 0, q.eventQueue.Size())
+	assert.Equal(t, float64(2), metricsRegistry.GetCounter(metrics.DispatcherDeadLetters).(*MetricsCounter).Get())
+}
+
+func TestQueueEventDispatcher_RetryPolicy(t *testing.T) {
+	metricsRegistry := NewMetricsRegistry()
+	q := NewQueueEventDispatcher("", metricsRegistry)
+	sender := &StatusDispatcher{errors: map[string]error{
+		"unavailable": &DispatchError{StatusCode: http.StatusServiceUnavailable},
+		"not_found":   &DispatchError{StatusCode: http.StatusNotFound},
+	}}
+	q.Dispatcher = sender
+	q.RetryPolicy = &RetryPolicy{MaxAttempts: 2, InitialInterval: time.Millisecond, RetryableStatusCodes: []int{http.StatusNotFound}}
+
+	// 404 is retryable for this policy, 503 is not
+	addVisitorLogEvents(q, "unavailable", "not_found", "visitor_0")
+	q.flushEvents()
+	assert.Equal(t, []string{"unavailable", "not_found", "not_found"}, sender.getAttempts())
+	assert.Equal(t, 2, q.eventQueue.Size())
+	assert.Equal(t, float64(1), metricsRegistry.GetCounter(metrics.DispatcherDeadLetters).(*MetricsCounter).Get())
+	assert.Equal(t, float64(1), metricsRegistry.GetCounter(metrics.DispatcherRetryFlush).(*MetricsCounter).Get())
+	assert.Equal(t, float64(1), metricsRegistry.GetCounter(metrics.DispatcherFailedFlush).(*MetricsCounter).Get())
+}
+
+func TestQueueEventDispatcher_RetryAfter(t *testing.T) {
+	metricsRegistry := NewMetricsRegistry()
+	q := NewQueueEventDispatcher("", metricsRegistry)
+	sender := &StatusDispatcher{errors: map[string]error{
+		"throttled": &DispatchError{StatusCode: http.StatusTooManyRequests, RetryAfter: 50 * time.Millisecond},
+	}}
+	q.Dispatcher = sender
+	q.RetryPolicy = &RetryPolicy{MaxAttempts: 2, InitialInterval: time.Millisecond, MaxInterval: 100 * time.Millisecond,
+		RetryableStatusCodes: DefaultRetryableStatusCodes, RespectRetryAfter: true}
+
+	// a Retry-After delay within the policy replaces the backoff
+	addVisitorLogEvents(q, "throttled")
+	start := time.Now()
+	q.flushEvents()
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	assert.Equal(t, []string{"throttled", "throttled"}, sender.getAttempts())
+
+	// a longer delay defers the flushes, which is not reported as a failure to send
+	sender.errors["throttled"] = &DispatchError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Hour}
+	logger := &recordingLogger{}
+	q.logger = logger
+	start = time.Now()
+	q.flushEvents()
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+	assert.Len(t, sender.getAttempts(), 3)
+	assert.True(t, q.isFlushDeferred())
+	assert.Contains(t, logger.warnings, "event endpoint asked to retry after 1h0m0s, deferring event dispatch")
+	for _, message := range logger.errors {
+		assert.NotContains(t, message, "event failed to send")
+	}
+
+	delete(sender.errors, "throttled")
+	q.flushEvents()
+	assert.Len(t, sender.getAttempts(), 3)
+	assert.Equal(t, 1, q.eventQueue.Size())
+
+	q.retryNotBefore = time.Time{}
+	q.flushEvents()
+	assert.Len(t, sender.getAttempts(), 4)
+	assert.Equal(t, 0, q.eventQueue.Size())
+}
+
+func TestBatchEventProcessor_RetryPolicyAndDeadLetterSink(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 1}
+	sink := &MemoryDeadLetterSink{}
+	processor := NewBatchEventProcessor(WithRetryPolicy(policy), WithDeadLetterSink(sink))
+	dispatcher := processor.EventDispatcher.(*QueueEventDispatcher)
+	assert.Equal(t, &policy, dispatcher.RetryPolicy)
+	assert.Equal(t, sink, dispatcher.DeadLetterSink)
+	assert.Equal(t, 1, dispatcher.retryPolicy().MaxAttempts)
+
+	dispatcher = NewBatchEventProcessor().EventDispatcher.(*QueueEventDispatcher)
+	assert.Nil(t, dispatcher.RetryPolicy)
+	assert.Equal(t, DefaultRetryPolicy(), dispatcher.retryPolicy())
+}
+
+// recordingLogger records the warnings and errors logged
+type recordingLogger struct {
+	lock     sync.Mutex
+	warnings []string
+	errors   []string
+}
+
+func (l *recordingLogger) Debug(message string) {}
+
+func (l *recordingLogger) Info(message string) {}
+
+func (l *recordingLogger) Warning(message string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.warnings = append(l.warnings, message)
+}
+
+func (l *recordingLogger) Error(message string, err interface{}) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.errors = append(l.errors, message)
+}
@@ -19,13 +19,16 @@ package event
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
 	"golang.org/x/sync/semaphore"
 
+	"github.com/optimizely/go-sdk/v2/pkg/cache"
 	"github.com/optimizely/go-sdk/v2/pkg/logging"
 	"github.com/optimizely/go-sdk/v2/pkg/metrics"
 	"github.com/optimizely/go-sdk/v2/pkg/notification"
@@ -51,11 +54,52 @@ type BatchEventProcessor struct {
 	flushLock       sync.Mutex
 	Ticker          *time.Ticker
 	EventDispatcher Dispatcher
+	dispatcherQueue Queue
+	httpOptions     []HTTPDispatcherOptionConfig
+	retryPolicy     *RetryPolicy
+	deadLetterSink  DeadLetterSink
 	processing      *semaphore.Weighted
 	logger          logging.OptimizelyLogProducer
 	metricsRegistry metrics.Registry
+
+	flushWorkers   int
+	overflowPolicy OverflowPolicy
+	blockTimeout   time.Duration
+	// queueMux serializes the removals of a flush with the ones of the DropOldest policy, droppedInFlush counts the
+	// events dropped since the flush got its events, they are the oldest events of the flush
+	queueMux       sync.Mutex
+	droppedInFlush int
+	// roomFreed is closed and replaced when a flush removes events from the queue
+	roomMux   sync.Mutex
+	roomFreed chan struct{}
+
+	// maxBatchBytes bounds the JSON payload of a batch, unbounded when not positive
+	maxBatchBytes  int
+	dedupWindow    time.Duration
+	dedupCacheSize int
+	// dedupCache holds the impressions processed within the deduplication window, nil when deduplication is off
+	dedupMux   sync.Mutex
+	dedupCache *cache.LRUCache
+
+	// metrics
+	queueSizeGauge            metrics.Gauge
+	droppedEventsCounter      metrics.Counter
+	blockedEventsCounter      metrics.Counter
+	deduplicatedEventsCounter metrics.Counter
 }
 
+// OverflowPolicy tells what ProcessEvent does when the queue is full
+type OverflowPolicy string
+
+const (
+	// DropNewest discards the event processed, it is the default policy
+	DropNewest OverflowPolicy = "drop_newest"
+	// DropOldest discards the oldest event of the queue to make room for the event processed
+	DropOldest OverflowPolicy = "drop_oldest"
+	// Block waits for a flush to make room, up to the block timeout after which the event processed is discarded
+	Block OverflowPolicy = "block"
+)
+
 // DefaultBatchSize holds the default value for the batch size
 const DefaultBatchSize = 10
 
@@ -77,7 +121,15 @@ var EventEndPoints = map[string]string{
 	"EU": "https://eu.logx.optimizely.com/v1/events",
 }
 
-const maxFlushWorkers = 1
+// DefaultFlushWorkers holds the default value for the number of batches dispatched concurrently
+const DefaultFlushWorkers = 1
+
+// DefaultOverflowBlockTimeout holds the default value for the time the Block overflow policy waits for room
+const DefaultOverflowBlockTimeout = 1 * time.Second
+
+// DefaultDeduplicationCacheSize holds the default value for the number of impressions remembered by the deduplication
+// window
+const DefaultDeduplicationCacheSize = 10000
 
 // BPOptionConfig is the BatchProcessor options that give you the ability to add one more more options before the processor is initialized.
 type BPOptionConfig func(qp *BatchEventProcessor)
@@ -117,6 +169,81 @@ func WithQueue(q Queue) BPOptionConfig {
 	}
 }
 
+// WithFlushWorkers sets the number of batches dispatched concurrently by a flush, it also sets the number of log
+// events sent concurrently by the QueueEventDispatcher created when no dispatcher is given. Only the failed batches
+// are sent again by the next flush, the ones delivered along with them are not.
+func WithFlushWorkers(workers int) BPOptionConfig {
+	return func(qp *BatchEventProcessor) {
+		qp.flushWorkers = workers
+	}
+}
+
+// WithOverflowPolicy sets what ProcessEvent does when the queue is full, defaults to DropNewest
+func WithOverflowPolicy(policy OverflowPolicy) BPOptionConfig {
+	return func(qp *BatchEventProcessor) {
+		qp.overflowPolicy = policy
+	}
+}
+
+// WithOverflowBlockTimeout sets how long the Block overflow policy waits for room, defaults to
+// DefaultOverflowBlockTimeout
+func WithOverflowBlockTimeout(timeout time.Duration) BPOptionConfig {
+	return func(qp *BatchEventProcessor) {
+		qp.blockTimeout = timeout
+	}
+}
+
+// WithMaxBatchBytes bounds the size in bytes of the JSON payload of a batch, before any compression. Batches are
+// closed before reaching BatchSize visitors when the next visitor would exceed it, a single visitor exceeding it is
+// sent alone.
+func WithMaxBatchBytes(maxBytes int) BPOptionConfig {
+	return func(qp *BatchEventProcessor) {
+		qp.maxBatchBytes = maxBytes
+	}
+}
+
+// WithDeduplicationWindow discards the impressions of a user for a flag, rule and variation already processed within
+// the window, e.g. when Decide is called several times per request, CMAB impressions are never discarded. Up to
+// maxImpressions impressions are remembered, DefaultDeduplicationCacheSize when not positive, the least recent ones
+// are forgotten first.
+func WithDeduplicationWindow(window time.Duration, maxImpressions int) BPOptionConfig {
+	return func(qp *BatchEventProcessor) {
+		qp.dedupWindow = window
+		qp.dedupCacheSize = maxImpressions
+	}
+}
+
+// WithDispatcherQueue sets the queue of the QueueEventDispatcher created when no dispatcher is given, e.g. a DiskQueue
+// so that the batches handed to the dispatcher also survive a restart
+func WithDispatcherQueue(q Queue) BPOptionConfig {
+	return func(qp *BatchEventProcessor) {
+		qp.dispatcherQueue = q
+	}
+}
+
+// WithHTTPDispatcherOptions sets the options of the http dispatcher of the QueueEventDispatcher created when no
+// dispatcher is given, e.g. WithGzipCompression
+func WithHTTPDispatcherOptions(options ...HTTPDispatcherOptionConfig) BPOptionConfig {
+	return func(qp *BatchEventProcessor) {
+		qp.httpOptions = append(qp.httpOptions, options...)
+	}
+}
+
+// WithRetryPolicy sets the retry policy of the QueueEventDispatcher created when no dispatcher is given
+func WithRetryPolicy(policy RetryPolicy) BPOptionConfig {
+	return func(qp *BatchEventProcessor) {
+		qp.retryPolicy = &policy
+	}
+}
+
+// WithDeadLetterSink sets the sink receiving the log events which failed permanently in the QueueEventDispatcher
+// created when no dispatcher is given, e.g. a FileDeadLetterSink
+func WithDeadLetterSink(sink DeadLetterSink) BPOptionConfig {
+	return func(qp *BatchEventProcessor) {
+		qp.deadLetterSink = sink
+	}
+}
+
 // WithEventDispatcher sets the Processor Dispatcher as a config option to be passed into the NewProcessor method
 func WithEventDispatcher(d Dispatcher) BPOptionConfig {
 	return func(qp *BatchEventProcessor) {
@@ -141,7 +268,8 @@ func WithEventDispatcherMetrics(metricsRegistry metrics.Registry) BPOptionConfig
 
 // NewBatchEventProcessor returns a new instance of BatchEventProcessor with queueSize and flushInterval
 func NewBatchEventProcessor(options ...BPOptionConfig) *BatchEventProcessor {
-	p := &BatchEventProcessor{processing: semaphore.NewWeighted(int64(maxFlushWorkers))}
+	// a single flush runs at a time, it dispatches its batches with the flush workers
+	p := &BatchEventProcessor{processing: semaphore.NewWeighted(1)}
 
 	for _, opt := range options {
 		opt(p)
@@ -170,15 +298,54 @@ func NewBatchEventProcessor(options ...BPOptionConfig) *BatchEventProcessor {
 		p.MaxQueueSize = defaultQueueSize
 	}
 
+	if p.flushWorkers <= 0 {
+		p.flushWorkers = DefaultFlushWorkers
+	}
+
+	switch p.overflowPolicy {
+	case DropNewest, DropOldest, Block:
+	case "":
+		p.overflowPolicy = DropNewest
+	default:
+		p.logger.Warning(fmt.Sprintf("Unknown overflow policy %q.  Setting to default", p.overflowPolicy))
+		p.overflowPolicy = DropNewest
+	}
+
+	if p.blockTimeout <= 0 {
+		p.blockTimeout = DefaultOverflowBlockTimeout
+	}
+
 	if p.Q == nil {
 		p.Q = NewInMemoryQueueWithLogger(p.MaxQueueSize, p.logger)
 	}
 
+	if p.dedupWindow > 0 {
+		if p.dedupCacheSize <= 0 {
+			p.dedupCacheSize = DefaultDeduplicationCacheSize
+		}
+		p.dedupCache = cache.NewLRUCache(p.dedupCacheSize, p.dedupWindow)
+	}
+
 	if p.EventDispatcher == nil {
-		dispatcher := NewQueueEventDispatcher(p.sdkKey, p.metricsRegistry)
+		dispatcher := NewQueueEventDispatcherWithQueue(p.sdkKey, p.metricsRegistry, p.dispatcherQueue)
+		dispatcher.Workers = p.flushWorkers
+		dispatcher.RetryPolicy = p.retryPolicy
+		dispatcher.DeadLetterSink = p.deadLetterSink
+		if len(p.httpOptions) > 0 {
+			dispatcher.Dispatcher = NewHTTPEventDispatcher(p.sdkKey, nil, nil, p.httpOptions...)
+		}
 		p.EventDispatcher = dispatcher
 	}
 
+	processorMetricsRegistry := p.metricsRegistry
+	if processorMetricsRegistry == nil {
+		processorMetricsRegistry = metrics.NewNoopRegistry()
+	}
+	p.queueSizeGauge = processorMetricsRegistry.GetGauge(metrics.ProcessorQueueSize)
+	p.droppedEventsCounter = processorMetricsRegistry.GetCounter(metrics.ProcessorDroppedEvents)
+	p.blockedEventsCounter = processorMetricsRegistry.GetCounter(metrics.ProcessorBlockedEvents)
+	p.deduplicatedEventsCounter = processorMetricsRegistry.GetCounter(metrics.ProcessorDeduplicatedEvents)
+
 	return p
 }
 
@@ -186,36 +353,141 @@ func NewBatchEventProcessor(options ...BPOptionConfig) *BatchEventProcessor {
 func (p *BatchEventProcessor) Start(ctx context.Context) {
 
 	p.logger.Info("Batch event processor started")
+	if d, ok := p.EventDispatcher.(*QueueEventDispatcher); ok && d.eventQueue.Size() > 0 {
+		// send the events replayed by a durable dispatcher queue
+		go d.flushEvents()
+	}
 	p.startTicker(ctx)
 }
 
 // ProcessEvent takes the given user event (can be an impression or conversion event) and queues it up to be dispatched
 // to the Optimizely log endpoint. A dispatch happens when we flush the events, which can happen on a set interval or
 // when the specified batch size (defaulted to 10) is reached.
+// When the queue is full the event is handled according to the overflow policy.
 func (p *BatchEventProcessor) ProcessEvent(event UserEvent) bool {
 
-	if p.Q.Size() >= p.MaxQueueSize {
+	dedupKey, duplicate := p.deduplicate(event)
+	if duplicate {
+		p.logger.Debug("impression already processed within the deduplication window. Discarding event")
+		p.deduplicatedEventsCounter.Add(1)
+		return true
+	}
+
+	if p.Q.Size() >= p.MaxQueueSize && !p.makeRoom() {
 		p.logger.Warning("MaxQueueSize has been met. Discarding event")
+		p.droppedEventsCounter.Add(1)
+		if dedupKey != "" {
+			// the impression was not sent, a later one must not be discarded
+			p.dedupCache.Remove(dedupKey)
+		}
 		return false
 	}
 
 	p.Q.Add(event)
+	queueSize := p.Q.Size()
+	p.queueSizeGauge.Set(float64(queueSize))
 
-	if p.Q.Size() < p.BatchSize {
+	if queueSize < p.BatchSize {
 		return true
 	}
 
+	p.logger.Debug("batch size reached.  Flushing routine being called")
+	p.startFlush()
+
+	return true
+}
+
+// deduplicate returns whether the event is an impression already processed within the deduplication window, the
+// impression is remembered otherwise and its key returned
+func (p *BatchEventProcessor) deduplicate(event UserEvent) (key string, duplicate bool) {
+	if p.dedupCache == nil || event.Impression == nil {
+		return "", false
+	}
+	// flag decisions without a variation share empty experiment and variation IDs, and every CMAB decision is
+	// an impression of its own
+	metadata := event.Impression.Metadata
+	fields := []string{event.VisitorID, metadata.FlagKey, metadata.RuleType, event.Impression.ExperimentID, event.Impression.VariationID}
+	if metadata.CmabUUID != nil {
+		fields = append(fields, *metadata.CmabUUID)
+	}
+	key = strings.Join(fields, "|")
+
+	p.dedupMux.Lock()
+	defer p.dedupMux.Unlock()
+	if p.dedupCache.Lookup(key) != nil {
+		return "", true
+	}
+	p.dedupCache.Save(key, true)
+	return key, false
+}
+
+// startFlush flushes the events in a go routine unless a flush is already running
+func (p *BatchEventProcessor) startFlush() {
 	if p.processing.TryAcquire(1) {
 		// it doesn't matter if the timer has kicked in here.
 		// we just want to start one go routine when the batch size is met.
-		p.logger.Debug("batch size reached.  Flushing routine being called")
 		go func() {
 			p.flushEvents()
 			p.processing.Release(1)
 		}()
 	}
+}
 
-	return true
+// makeRoom applies the overflow policy to the full queue and returns whether the event can be queued
+func (p *BatchEventProcessor) makeRoom() bool {
+	switch p.overflowPolicy {
+	case DropOldest:
+		p.queueMux.Lock()
+		dropped := len(p.remove(1))
+		p.droppedInFlush += dropped
+		p.queueMux.Unlock()
+		if dropped > 0 {
+			p.logger.Warning("MaxQueueSize has been met. Discarding oldest event")
+			p.droppedEventsCounter.Add(1)
+		}
+		return true
+	case Block:
+		p.blockedEventsCounter.Add(1)
+		return p.waitForRoom()
+	default:
+		return false
+	}
+}
+
+// waitForRoom flushes the events and waits for the queue to have room, up to the block timeout
+func (p *BatchEventProcessor) waitForRoom() bool {
+	timer := time.NewTimer(p.blockTimeout)
+	defer timer.Stop()
+
+	for {
+		p.roomMux.Lock()
+		if p.roomFreed == nil {
+			p.roomFreed = make(chan struct{})
+		}
+		roomFreed := p.roomFreed
+		p.roomMux.Unlock()
+
+		if p.Q.Size() < p.MaxQueueSize {
+			return true
+		}
+		p.startFlush()
+
+		select {
+		case <-roomFreed:
+		case <-timer.C:
+			return p.Q.Size() < p.MaxQueueSize
+		}
+	}
+}
+
+// signalRoom wakes up the events waiting for room
+func (p *BatchEventProcessor) signalRoom() {
+	p.roomMux.Lock()
+	defer p.roomMux.Unlock()
+	if p.roomFreed != nil {
+		close(p.roomFreed)
+		p.roomFreed = nil
+	}
 }
 
 // eventsCount returns size of an event queue
@@ -273,69 +545,192 @@ func (p *BatchEventProcessor) addToBatch(current *Batch, visitor Visitor) {
 	current.Visitors = append(current.Visitors, visitor)
 }
 
-// flushEvents flushes events in queue
+// visitorBytes returns the size of the JSON payload of the visitor, 0 when the batch size in bytes is not bounded
+func (p *BatchEventProcessor) visitorBytes(visitor Visitor) int {
+	if p.maxBatchBytes <= 0 {
+		return 0
+	}
+	data, err := json.Marshal(visitor)
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
+
+// batchBytes returns the size of the JSON payload of the batch, 0 when the batch size in bytes is not bounded
+func (p *BatchEventProcessor) batchBytes(batch Batch) int {
+	if p.maxBatchBytes <= 0 {
+		return 0
+	}
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
+
+// pendingBatch is a batch of events of the queue, count is the number of queue items it was built from
+type pendingBatch struct {
+	batch    Batch
+	visitors int
+	count    int
+	// bytes is the size of the JSON payload of the batch, only computed when the batch size in bytes is bounded
+	bytes int
+}
+
+// flushEvents flushes events in queue. Up to a batch per flush worker is dispatched at a time, the events of the
+// delivered batches are removed from the queue and the events of the failed ones are sent again by the next flush.
 func (p *BatchEventProcessor) flushEvents() {
 	// we flush when queue size is reached.
 	// however, if there is a ticker cycle already processing, we should wait
 	p.flushLock.Lock()
 	defer p.flushLock.Unlock()
 
-	var batchEvent Batch
-	var batchEventCount = 0
-	var failedToSend = false
-
 	for p.eventsCount() > 0 {
+		p.queueMux.Lock()
+		events := p.getEvents(p.BatchSize * p.flushWorkers)
+		p.droppedInFlush = 0
+		p.queueMux.Unlock()
+
+		batches := p.createBatches(events)
+		if len(batches) == 0 {
+			break
+		}
+
+		results := make([]bool, len(batches))
+		if len(batches) == 1 {
+			results[0] = p.dispatch(batches[0])
+		} else {
+			var wg sync.WaitGroup
+			for i := range batches {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					results[i] = p.dispatch(batches[i])
+				}(i)
+			}
+			wg.Wait()
+		}
+
+		p.removeFlushed(events, batches, results)
+
+		failedToSend := false
+		for _, delivered := range results {
+			failedToSend = failedToSend || !delivered
+		}
 		if failedToSend {
 			p.logger.Error("last Event Batch failed to send; retry on next flush", errors.New("dispatcher failed"))
 			break
 		}
-		events := p.getEvents(p.BatchSize)
-
-		if len(events) > 0 {
-			for i := 0; i < len(events); i++ {
-				userEvent, ok := events[i].(UserEvent)
-				if ok {
-					if batchEventCount == 0 {
-						batchEvent = createBatchEvent(userEvent, createVisitorFromUserEvent(userEvent))
-						batchEventCount = 1
-					} else {
-						if !p.canBatch(&batchEvent, userEvent) {
-							// this could happen if the project config was updated for instance.
-							p.logger.Info("Can't batch last event. Sending current batch.")
-							break
-						} else {
-							p.addToBatch(&batchEvent, createVisitorFromUserEvent(userEvent))
-							batchEventCount++
-						}
-					}
-
-					if batchEventCount >= p.BatchSize {
-						// the batch size is reached so take the current batchEvent and send it.
-						break
-					}
+	}
+}
+
+// createBatches splits the events into up to a batch per flush worker
+func (p *BatchEventProcessor) createBatches(events []interface{}) []pendingBatch {
+	var batches []pendingBatch
+	current := pendingBatch{}
+	for _, item := range events {
+		userEvent, ok := item.(UserEvent)
+		if !ok {
+			// the item is removed along with the batch
+			current.count++
+			continue
+		}
+
+		visitor := createVisitorFromUserEvent(userEvent)
+		visitorBytes := p.visitorBytes(visitor)
+		if current.visitors > 0 {
+			// a visitor is separated from the previous one by a comma
+			full := current.visitors >= p.BatchSize || (p.maxBatchBytes > 0 && current.bytes+visitorBytes+1 > p.maxBatchBytes)
+			if full || !p.canBatch(&current.batch, userEvent) {
+				if !full {
+					// this could happen if the project config was updated for instance.
+					p.logger.Info("Can't batch last event. Sending current batch.")
+				}
+				batches = append(batches, current)
+				current = pendingBatch{}
+				if len(batches) == p.flushWorkers {
+					return batches
 				}
 			}
 		}
-		if batchEventCount > 0 {
-			// TODO: figure out what to do with the error
-			logEvent := createLogEvent(batchEvent, p.EventEndPoint)
-			notificationCenter := registry.GetNotificationCenter(p.sdkKey)
-
-			err := notificationCenter.Send(notification.LogEvent, logEvent)
 
-			if err != nil {
-				p.logger.Error("Send Log Event notification failed.", err)
+		if current.visitors == 0 {
+			current.batch = createBatchEvent(userEvent, visitor)
+			current.bytes = p.batchBytes(current.batch)
+			if p.maxBatchBytes > 0 && current.bytes > p.maxBatchBytes {
+				p.logger.Warning(fmt.Sprintf("Event of %d bytes exceeds the max batch size of %d bytes. Sending it alone.",
+					current.bytes, p.maxBatchBytes))
 			}
-			if success, _ := p.EventDispatcher.DispatchEvent(logEvent); success {
-				p.logger.Debug("Dispatched event successfully")
-				p.remove(batchEventCount)
-				batchEventCount = 0
-				batchEvent = Batch{}
-			} else {
-				p.logger.Warning("Failed to dispatch event successfully")
-				failedToSend = true
+		} else {
+			p.addToBatch(&current.batch, visitor)
+			current.bytes += visitorBytes + 1
+		}
+		current.visitors++
+		current.count++
+	}
+	if current.count > 0 {
+		batches = append(batches, current)
+	}
+	return batches
+}
+
+// dispatch sends the batch and returns whether it was dispatched
+func (p *BatchEventProcessor) dispatch(pending pendingBatch) bool {
+	if pending.visitors == 0 {
+		return true
+	}
+
+	// TODO: figure out what to do with the error
+	logEvent := createLogEvent(pending.batch, p.EventEndPoint)
+	notificationCenter := registry.GetNotificationCenter(p.sdkKey)
+
+	err := notificationCenter.Send(notification.LogEvent, logEvent)
+
+	if err != nil {
+		p.logger.Error("Send Log Event notification failed.", err)
+	}
+	if success, _ := p.EventDispatcher.DispatchEvent(logEvent); success {
+		p.logger.Debug("Dispatched event successfully")
+		return true
+	}
+	p.logger.Warning("Failed to dispatch event successfully")
+	return false
+}
+
+// removeFlushed removes the events of the batches from the queue up to the last batch which was delivered, except the
+// ones the DropOldest policy dropped meanwhile. The events of the failed batches before it are queued again so that
+// the delivered batches are not sent twice, the failed batches after it stay in place.
+func (p *BatchEventProcessor) removeFlushed(events []interface{}, batches []pendingBatch, results []bool) {
+	delivered := len(batches)
+	for delivered > 0 && !results[delivered-1] {
+		delivered--
+	}
+
+	p.queueMux.Lock()
+	var requeued []interface{}
+	flushed := 0
+	for i := 0; i < delivered; i++ {
+		if !results[i] {
+			for index := flushed; index < flushed+batches[i].count; index++ {
+				// the oldest events of the flush are the ones the DropOldest policy dropped
+				if _, ok := events[index].(UserEvent); ok && index >= p.droppedInFlush {
+					requeued = append(requeued, events[index])
+				}
 			}
 		}
+		flushed += batches[i].count
+	}
+	p.remove(flushed - min(p.droppedInFlush, flushed))
+	for _, event := range requeued {
+		p.Q.Add(event)
+	}
+	p.droppedInFlush = 0
+	p.queueMux.Unlock()
+
+	p.queueSizeGauge.Set(float64(p.eventsCount()))
+	if flushed > len(requeued) {
+		p.signalRoom()
 	}
 }
 
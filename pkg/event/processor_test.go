@@ -23,13 +23,16 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/optimizely/go-sdk/v2/pkg/entities"
 	"github.com/optimizely/go-sdk/v2/pkg/logging"
+	"github.com/optimizely/go-sdk/v2/pkg/metrics"
 	"github.com/optimizely/go-sdk/v2/pkg/utils"
 	// Import the package containing the Impression type
 )
@@ -895,3 +898,285 @@ func benchmarkProcessor(q Queue, bSize int, b *testing.B) {
 		b.Fail()
 	}
 }
+
+// ConcurrentDispatcher records the highest number of concurrent dispatches and fails the batches of failVisitor
+type ConcurrentDispatcher struct {
+	delay       time.Duration
+	failVisitor string
+	lock        sync.Mutex
+	running     int
+	maxRunning  int
+	visitors    []string
+}
+
+func (c *ConcurrentDispatcher) DispatchEvent(event LogEvent) (bool, error) {
+	c.lock.Lock()
+	c.running++
+	c.maxRunning = max(c.maxRunning, c.running)
+	c.lock.Unlock()
+
+	time.Sleep(c.delay)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.running--
+	for _, visitor := range event.Event.Visitors {
+		if visitor.VisitorID == c.failVisitor {
+			return false, nil
+		}
+	}
+	for _, visitor := range event.Event.Visitors {
+		c.visitors = append(c.visitors, visitor.VisitorID)
+	}
+	return true, nil
+}
+
+func buildVisitorImpression(visitorID string) UserEvent {
+	impression := BuildTestImpressionEvent()
+	impression.VisitorID = visitorID
+	return impression
+}
+
+func queuedVisitors(q Queue) []string {
+	var visitors []string
+	for _, item := range q.Get(q.Size()) {
+		visitors = append(visitors, item.(UserEvent).VisitorID)
+	}
+	return visitors
+}
+
+func TestBatchEventProcessor_FlushWorkers(t *testing.T) {
+	dispatcher := &ConcurrentDispatcher{delay: 50 * time.Millisecond}
+	processor := NewBatchEventProcessor(WithSDKKey(t.Name()), WithEventDispatcher(dispatcher), WithBatchSize(2), WithQueueSize(100),
+		WithFlushWorkers(3), WithFlushInterval(time.Hour))
+	for i := 0; i < 7; i++ {
+		processor.Q.Add(buildVisitorImpression(fmt.Sprintf("visitor_%d", i)))
+	}
+
+	processor.flushEvents()
+	assert.Equal(t, 0, processor.eventsCount())
+	assert.Equal(t, 3, dispatcher.maxRunning)
+	assert.ElementsMatch(t, []string{"visitor_0", "visitor_1", "visitor_2", "visitor_3", "visitor_4", "visitor_5", "visitor_6"},
+		dispatcher.visitors)
+}
+
+func TestBatchEventProcessor_FlushWorkers_Failure(t *testing.T) {
+	dispatcher := &ConcurrentDispatcher{failVisitor: "visitor_0"}
+	processor := NewBatchEventProcessor(WithSDKKey(t.Name()), WithEventDispatcher(dispatcher), WithBatchSize(2), WithQueueSize(100),
+		WithFlushWorkers(3), WithFlushInterval(time.Hour))
+	for i := 0; i < 6; i++ {
+		processor.Q.Add(buildVisitorImpression(fmt.Sprintf("visitor_%d", i)))
+	}
+
+	processor.flushEvents()
+	// only the first batch failed, the batches delivered after it are not sent again by the next flush
+	assert.Equal(t, []string{"visitor_0", "visitor_1"}, queuedVisitors(processor.Q))
+	assert.ElementsMatch(t, []string{"visitor_2", "visitor_3", "visitor_4", "visitor_5"}, dispatcher.visitors)
+
+	dispatcher.failVisitor = ""
+	processor.flushEvents()
+	assert.Equal(t, 0, processor.eventsCount())
+	assert.ElementsMatch(t, []string{"visitor_0", "visitor_1", "visitor_2", "visitor_3", "visitor_4", "visitor_5"}, dispatcher.visitors)
+}
+
+func TestBatchEventProcessor_FlushWorkers_LastBatchFailure(t *testing.T) {
+	dispatcher := &ConcurrentDispatcher{failVisitor: "visitor_4"}
+	processor := NewBatchEventProcessor(WithSDKKey(t.Name()), WithEventDispatcher(dispatcher), WithBatchSize(2), WithQueueSize(100),
+		WithFlushWorkers(3), WithFlushInterval(time.Hour))
+	for i := 0; i < 6; i++ {
+		processor.Q.Add(buildVisitorImpression(fmt.Sprintf("visitor_%d", i)))
+	}
+
+	processor.flushEvents()
+	// the failed batches after the last delivered one stay in place
+	assert.Equal(t, []string{"visitor_4", "visitor_5"}, queuedVisitors(processor.Q))
+	assert.ElementsMatch(t, []string{"visitor_0", "visitor_1", "visitor_2", "visitor_3"}, dispatcher.visitors)
+}
+
+func TestBatchEventProcessor_OverflowDropNewest(t *testing.T) {
+	metricsRegistry := NewMetricsRegistry()
+	processor := NewBatchEventProcessor(WithSDKKey(t.Name()),
+		WithEventDispatcher(&MockDispatcher{ShouldFail: true, Events: NewInMemoryQueue(100)}),
+		WithBatchSize(3), WithQueueSize(3), WithEventDispatcherMetrics(metricsRegistry))
+	assert.Equal(t, DropNewest, processor.overflowPolicy)
+
+	for i := 0; i < 4; i++ {
+		processor.Q.Add(buildVisitorImpression(fmt.Sprintf("visitor_%d", i)))
+	}
+	assert.False(t, processor.ProcessEvent(buildVisitorImpression("visitor_4")))
+	assert.Equal(t, float64(1), metricsRegistry.GetCounter(metrics.ProcessorDroppedEvents).(*MetricsCounter).Get())
+}
+
+func TestBatchEventProcessor_OverflowDropOldest(t *testing.T) {
+	metricsRegistry := NewMetricsRegistry()
+	processor := NewBatchEventProcessor(WithSDKKey(t.Name()),
+		WithEventDispatcher(&MockDispatcher{ShouldFail: true, Events: NewInMemoryQueue(100)}),
+		WithBatchSize(3), WithQueueSize(3), WithOverflowPolicy(DropOldest), WithEventDispatcherMetrics(metricsRegistry))
+
+	for i := 0; i < 5; i++ {
+		assert.True(t, processor.ProcessEvent(buildVisitorImpression(fmt.Sprintf("visitor_%d", i))))
+	}
+	// wait for the flush started when the batch size was reached, it fails
+	processor.flushLock.Lock()
+	defer processor.flushLock.Unlock()
+	assert.Equal(t, []string{"visitor_2", "visitor_3", "visitor_4"}, queuedVisitors(processor.Q))
+	assert.Equal(t, float64(2), metricsRegistry.GetCounter(metrics.ProcessorDroppedEvents).(*MetricsCounter).Get())
+	assert.Equal(t, float64(3), metricsRegistry.GetGauge(metrics.ProcessorQueueSize).(*MetricsGauge).Get())
+}
+
+func TestBatchEventProcessor_DropOldest_During_Flush(t *testing.T) {
+	dispatcher := &ConcurrentDispatcher{delay: 100 * time.Millisecond}
+	processor := NewBatchEventProcessor(WithSDKKey(t.Name()), WithEventDispatcher(dispatcher), WithBatchSize(3), WithQueueSize(3),
+		WithOverflowPolicy(DropOldest), WithFlushInterval(time.Hour))
+	for i := 0; i < 3; i++ {
+		processor.Q.Add(buildVisitorImpression(fmt.Sprintf("visitor_%d", i)))
+	}
+
+	done := make(chan struct{})
+	go func() {
+		processor.flushEvents()
+		close(done)
+	}()
+	assert.Eventually(t, func() bool {
+		dispatcher.lock.Lock()
+		defer dispatcher.lock.Unlock()
+		return dispatcher.running == 1
+	}, time.Second, time.Millisecond)
+
+	// the oldest event is dropped while being flushed, the flush must not remove the event queued meanwhile
+	assert.True(t, processor.ProcessEvent(buildVisitorImpression("visitor_3")))
+	<-done
+	processor.flushLock.Lock()
+	defer processor.flushLock.Unlock()
+	assert.Equal(t, []string{"visitor_0", "visitor_1", "visitor_2", "visitor_3"}, dispatcher.visitors)
+	assert.Equal(t, 0, processor.eventsCount())
+}
+
+func TestBatchEventProcessor_OverflowBlock(t *testing.T) {
+	metricsRegistry := NewMetricsRegistry()
+	dispatcher := &MockDispatcher{ShouldFail: true, Events: NewInMemoryQueue(100)}
+	processor := NewBatchEventProcessor(WithSDKKey(t.Name()), WithEventDispatcher(dispatcher), WithBatchSize(3), WithQueueSize(3),
+		WithOverflowPolicy(Block), WithOverflowBlockTimeout(50*time.Millisecond), WithEventDispatcherMetrics(metricsRegistry))
+	for i := 0; i < 3; i++ {
+		processor.Q.Add(buildVisitorImpression(fmt.Sprintf("visitor_%d", i)))
+	}
+
+	// the flush fails, no room is made before the timeout
+	start := time.Now()
+	assert.False(t, processor.ProcessEvent(buildVisitorImpression("visitor_3")))
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	assert.Equal(t, float64(1), metricsRegistry.GetCounter(metrics.ProcessorBlockedEvents).(*MetricsCounter).Get())
+	assert.Equal(t, float64(1), metricsRegistry.GetCounter(metrics.ProcessorDroppedEvents).(*MetricsCounter).Get())
+
+	processor.flushLock.Lock()
+	dispatcher.ShouldFail = false
+	processor.flushLock.Unlock()
+	// the flush makes room
+	assert.True(t, processor.ProcessEvent(buildVisitorImpression("visitor_4")))
+	assert.Equal(t, float64(2), metricsRegistry.GetCounter(metrics.ProcessorBlockedEvents).(*MetricsCounter).Get())
+	assert.Equal(t, float64(1), metricsRegistry.GetCounter(metrics.ProcessorDroppedEvents).(*MetricsCounter).Get())
+}
+
+func TestBatchEventProcessor_MaxBatchBytes(t *testing.T) {
+	impression := buildVisitorImpression("visitor_0")
+	oneVisitor, err := json.Marshal(createBatchEvent(impression, createVisitorFromUserEvent(impression)))
+	require.NoError(t, err)
+	visitor, err := json.Marshal(createVisitorFromUserEvent(impression))
+	require.NoError(t, err)
+	// room for two visitors but not three
+	maxBytes := len(oneVisitor) + len(visitor) + 1
+
+	dispatcher := &MockDispatcher{Events: NewInMemoryQueue(100)}
+	processor := NewBatchEventProcessor(WithSDKKey(t.Name()), WithEventDispatcher(dispatcher), WithBatchSize(10),
+		WithQueueSize(100), WithFlushInterval(time.Hour), WithMaxBatchBytes(maxBytes))
+	for i := 0; i < 5; i++ {
+		processor.Q.Add(buildVisitorImpression(fmt.Sprintf("visitor_%d", i)))
+	}
+	processor.flushEvents()
+
+	assert.Equal(t, 0, processor.eventsCount())
+	var visitors []int
+	for _, item := range dispatcher.Events.Get(dispatcher.Events.Size()) {
+		logEvent := item.(LogEvent)
+		visitors = append(visitors, len(logEvent.Event.Visitors))
+		payload, marshalErr := json.Marshal(logEvent.Event)
+		require.NoError(t, marshalErr)
+		assert.LessOrEqual(t, len(payload), maxBytes)
+	}
+	assert.Equal(t, []int{2, 2, 1}, visitors)
+}
+
+func TestBatchEventProcessor_MaxBatchBytes_LargeEvent(t *testing.T) {
+	dispatcher := &MockDispatcher{Events: NewInMemoryQueue(100)}
+	processor := NewBatchEventProcessor(WithSDKKey(t.Name()), WithEventDispatcher(dispatcher), WithBatchSize(10),
+		WithQueueSize(100), WithFlushInterval(time.Hour), WithMaxBatchBytes(10))
+	for i := 0; i < 3; i++ {
+		processor.Q.Add(buildVisitorImpression(fmt.Sprintf("visitor_%d", i)))
+	}
+	processor.flushEvents()
+
+	// each event exceeds the limit and is sent alone
+	assert.Equal(t, 0, processor.eventsCount())
+	assert.Equal(t, 3, dispatcher.Events.Size())
+}
+
+func TestBatchEventProcessor_DeduplicationWindow(t *testing.T) {
+	metricsRegistry := NewMetricsRegistry()
+	processor := NewBatchEventProcessor(WithSDKKey(t.Name()), WithBatchSize(100), WithQueueSize(100),
+		WithFlushInterval(time.Hour), WithDeduplicationWindow(50*time.Millisecond, 0), WithEventDispatcherMetrics(metricsRegistry),
+		WithEventDispatcher(&MockDispatcher{Events: NewInMemoryQueue(100)}))
+	assert.Equal(t, DefaultDeduplicationCacheSize, processor.dedupCacheSize)
+
+	impression := buildVisitorImpression("visitor_0")
+	assert.True(t, processor.ProcessEvent(impression))
+	assert.True(t, processor.ProcessEvent(buildVisitorImpression("visitor_0")))
+	assert.Equal(t, 1, processor.eventsCount())
+	assert.Equal(t, float64(1), metricsRegistry.GetCounter(metrics.ProcessorDeduplicatedEvents).(*MetricsCounter).Get())
+
+	// impressions of another user or variation and conversions are not duplicates
+	processor.ProcessEvent(buildVisitorImpression("visitor_1"))
+	otherVariation := buildVisitorImpression("visitor_0")
+	otherVariation.Impression.VariationID = "other"
+	processor.ProcessEvent(otherVariation)
+	processor.ProcessEvent(BuildTestConversionEvent())
+	processor.ProcessEvent(BuildTestConversionEvent())
+	assert.Equal(t, 5, processor.eventsCount())
+
+	// flag decisions without a variation are told apart by their flag
+	for _, flagKey := range []string{"flag_1", "flag_2"} {
+		unbucketed := buildVisitorImpression("visitor_2")
+		unbucketed.Impression.ExperimentID, unbucketed.Impression.VariationID = "", ""
+		unbucketed.Impression.Metadata = DecisionMetadata{FlagKey: flagKey, RuleType: "rollout"}
+		processor.ProcessEvent(unbucketed)
+	}
+	assert.Equal(t, 7, processor.eventsCount())
+
+	// every CMAB decision is an impression of its own
+	for _, cmabUUID := range []string{"uuid_1", "uuid_2"} {
+		cmabImpression := buildVisitorImpression("visitor_3")
+		cmabImpression.Impression.Metadata.CmabUUID = &cmabUUID
+		processor.ProcessEvent(cmabImpression)
+	}
+	assert.Equal(t, 9, processor.eventsCount())
+
+	// the impression is processed again once the window is over
+	time.Sleep(60 * time.Millisecond)
+	processor.ProcessEvent(impression)
+	assert.Equal(t, 10, processor.eventsCount())
+	assert.Equal(t, float64(1), metricsRegistry.GetCounter(metrics.ProcessorDeduplicatedEvents).(*MetricsCounter).Get())
+}
+
+func TestBatchEventProcessor_DeduplicationWindow_DroppedEvent(t *testing.T) {
+	processor := NewBatchEventProcessor(WithSDKKey(t.Name()), WithBatchSize(1), WithQueueSize(1),
+		WithFlushInterval(time.Hour), WithDeduplicationWindow(time.Hour, 10),
+		WithEventDispatcher(&MockDispatcher{ShouldFail: true, Events: NewInMemoryQueue(100)}))
+	processor.Q.Add(BuildTestConversionEvent())
+
+	// the impression dropped because the queue is full is not a duplicate of the next one
+	assert.False(t, processor.ProcessEvent(buildVisitorImpression("visitor_0")))
+	processor.Q.Remove(1)
+	assert.True(t, processor.ProcessEvent(buildVisitorImpression("visitor_0")))
+	assert.Equal(t, 1, processor.eventsCount())
+	assert.True(t, processor.ProcessEvent(buildVisitorImpression("visitor_0")))
+	assert.Equal(t, 1, processor.eventsCount())
+}
@@ -0,0 +1,134 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package event //
+package event
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultRetryableStatusCodes holds the response status codes retried by the default retry policy, other error statuses
+// fail the log event permanently
+var DefaultRetryableStatusCodes = []int{
+	http.StatusRequestTimeout,
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryPolicy tells the QueueEventDispatcher how to retry the log events which fail to be sent. A log event still
+// failing after MaxAttempts stays in the queue and is sent again by the next flush, one failing with a status code which
+// is not retryable is handed to the dead letter sink and removed from the queue.
+type RetryPolicy struct {
+	// MaxAttempts is the number of times a log event is sent by a flush, at least 1
+	MaxAttempts int
+	// InitialInterval is the wait before the first retry, each retry waits Multiplier times longer up to MaxInterval
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	Multiplier      float64
+	// RetryableStatusCodes are the error status codes worth retrying. Failures without a status code, e.g. network
+	// errors, are always retried.
+	RetryableStatusCodes []int
+	// RespectRetryAfter makes a retry wait for the Retry-After delay of the response when it is longer than the
+	// backoff. A delay longer than MaxInterval is not waited for, the events are retried by the first flush after it.
+	RespectRetryAfter bool
+}
+
+// DefaultRetryPolicy returns the retry policy of the QueueEventDispatcher: 4 attempts with a backoff doubling from
+// 200ms up to 1s, retrying timeouts, throttling and server errors, and respecting Retry-After
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:          maxRetries + 1,
+		InitialInterval:      initialRetryInterval,
+		MaxInterval:          maxRetryInterval,
+		Multiplier:           2,
+		RetryableStatusCodes: DefaultRetryableStatusCodes,
+		RespectRetryAfter:    true,
+	}
+}
+
+// Backoff returns the wait before the given retry, starting at 0 for the first retry
+func (p RetryPolicy) Backoff(retry int) time.Duration {
+	interval := float64(p.InitialInterval)
+	for i := 0; i < retry && interval < float64(p.MaxInterval); i++ {
+		interval *= max(p.Multiplier, 1)
+	}
+	return min(time.Duration(interval), p.MaxInterval)
+}
+
+// IsRetryable returns whether a failure with the given status code is worth retrying. Status codes below 400 are
+// retried, they do not tell the event was rejected.
+func (p RetryPolicy) IsRetryable(statusCode int) bool {
+	if statusCode < http.StatusBadRequest {
+		return true
+	}
+	for _, code := range p.RetryableStatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// DispatchError is returned by the http event dispatcher when the event endpoint answers with an unexpected status.
+// Dispatchers can return it to tell the QueueEventDispatcher whether a failure is worth retrying.
+type DispatchError struct {
+	StatusCode int
+	// RetryAfter is the delay asked by the Retry-After header of the response, 0 when absent
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *DispatchError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("event dispatch failed with status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("event dispatch failed with status %d: %v", e.StatusCode, e.Err)
+}
+
+func (e *DispatchError) Unwrap() error {
+	return e.Err
+}
+
+// dispatchStatus returns the status code and Retry-After delay carried by the error, zero when it has none
+func dispatchStatus(err error) (statusCode int, retryAfter time.Duration) {
+	var dispatchErr *DispatchError
+	if errors.As(err, &dispatchErr) {
+		return dispatchErr.StatusCode, dispatchErr.RetryAfter
+	}
+	return 0, 0
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0)
+	}
+	return 0
+}
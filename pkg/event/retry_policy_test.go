@@ -0,0 +1,83 @@
+/****************************************************************************
+ * Copyright 2026, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    https://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package event
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := DefaultRetryPolicy()
+	assert.Equal(t, 4, policy.MaxAttempts)
+	assert.Equal(t, 200*time.Millisecond, policy.Backoff(0))
+	assert.Equal(t, 400*time.Millisecond, policy.Backoff(1))
+	assert.Equal(t, 800*time.Millisecond, policy.Backoff(2))
+	assert.Equal(t, time.Second, policy.Backoff(3))
+	assert.Equal(t, time.Second, policy.Backoff(100))
+
+	policy = RetryPolicy{InitialInterval: 100 * time.Millisecond, MaxInterval: time.Minute, Multiplier: 3}
+	assert.Equal(t, 100*time.Millisecond, policy.Backoff(0))
+	assert.Equal(t, 900*time.Millisecond, policy.Backoff(2))
+
+	// a multiplier below 1 gives a constant backoff
+	policy = RetryPolicy{InitialInterval: 100 * time.Millisecond, MaxInterval: time.Minute}
+	assert.Equal(t, 100*time.Millisecond, policy.Backoff(5))
+}
+
+func TestRetryPolicyIsRetryable(t *testing.T) {
+	policy := DefaultRetryPolicy()
+	assert.True(t, policy.IsRetryable(0))
+	assert.True(t, policy.IsRetryable(http.StatusOK))
+	assert.True(t, policy.IsRetryable(http.StatusTooManyRequests))
+	assert.True(t, policy.IsRetryable(http.StatusServiceUnavailable))
+	assert.False(t, policy.IsRetryable(http.StatusBadRequest))
+	assert.False(t, policy.IsRetryable(http.StatusNotFound))
+
+	policy.RetryableStatusCodes = []int{http.StatusNotFound}
+	assert.True(t, policy.IsRetryable(http.StatusNotFound))
+	assert.False(t, policy.IsRetryable(http.StatusServiceUnavailable))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, 120*time.Second, parseRetryAfter("120", now))
+	assert.Equal(t, 30*time.Second, parseRetryAfter(now.Add(30*time.Second).Format(http.TimeFormat), now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter(now.Add(-time.Hour).Format(http.TimeFormat), now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("-5", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("soon", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("", now))
+}
+
+func TestDispatchError(t *testing.T) {
+	cause := errors.New("400 Bad Request")
+	err := error(&DispatchError{StatusCode: http.StatusBadRequest, Err: cause})
+	assert.ErrorIs(t, err, cause)
+	assert.Equal(t, "event dispatch failed with status 400: 400 Bad Request", err.Error())
+
+	statusCode, retryAfter := dispatchStatus(&DispatchError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Second})
+	assert.Equal(t, http.StatusTooManyRequests, statusCode)
+	assert.Equal(t, time.Second, retryAfter)
+
+	statusCode, retryAfter = dispatchStatus(cause)
+	assert.Zero(t, statusCode)
+	assert.Zero(t, retryAfter)
+}
@@ -23,4 +23,24 @@ const (
 	DispatcherSuccessFlush = "dispatcher.successFlush"
 	DispatcherRetryFlush   = "dispatcher.retryFlush"
 	DispatcherQueueSize    = "dispatcher.queueSize"
+	DispatcherDeadLetters  = "dispatcher.deadLetters"
+)
+
+// ProcessorQueueSize stores the metric names of the batch event processor queue
+const (
+	ProcessorQueueSize          = "processor.queueSize"
+	ProcessorDroppedEvents      = "processor.droppedEvents"
+	ProcessorBlockedEvents      = "processor.blockedEvents"
+	ProcessorDeduplicatedEvents = "processor.deduplicatedEvents"
+)
+
+// ConfigPollNotModified stores the counter names for datafile poll outcomes
+const (
+	ConfigPollNotModified  = "config.pollNotModified"
+	ConfigPollNewRevision  = "config.pollNewRevision"
+	ConfigPollSameRevision = "config.pollSameRevision"
+	ConfigPollError        = "config.pollError"
+	ConfigPollForbidden    = "config.pollForbidden"
+	ConfigPollRejected     = "config.pollRejected"
+	ConfigPollPinned       = "config.pollPinned"
 )
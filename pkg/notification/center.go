@@ -41,11 +41,15 @@ func NewNotificationCenter() *DefaultCenter {
 	projectConfigUpdateNotificationManager := NewAtomicManager(logging.GetLogger("", "AtomicManager"))
 	processLogEventNotificationManager := NewAtomicManager(logging.GetLogger("", "AtomicManager"))
 	trackNotificationManager := NewAtomicManager(logging.GetLogger("", "AtomicManager"))
+	configPollNotificationManager := NewAtomicManager(logging.GetLogger("", "AtomicManager"))
+	datafileRejectedNotificationManager := NewAtomicManager(logging.GetLogger("", "AtomicManager"))
 	managerMap := make(map[Type]Manager)
 	managerMap[Decision] = decisionNotificationManager
 	managerMap[ProjectConfigUpdate] = projectConfigUpdateNotificationManager
 	managerMap[LogEvent] = processLogEventNotificationManager
 	managerMap[Track] = trackNotificationManager
+	managerMap[ConfigPoll] = configPollNotificationManager
+	managerMap[DatafileRejected] = datafileRejectedNotificationManager
 	return &DefaultCenter{
 		managerMap: managerMap,
 	}
@@ -18,6 +18,8 @@
 package notification
 
 import (
+	"time"
+
 	"github.com/optimizely/go-sdk/v2/pkg/entities"
 )
 
@@ -36,6 +38,10 @@ const (
 	ProjectConfigUpdate Type = "project_config_update"
 	// LogEvent notification type
 	LogEvent Type = "log_event_notification"
+	// ConfigPoll notification type
+	ConfigPoll Type = "config_poll"
+	// DatafileRejected notification type
+	DatafileRejected Type = "datafile_rejected"
 
 	// ABTest is used when the decision is returned as part of evaluating an ab test
 	ABTest DecisionNotificationType = "ab-test"
@@ -70,6 +76,48 @@ type TrackNotification struct {
 type ProjectConfigUpdateNotification struct {
 	Type     Type
 	Revision string
+	Source   string      // datafile source that served the revision, empty for the initial datafile
+	Diff     interface{} // *config.ConfigDiff from the previous revision when enabled on the config manager, nil otherwise
+	Pinned   bool        // whether the config manager is pinned to Revision
+}
+
+// ConfigPollOutcome is the outcome of a single datafile poll
+type ConfigPollOutcome string
+
+const (
+	// PollNotModified is used when the datafile server responded with 304 Not Modified
+	PollNotModified ConfigPollOutcome = "not_modified"
+	// PollNewRevision is used when a datafile with a new revision was fetched and applied
+	PollNewRevision ConfigPollOutcome = "new_revision"
+	// PollSameRevision is used when a datafile was fetched but its revision matches the current one
+	PollSameRevision ConfigPollOutcome = "same_revision"
+	// PollError is used when the datafile could not be fetched or parsed
+	PollError ConfigPollOutcome = "error"
+	// PollForbidden is used when the datafile server responded with 403 Forbidden
+	PollForbidden ConfigPollOutcome = "forbidden"
+	// PollRejected is used when a fetched datafile was refused before being applied
+	PollRejected ConfigPollOutcome = "rejected"
+	// PollPinned is used when a datafile with a new revision was fetched but not applied because the config is pinned
+	PollPinned ConfigPollOutcome = "pinned"
+)
+
+// ConfigPollNotification is a notification triggered after every datafile poll
+type ConfigPollNotification struct {
+	Type               Type
+	Outcome            ConfigPollOutcome
+	StatusCode         int
+	Err                error
+	Revision           string    // revision being served after the poll
+	LastSuccessfulPoll time.Time // zero until a poll succeeds
+	Source             string    // datafile source that served the current revision
+}
+
+// DatafileRejectedNotification is a notification triggered when a fetched datafile is refused and the current config is kept
+type DatafileRejectedNotification struct {
+	Type     Type
+	Revision string
+	Source   string
+	Reason   error
 }
 
 // LogEventNotification is the notification triggered before log event is dispatched.
@@ -40,6 +40,9 @@ const (
 	// HeaderAccept is the HTTP Accept Type header.
 	HeaderAccept = "Accept"
 
+	// HeaderContentEncoding is the HTTP Content Encoding header.
+	HeaderContentEncoding = "Content-Encoding"
+
 	// ContentTypeJSON is the Content-Type value for a JSON response.
 	ContentTypeJSON = "application/json"
 